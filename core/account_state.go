@@ -0,0 +1,221 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lonySp/go-blockchain/types"
+	"math"
+	"sync"
+)
+
+var (
+	// ErrAccountNotFound 表示账户不存在
+	// ErrAccountNotFound is returned when the account does not exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInsufficientBalance 表示账户余额不足
+	// ErrInsufficientBalance is returned when the account balance is insufficient
+	ErrInsufficientBalance = errors.New("insufficient account balance")
+	// ErrInvalidNonce 表示交易序号与账户序号不匹配
+	// ErrInvalidNonce is returned when the transaction nonce does not match the account nonce
+	ErrInvalidNonce = errors.New("invalid account nonce")
+)
+
+// Account 结构体表示链上的一个账户
+// Account struct represents an account on the chain
+type Account struct {
+	Address types.Address // 账户地址 // Account address
+	Balance uint64        // 账户余额 // Account balance
+	Nonce   uint64        // 下一笔交易应使用的序号 // Nonce expected for the next transaction
+	Code    []byte        // 合约账户的代码 // Code of a contract account
+}
+
+// String 方法返回账户的字符串表示
+// String method returns the string representation of the account
+func (a *Account) String() string {
+	return fmt.Sprintf("%s: %d (nonce %d)", a.Address, a.Balance, a.Nonce)
+}
+
+// AccountState 结构体表示以地址为键的账户状态
+// AccountState struct represents the account state keyed by address
+type AccountState struct {
+	lock     sync.RWMutex               // 读写锁 // Read-write lock
+	accounts map[types.Address]*Account // 地址到账户的映射 // Map from address to account
+}
+
+// NewAccountState 创建一个新的 AccountState 实例
+// NewAccountState creates a new instance of AccountState
+func NewAccountState() *AccountState {
+	return &AccountState{
+		accounts: make(map[types.Address]*Account),
+	}
+}
+
+// CreateAccount 创建一个新账户，如果账户已存在则返回已有账户
+// CreateAccount creates a new account, or returns the existing one if it already exists
+func (s *AccountState) CreateAccount(address types.Address) *Account {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.getOrCreate(address)
+}
+
+// GetAccount 返回指定地址账户的副本
+// GetAccount returns a copy of the account at the given address
+func (s *AccountState) GetAccount(address types.Address) (*Account, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	acc, ok := s.accounts[address]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	accCopy := *acc
+	return &accCopy, nil
+}
+
+// GetBalance 返回指定地址的余额，不存在的账户余额为 0
+// GetBalance returns the balance of the given address, a missing account has a balance of 0
+func (s *AccountState) GetBalance(address types.Address) uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if acc, ok := s.accounts[address]; ok {
+		return acc.Balance
+	}
+	return 0
+}
+
+// GetNonce 返回指定地址下一笔交易应使用的序号
+// GetNonce returns the nonce expected for the next transaction of the given address
+func (s *AccountState) GetNonce(address types.Address) uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if acc, ok := s.accounts[address]; ok {
+		return acc.Nonce
+	}
+	return 0
+}
+
+// UseNonce 校验并消耗指定地址的序号
+// UseNonce checks and consumes the nonce of the given address
+func (s *AccountState) UseNonce(address types.Address, nonce uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc := s.getOrCreate(address)
+	if nonce != acc.Nonce {
+		return fmt.Errorf("%w (%s): have %d, want %d", ErrInvalidNonce, address, nonce, acc.Nonce)
+	}
+	acc.Nonce++
+	return nil
+}
+
+// GetCode 返回指定地址的合约代码，非合约账户返回 nil
+// GetCode returns the contract code at the given address, nil for non-contract accounts
+func (s *AccountState) GetCode(address types.Address) []byte {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if acc, ok := s.accounts[address]; ok {
+		return acc.Code
+	}
+	return nil
+}
+
+// SetCode 设置指定地址的合约代码，合约代码只能设置一次
+// SetCode sets the contract code at the given address, the code can only be set once
+func (s *AccountState) SetCode(address types.Address, code []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc := s.getOrCreate(address)
+	if len(acc.Code) > 0 {
+		return fmt.Errorf("account (%s) already has code", address)
+	}
+	acc.Code = code
+	return nil
+}
+
+// AddBalance 增加指定地址的余额
+// AddBalance credits the given amount to the address
+func (s *AccountState) AddBalance(address types.Address, amount uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.credit(address, amount)
+}
+
+// SubBalance 减少指定地址的余额
+// SubBalance debits the given amount from the address
+func (s *AccountState) SubBalance(address types.Address, amount uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.debit(address, amount)
+}
+
+// Transfer 从一个账户向另一个账户转账
+// Transfer moves the given amount from one account to another
+func (s *AccountState) Transfer(from, to types.Address, amount uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.debit(from, amount); err != nil {
+		return err
+	}
+	return s.credit(to, amount)
+}
+
+// Copy 返回账户状态的深拷贝，用于在不修改真实状态的情况下模拟执行
+// Copy returns a deep copy of the account state, used to simulate execution without touching the real state
+func (s *AccountState) Copy() *AccountState {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	cp := NewAccountState()
+	for addr, acc := range s.accounts {
+		accCopy := *acc
+		cp.accounts[addr] = &accCopy
+	}
+	return cp
+}
+
+// getOrCreate 返回指定地址的账户，不存在时创建，调用方需持有写锁
+// getOrCreate returns the account at the given address and creates it when missing, the caller must hold the write lock
+func (s *AccountState) getOrCreate(address types.Address) *Account {
+	acc, ok := s.accounts[address]
+	if !ok {
+		acc = &Account{Address: address}
+		s.accounts[address] = acc
+	}
+	return acc
+}
+
+// credit 增加余额，调用方需持有写锁
+// credit adds to the balance, the caller must hold the write lock
+func (s *AccountState) credit(address types.Address, amount uint64) error {
+	acc := s.getOrCreate(address)
+	if acc.Balance > math.MaxUint64-amount {
+		return fmt.Errorf("balance overflow for account (%s)", address)
+	}
+	acc.Balance += amount
+	return nil
+}
+
+// debit 减少余额，调用方需持有写锁
+// debit subtracts from the balance, the caller must hold the write lock
+func (s *AccountState) debit(address types.Address, amount uint64) error {
+	acc, ok := s.accounts[address]
+	if !ok {
+		if amount == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w (%s)", ErrAccountNotFound, address)
+	}
+	if acc.Balance < amount {
+		return fmt.Errorf("%w (%s): have %d, want %d", ErrInsufficientBalance, address, acc.Balance, amount)
+	}
+	acc.Balance -= amount
+	return nil
+}
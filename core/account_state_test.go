@@ -0,0 +1,55 @@
+package core
+
+import (
+	"github.com/lonySp/go-blockchain/crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAccountStateTransfer 测试账户之间的转账
+// TestAccountStateTransfer tests transfers between accounts
+func TestAccountStateTransfer(t *testing.T) {
+	state := NewAccountState()
+	from := crypto.GeneratePrivateKey().PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+
+	assert.Nil(t, state.AddBalance(from, 100))
+	assert.Nil(t, state.Transfer(from, to, 30))
+	assert.Equal(t, uint64(70), state.GetBalance(from))
+	assert.Equal(t, uint64(30), state.GetBalance(to))
+
+	acc, err := state.GetAccount(to)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(30), acc.Balance)
+}
+
+// TestAccountStateInsufficientBalance 测试余额不足时转账失败
+// TestAccountStateInsufficientBalance tests that a transfer fails on insufficient balance
+func TestAccountStateInsufficientBalance(t *testing.T) {
+	state := NewAccountState()
+	from := crypto.GeneratePrivateKey().PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+
+	assert.ErrorIs(t, state.Transfer(from, to, 1), ErrAccountNotFound)
+
+	assert.Nil(t, state.AddBalance(from, 10))
+	assert.ErrorIs(t, state.Transfer(from, to, 11), ErrInsufficientBalance)
+	assert.Equal(t, uint64(10), state.GetBalance(from))
+
+	_, err := state.GetAccount(to)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+// TestAccountStateCopy 测试状态副本与原状态相互独立
+// TestAccountStateCopy tests that a state copy is independent from the original
+func TestAccountStateCopy(t *testing.T) {
+	state := NewAccountState()
+	addr := crypto.GeneratePrivateKey().PublicKey().Address()
+	assert.Nil(t, state.AddBalance(addr, 10))
+
+	cp := state.Copy()
+	assert.Nil(t, cp.SubBalance(addr, 10))
+	assert.Equal(t, uint64(0), cp.GetBalance(addr))
+	assert.Equal(t, uint64(10), state.GetBalance(addr))
+}
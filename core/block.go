@@ -3,13 +3,17 @@ package core
 import (
 	"bytes"
 	"crypto/sha256"
-	"encoding/gob"
+	"encoding/binary"
 	"fmt"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
 	"time"
 )
 
+// HeaderVersion 是当前的区块头版本号，决定区块头的规范编码
+// HeaderVersion is the current block header version, which decides the canonical header encoding
+const HeaderVersion uint32 = 1
+
 // Header 结构体表示区块头
 // Header struct represents the block header
 type Header struct {
@@ -18,15 +22,26 @@ type Header struct {
 	PrevBlockHash types.Hash // 前一个区块的哈希值 // Hash of the previous block
 	Timestamp     uint64     // 区块生成的时间戳 // Timestamp when the block was created
 	Height        uint32     // 区块高度 // Block height
+	ChainID       uint32     // 区块所属链的 ID // ID of the chain the block belongs to
 }
 
-// Bytes 方法返回区块头的二进制数据
-// Bytes method returns the binary data of the block header
+// Bytes 方法返回区块头的规范编码，区块哈希和区块签名都基于该编码
+// Bytes method returns the canonical encoding of the block header, both the block hash and the block signature are based on it
+//
+// 版本 1 的编码固定为 84 字节，整数均为大端序 // Version 1 is a fixed 84 byte encoding, all integers are big-endian:
+//
+//	version (4) | chainID (4) | height (4) | timestamp (8) | prevBlockHash (32) | dataHash (32)
+//
+// 编码以版本号开头，新的版本号可以定义新的布局，BlockValidator 拒绝不支持的版本
+// The encoding starts with the version so new versions can define a new layout, BlockValidator rejects unsupported versions
 func (h *Header) Bytes() []byte {
-	buf := &bytes.Buffer{}
-	enc := gob.NewEncoder(buf)
-	enc.Encode(h) // 将区块头编码为二进制数据 // Encode the block header into binary data
-	return buf.Bytes()
+	buf := make([]byte, 0, 84)
+	buf = binary.BigEndian.AppendUint32(buf, h.Version)
+	buf = binary.BigEndian.AppendUint32(buf, h.ChainID)
+	buf = binary.BigEndian.AppendUint32(buf, h.Height)
+	buf = binary.BigEndian.AppendUint64(buf, h.Timestamp)
+	buf = append(buf, h.PrevBlockHash[:]...)
+	return append(buf, h.DataHash[:]...)
 }
 
 // Block 结构体表示区块
@@ -60,11 +75,12 @@ func NewBlockFromPrevHeader(prevHeader *Header, txx []*Transaction) (*Block, err
 	}
 	// 创建新的区块头 // Create a new block header
 	header := &Header{
-		Version:       1,
+		Version:       HeaderVersion,
 		Height:        prevHeader.Height + 1,
 		DataHash:      dataHash,
 		PrevBlockHash: BlockHasher{}.Hash(prevHeader),
 		Timestamp:     uint64(time.Now().UnixNano()),
+		ChainID:       prevHeader.ChainID,
 	}
 	// 创建并返回新的区块 // Create and return the new block
 	return NewBlock(header, txx)
@@ -76,11 +92,12 @@ func (b *Block) AddTransaction(tx *Transaction) {
 	b.Transactions = append(b.Transactions, tx)
 }
 
-// Sign 方法使用私钥对区块头数据进行签名
-// Sign method signs the block header data using the private key
+// Sign 方法使用私钥对区块哈希进行签名，区块头中的链 ID 将签名绑定到特定的链
+// Sign method signs the block hash using the private key, the chain ID in the header binds the signature to a specific chain
 func (b *Block) Sign(privateKey crypto.PrivateKey) error {
-	// 使用私钥对区块头进行签名 // Sign the block header using the private key
-	sig, err := privateKey.Sign(b.Header.Bytes())
+	// 使用私钥对区块头规范编码的摘要进行签名 // Sign the digest of the canonical header encoding using the private key
+	digest := BlockHasher{}.Hash(b.Header)
+	sig, err := privateKey.Sign(digest[:])
 	if err != nil {
 		return err
 	}
@@ -91,30 +108,35 @@ func (b *Block) Sign(privateKey crypto.PrivateKey) error {
 	return nil
 }
 
-// Verify 方法验证区块签名的有效性
-// Verify method verifies the validity of the block signature
+// Verify 方法验证区块签名和所有交易签名的有效性
+// Verify method verifies the validity of the block signature and of every transaction signature
 func (b *Block) Verify() error {
+	return b.VerifyCached(nil)
+}
+
+// VerifyCached 方法验证区块签名，并使用签名缓存并行验证交易签名
+// VerifyCached method verifies the block signature and verifies the transaction signatures in parallel using the signature cache
+func (b *Block) VerifyCached(cache *SigCache) error {
 	// 如果签名为空，则返回错误 // Return an error if the signature is nil
 	if b.Signature == nil {
 		return fmt.Errorf("block has no signature")
 	}
 
-	// 验证签名，如果无效则返回错误 // Verify the signature, return an error if invalid
-	if !b.Signature.Verify(b.Validator, b.Header.Bytes()) {
+	// 使用相同的摘要验证签名，如果无效则返回错误 // Verify the signature over the same digest, return an error if invalid
+	digest := BlockHasher{}.Hash(b.Header)
+	if !b.Signature.Verify(b.Validator, digest[:]) {
 		return fmt.Errorf("block has invalid signature")
 	}
 
 	// 验证区块中的每个交易 // Verify each transaction in the block
-	for _, tx := range b.Transactions {
-		if err := tx.Verify(); err != nil {
-			return err
-		}
+	if err := verifyTransactions(b.Transactions, cache); err != nil {
+		return err
 	}
 
 	// 重新计算数据哈希并验证 // Recalculate data hash and verify
 	dataHash, err := CalculateDataHash(b.Transactions)
 	if err != nil {
-		return nil
+		return err
 	}
 	if dataHash != b.DataHash {
 		return fmt.Errorf("block (%s) has an invalid data hash", b.DataHash)
@@ -2,6 +2,8 @@ package core
 
 import (
 	"bytes"
+	"encoding/hex"
+	"github.com/golang/protobuf/proto"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +25,50 @@ func TestSignBlock(t *testing.T) {
 	assert.NotNil(t, b.Signature)     // 验证签名是否存在 // Verify if the signature is present
 }
 
+// TestHeaderCanonicalBytes 测试区块头规范编码和区块哈希的测试向量
+// TestHeaderCanonicalBytes tests the test vectors of the canonical header encoding and the block hash
+func TestHeaderCanonicalBytes(t *testing.T) {
+	header := &Header{
+		Version:   HeaderVersion,
+		ChainID:   1,
+		Height:    2,
+		Timestamp: 3,
+	}
+	for i := range header.PrevBlockHash {
+		header.PrevBlockHash[i] = 0xaa
+		header.DataHash[i] = 0xbb
+	}
+
+	expected := "00000001" + // version
+		"00000001" + // chainID
+		"00000002" + // height
+		"0000000000000003" + // timestamp
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // prevBlockHash
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" // dataHash
+	assert.Equal(t, expected, hex.EncodeToString(header.Bytes()))
+	assert.Equal(t, "8a8acb8a60c4de8e605f84a8171267861cceab0c59f3653ca2c9212526e857c8", BlockHasher{}.Hash(header).String())
+
+	// 默认创世区块的哈希 // Hash of the default genesis block
+	genesis, err := DefaultGenesis(1).Block()
+	assert.Nil(t, err)
+	assert.Equal(t, "7f595376c1e0bf9e02bdeeb1a432827fb0fc53c401452e52670046c6a9ab1830", genesis.Hash(BlockHasher{}).String())
+}
+
+// TestVerifyBlockCoversHeader 测试区块签名覆盖区块头的所有字段
+// TestVerifyBlockCoversHeader tests that the block signature covers every header field
+func TestVerifyBlockCoversHeader(t *testing.T) {
+	b := randomBlock(t, 1, types.Hash{})
+	assert.Nil(t, b.Verify())
+
+	b.Timestamp++
+	assert.NotNil(t, b.Verify())
+	b.Timestamp--
+	b.ChainID++
+	assert.NotNil(t, b.Verify())
+	b.ChainID--
+	assert.Nil(t, b.Verify())
+}
+
 // TestDecodeEncodeBlock 测试区块的编码和解码功能
 // TestDecodeEncodeBlock tests the block encoding and decoding functionality
 func TestDecodeEncodeBlock(t *testing.T) {
@@ -35,8 +81,33 @@ func TestDecodeEncodeBlock(t *testing.T) {
 	bDecode := new(Block)
 	// 解码区块 // Decode the block
 	assert.Nil(t, NewProtobufBlockDecoder(buf).Decode(bDecode))
+	// 解码后的区块和交易带有重新计算的哈希 // The decoded block and transactions carry their recomputed hash
+	b.Hash(BlockHasher{})
+	for _, tx := range b.Transactions {
+		tx.Hash(TxHasher{})
+	}
 	// 验证编码和解码后的区块是否相等 // Verify if the encoded and decoded blocks are equal
 	assert.Equal(t, b, bDecode)
+
+	// 缓存的哈希与区块头不符时，解码后的哈希仍按区块头计算 // When the cached hash does not match the header the decoded hash is still computed from the header
+	b.Timestamp++
+	buf.Reset()
+	assert.Nil(t, NewProtobufBlockEncoder(buf).Encode(b))
+	bDecode = new(Block)
+	assert.Nil(t, NewProtobufBlockDecoder(buf).Decode(bDecode))
+	assert.Equal(t, BlockHasher{}.Hash(b.Header), bDecode.Hash(BlockHasher{}))
+	assert.NotEqual(t, b.Hash(BlockHasher{}), bDecode.Hash(BlockHasher{}))
+}
+
+// TestDecodeBlockWithoutHeader 测试解码空的或没有区块头的区块时返回错误而不是崩溃
+// TestDecodeBlockWithoutHeader tests that decoding an empty block or one without a header returns an error instead of panicking
+func TestDecodeBlockWithoutHeader(t *testing.T) {
+	assert.NotNil(t, NewProtobufBlockDecoder(bytes.NewReader(nil)).Decode(new(Block)))
+
+	b := randomBlock(t, 1, types.Hash{})
+	data, err := proto.Marshal(&ProtoBlock{Validator: b.Validator.ToSlice(), Signature: b.Signature.ToBytes()})
+	assert.Nil(t, err)
+	assert.NotNil(t, NewProtobufBlockDecoder(bytes.NewReader(data)).Decode(new(Block)))
 }
 
 // TestVerifyBlock 测试区块签名验证功能
@@ -62,15 +133,51 @@ func TestVerifyBlock(t *testing.T) {
 	assert.NotNil(t, b.Verify()) // 验证签名是否无效 // Verify if the signature is invalid
 }
 
+// TestVerifyBlockKeyTypes 测试不同签名算法的区块和交易可以混合验证，并在编码往返后保持有效
+// TestVerifyBlockKeyTypes tests that blocks and transactions of different signature schemes verify together and stay valid after an encoding round trip
+func TestVerifyBlockKeyTypes(t *testing.T) {
+	var txx []*Transaction
+	for _, keyType := range []crypto.KeyType{crypto.KeyTypeP256, crypto.KeyTypeEd25519, crypto.KeyTypeSecp256k1} {
+		privateKey, err := crypto.GenerateKey(keyType)
+		assert.Nil(t, err)
+		tx := NewDeployTransaction([]byte("foo"))
+		assert.Nil(t, tx.Sign(privateKey))
+		txx = append(txx, tx)
+	}
+
+	validatorKey, err := crypto.GenerateKey(crypto.KeyTypeEd25519)
+	assert.Nil(t, err)
+	b := randomBlockWithTransactions(t, 1, types.Hash{}, txx)
+	assert.Nil(t, b.Sign(validatorKey))
+	assert.Nil(t, b.Verify())
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, NewProtobufBlockEncoder(buf).Encode(b))
+	bDecode := new(Block)
+	assert.Nil(t, NewProtobufBlockDecoder(buf).Decode(bDecode))
+	assert.Nil(t, bDecode.Verify())
+	assert.Equal(t, crypto.KeyTypeEd25519, bDecode.Validator.Type)
+
+	// 签名和公钥的算法不一致时验证失败 // Verification fails when the signature and public key schemes differ
+	bDecode.Signature.Type = crypto.KeyTypeSecp256k1
+	assert.NotNil(t, bDecode.Verify())
+}
+
 // randomBlock 创建一个随机区块
 // randomBlock creates a random block
 func randomBlock(t *testing.T, height uint32, prevBlockHash types.Hash) *Block {
-	// 生成私钥 // Generate a private key
-	privateKey := crypto.GeneratePrivateKey()
-
 	// 创建一个随机交易并签名 // Create a random transaction with a signature
 	tx := randomTxWithSignature(t)
 
+	return randomBlockWithTransactions(t, height, prevBlockHash, []*Transaction{tx})
+}
+
+// randomBlockWithTransactions 创建一个包含指定交易的随机区块
+// randomBlockWithTransactions creates a random block containing the given transactions
+func randomBlockWithTransactions(t *testing.T, height uint32, prevBlockHash types.Hash, txx []*Transaction) *Block {
+	// 生成私钥 // Generate a private key
+	privateKey := crypto.GeneratePrivateKey()
+
 	// 创建区块头 // Create the block header
 	header := &Header{
 		Version:       1,
@@ -80,7 +187,7 @@ func randomBlock(t *testing.T, height uint32, prevBlockHash types.Hash) *Block {
 	}
 
 	// 创建区块并返回 // Create and return the block
-	b, err := NewBlock(header, []*Transaction{tx})
+	b, err := NewBlock(header, txx)
 	assert.Nil(t, err) // 验证区块创建是否成功 // Verify if the block creation is successful
 
 	// 计算并设置区块数据哈希 // Calculate and set the block data hash
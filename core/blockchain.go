@@ -3,18 +3,25 @@ package core
 import (
 	"fmt"
 	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/types"
+	"sort"
 	"sync"
 )
 
 // Blockchain 结构体表示区块链
 // Blockchain struct represents the blockchain
 type Blockchain struct {
-	logger        log.Logger   // 日志记录器 // Logger for logging
-	store         Storage      // 存储区块链数据的存储接口 // Storage interface for blockchain data
-	lock          sync.RWMutex // 读写锁，用于并发访问区块链 // Read-write lock for concurrent access to the blockchain
-	headers       []*Header    // 区块链中的区块头列表 // List of block headers in the blockchain
-	validator     Validator    // 验证器，用于验证区块 // Validator for validating blocks
-	contractState *State       // 合约状态 // Contract state
+	logger        log.Logger    // 日志记录器 // Logger for logging
+	store         Storage       // 存储区块链数据的存储接口 // Storage interface for blockchain data
+	lock          sync.RWMutex  // 读写锁，用于并发访问区块链 // Read-write lock for concurrent access to the blockchain
+	importLock    sync.Mutex    // 串行化区块导入，从验证到追加区块期间一直持有 // Serializes block imports, held from validation until the block is appended
+	headers       []*Header     // 区块链中的区块头列表 // List of block headers in the blockchain
+	validator     Validator     // 验证器，用于验证区块 // Validator for validating blocks
+	contractState *State        // 合约状态 // Contract state
+	accountState  *AccountState // 账户状态 // Account state
+	validatorSet  *ValidatorSet // 允许出块的验证者集合，为空时不限制 // Validators allowed to propose blocks, unrestricted when empty
+	blockReward   uint64        // 每个区块铸造给验证者的奖励 // Reward minted to the validator of every block
+	sigCache      *SigCache     // 已验证的交易签名，交易池准入时写入，导入区块时复用 // Verified transaction signatures, filled on pool admission and reused on block import
 }
 
 // NewBlockchain 创建一个新的区块链
@@ -23,8 +30,11 @@ func NewBlockchain(l log.Logger, genesis *Block) (*Blockchain, error) {
 	// 初始化区块链实例 // Initialize blockchain instance
 	bc := &Blockchain{
 		contractState: NewState(),
+		accountState:  NewAccountState(),
+		validatorSet:  NewValidatorSet(),
 		headers:       []*Header{},
 		store:         NewMemoryStore(), // 使用内存存储 // Use in-memory storage
+		sigCache:      NewSigCache(DefaultSigCacheSize),
 		logger:        l,
 	}
 	// 设置区块验证器 // Set the block validator
@@ -35,33 +45,169 @@ func NewBlockchain(l log.Logger, genesis *Block) (*Blockchain, error) {
 	return bc, err
 }
 
+// NewBlockchainFromGenesis 根据创世配置创建区块链，并写入初始账户、合约状态、验证者集合和区块奖励
+// NewBlockchainFromGenesis creates a blockchain from the genesis configuration and writes the initial accounts, contract state, validator set and block reward
+func NewBlockchainFromGenesis(l log.Logger, g *Genesis) (*Blockchain, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	genesis, err := g.Block()
+	if err != nil {
+		return nil, err
+	}
+	bc, err := NewBlockchain(l, genesis)
+	if err != nil {
+		return nil, err
+	}
+
+	for addr, account := range g.Alloc {
+		if err := bc.accountState.AddBalance(addr, account.Balance); err != nil {
+			return nil, err
+		}
+		if len(account.Code) > 0 {
+			if err := bc.accountState.SetCode(addr, account.Code); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for k, v := range g.Storage {
+		if err := bc.contractState.Put([]byte(k), v); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range g.Validators {
+		bc.validatorSet.Add(key)
+	}
+	bc.SetBlockReward(g.Consensus.BlockReward)
+
+	return bc, nil
+}
+
 // SetValidator 设置区块链的验证器
 // SetValidator sets the validator for the blockchain
 func (bc *Blockchain) SetValidator(v Validator) {
 	bc.validator = v
 }
 
+// SetBlockReward 设置每个区块铸造给验证者的奖励
+// SetBlockReward sets the reward minted to the validator of every block
+func (bc *Blockchain) SetBlockReward(reward uint64) {
+	bc.blockReward = reward
+}
+
+// BlockReward 返回每个区块铸造给验证者的奖励
+// BlockReward returns the reward minted to the validator of every block
+func (bc *Blockchain) BlockReward() uint64 {
+	return bc.blockReward
+}
+
 // AddBlock 添加一个区块到区块链
 // AddBlock adds a block to the blockchain
 func (bc *Blockchain) AddBlock(b *Block) error {
+	// 同一时间只导入一个区块，避免两个区块基于同一状态通过验证 // Import one block at a time so two blocks never validate against the same state
+	bc.importLock.Lock()
+	defer bc.importLock.Unlock()
+
 	// 验证区块 // Validate the block
 	if err := bc.validator.ValidateBlock(b); err != nil {
 		return err
 	}
 
-	// 执行每个交易的数据代码 // Execute the code for each transaction's data
-	for _, tx := range b.Transactions {
-		bc.logger.Log("msg", "executing code", "len", len(tx.Data), "hash", tx.Hash(&TxHasher{}))
-		vm := NewVM(tx.Data, bc.contractState) // 创建虚拟机实例 // Create a VM instance
-		if err := vm.Run(); err != nil {       // 运行虚拟机 // Run the VM
-			return err
-		}
-
-		fmt.Printf("STATE: %+v \n", vm.contractState) // 打印合约状态 // Print the contract state
+	// 在状态副本上执行一次区块，核对交易类型、序号、转账、手续费和区块奖励，全部成功后再提交
+	// Execute the block once on copies of the state to check transaction types, nonces, transfers, fees and the block reward, and commit once everything succeeded
+	ctx := bc.newExecutionContext(b.Validator.Address(), b.Height)
+	if err := bc.executeBlock(ctx, b); err != nil {
+		return fmt.Errorf("block (%s) has invalid transaction: %w", b.Hash(BlockHasher{}), err)
 	}
+	// 提交状态并追加区块 // Commit the state and append the block
+	return bc.commitBlock(ctx, b)
+}
+
+// ChainID 返回区块链的链 ID，由创世区块决定
+// ChainID returns the chain ID of the blockchain, which is set by the genesis block
+func (bc *Blockchain) ChainID() uint32 {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.headers[0].ChainID
+}
+
+// GenesisHash 返回创世区块的哈希
+// GenesisHash returns the hash of the genesis block
+func (bc *Blockchain) GenesisHash() types.Hash {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return BlockHasher{}.Hash(bc.headers[0])
+}
+
+// GetBalance 返回指定地址的账户余额
+// GetBalance returns the account balance of the given address
+func (bc *Blockchain) GetBalance(address types.Address) uint64 {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.accountState.GetBalance(address)
+}
+
+// GetNonce 返回指定地址下一笔交易应使用的序号
+// GetNonce returns the nonce expected for the next transaction of the given address
+func (bc *Blockchain) GetNonce(address types.Address) uint64 {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.accountState.GetNonce(address)
+}
+
+// GetCode 返回指定地址的合约代码
+// GetCode returns the contract code at the given address
+func (bc *Blockchain) GetCode(address types.Address) []byte {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
 
-	// 添加未验证的区块 // Add the block without validation
-	return bc.addBlockWithoutValidation(b)
+	return bc.accountState.GetCode(address)
+}
+
+// IsValidator 检查地址是否属于验证者集合
+// IsValidator checks if the address belongs to the validator set
+func (bc *Blockchain) IsValidator(address types.Address) bool {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.validatorSet.Contains(address)
+}
+
+// ValidatorCount 返回验证者集合的大小，为 0 时任何节点都可以出块
+// ValidatorCount returns the size of the validator set, any node may propose blocks when it is 0
+func (bc *Blockchain) ValidatorCount() int {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.validatorSet.Len()
+}
+
+// ExecutableTransactions 返回由指定验证者打包时可以按序号顺序执行的交易，跳过会失败的交易和不完整或会失败的交易包
+// ExecutableTransactions returns the transactions that can be executed in nonce order when packed by the given validator, skipping the ones that would fail as well as incomplete or failing bundles
+func (bc *Blockchain) ExecutableTransactions(validator types.Address, txx []*Transaction) []*Transaction {
+	// 交易包作为一个单元，按首笔交易的序号稳定排序，保证同一发送方的交易按顺序执行
+	// Bundles form one unit, stable sort by the nonce of the first transaction so transactions of the same sender execute in order
+	units := groupTransactions(txx)
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i][0].Nonce < units[j][0].Nonce
+	})
+
+	ctx := bc.newExecutionContext(validator, bc.Height()+1)
+
+	executable := make([]*Transaction, 0, len(txx))
+	for _, unit := range units {
+		// 失败的单元整体回滚 // Failing units are rolled back as a whole
+		if err := executeUnit(ctx, unit); err != nil {
+			bc.logger.Log("msg", "skipping transaction", "hash", unit[0].Hash(TxHasher{}), "size", len(unit), "err", err)
+			continue
+		}
+		executable = append(executable, unit...)
+	}
+	return executable
 }
 
 // GetHeader 获取指定高度的区块头
@@ -78,6 +224,16 @@ func (bc *Blockchain) GetHeader(height uint32) (*Header, error) {
 	return bc.headers[height], nil
 }
 
+// GetBlock 获取指定高度的区块
+// GetBlock gets the block at the given height
+func (bc *Blockchain) GetBlock(height uint32) (*Block, error) {
+	// 检查请求的高度是否有效 // Check if the requested height is valid
+	if height > bc.Height() {
+		return nil, fmt.Errorf("given height (%d) too high", height)
+	}
+	return bc.store.Get(height)
+}
+
 // HasBlock 检查区块链是否包含某个高度的区块
 // HasBlock checks if the blockchain contains a block of a certain height
 func (bc *Blockchain) HasBlock(height uint32) bool {
@@ -99,6 +255,12 @@ func (bc *Blockchain) addBlockWithoutValidation(b *Block) error {
 	bc.lock.Lock()
 	defer bc.lock.Unlock()
 
+	return bc.appendBlock(b)
+}
+
+// appendBlock 追加区块头并存储区块，调用者需持有写锁
+// appendBlock appends the block header and stores the block, the caller must hold the write lock
+func (bc *Blockchain) appendBlock(b *Block) error {
 	// 添加区块头到区块链 // Add block header to the blockchain
 	bc.headers = append(bc.headers, b.Header)
 
@@ -113,3 +275,53 @@ func (bc *Blockchain) addBlockWithoutValidation(b *Block) error {
 	// 将区块存储到存储接口中 // Store the block in the storage interface
 	return bc.store.Put(b)
 }
+
+// newExecutionContext 基于当前状态的副本创建执行上下文
+// newExecutionContext creates an execution context on copies of the current state
+func (bc *Blockchain) newExecutionContext(proposer types.Address, height uint32) *ExecutionContext {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return &ExecutionContext{
+		Accounts:   bc.accountState.Copy(),
+		Contracts:  bc.contractState.Copy(),
+		Validators: bc.validatorSet.Copy(),
+		Proposer:   proposer,
+		Height:     height,
+	}
+}
+
+// executeBlock 按顺序执行区块中的交易，并向验证者铸造区块奖励，任意交易失败时整个上下文作废，因此不需要快照
+// executeBlock executes the transactions of the block in order and mints the block reward to the validator, the whole context is discarded when any transaction fails so no snapshot is needed
+func (bc *Blockchain) executeBlock(ctx *ExecutionContext, b *Block) error {
+	units, err := splitBlockTransactions(b.Transactions)
+	if err != nil {
+		return err
+	}
+	for _, unit := range units {
+		for _, tx := range unit {
+			if err := executeTransaction(ctx, tx); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Accounts.AddBalance(ctx.Proposer, bc.blockReward)
+}
+
+// commitBlock 在同一次加锁中用执行后的状态替换当前状态并追加区块，读取者不会看到状态和高度不一致
+// commitBlock replaces the current state with the executed one and appends the block under a single lock, so readers never see the state and the height disagree
+func (bc *Blockchain) commitBlock(ctx *ExecutionContext, b *Block) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.accountState = ctx.Accounts
+	bc.contractState = ctx.Contracts
+	bc.validatorSet = ctx.Validators
+	return bc.appendBlock(b)
+}
+
+// SigCache 方法返回区块链的签名缓存
+// SigCache method returns the signature cache of the blockchain
+func (bc *Blockchain) SigCache() *SigCache {
+	return bc.sigCache
+}
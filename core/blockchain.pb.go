@@ -25,11 +25,8 @@ type ProtoTxHeader struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Version       uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`            // 交易版本号
-	PrevBlockHash []byte `protobuf:"bytes,2,opt,name=prevBlockHash,proto3" json:"prevBlockHash,omitempty"` // 前一个区块的哈希值
-	Timestamp     uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`        // 交易生成的时间戳
-	Height        uint32 `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`              // 区块高度
-	Nonce         uint64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`                // 随机数（用于 PoW 等共识算法）
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // 交易信封版本号
+	Type    uint32 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`       // 交易类型
 }
 
 func (x *ProtoTxHeader) Reset() {
@@ -71,32 +68,66 @@ func (x *ProtoTxHeader) GetVersion() uint32 {
 	return 0
 }
 
-func (x *ProtoTxHeader) GetPrevBlockHash() []byte {
+func (x *ProtoTxHeader) GetType() uint32 {
 	if x != nil {
-		return x.PrevBlockHash
+		return x.Type
 	}
-	return nil
+	return 0
 }
 
-func (x *ProtoTxHeader) GetTimestamp() uint64 {
-	if x != nil {
-		return x.Timestamp
+type ProtoValidatorUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PublicKey []byte `protobuf:"bytes,1,opt,name=publicKey,proto3" json:"publicKey,omitempty"` // 验证者公钥
+	Remove    bool   `protobuf:"varint,2,opt,name=remove,proto3" json:"remove,omitempty"`      // 是否移除该验证者
+}
+
+func (x *ProtoValidatorUpdate) Reset() {
+	*x = ProtoValidatorUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_core_blockchain_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return 0
 }
 
-func (x *ProtoTxHeader) GetHeight() uint32 {
+func (x *ProtoValidatorUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoValidatorUpdate) ProtoMessage() {}
+
+func (x *ProtoValidatorUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_core_blockchain_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoValidatorUpdate.ProtoReflect.Descriptor instead.
+func (*ProtoValidatorUpdate) Descriptor() ([]byte, []int) {
+	return file_core_blockchain_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProtoValidatorUpdate) GetPublicKey() []byte {
 	if x != nil {
-		return x.Height
+		return x.PublicKey
 	}
-	return 0
+	return nil
 }
 
-func (x *ProtoTxHeader) GetNonce() uint64 {
+func (x *ProtoValidatorUpdate) GetRemove() bool {
 	if x != nil {
-		return x.Nonce
+		return x.Remove
 	}
-	return 0
+	return false
 }
 
 type ProtoTransaction struct {
@@ -104,18 +135,29 @@ type ProtoTransaction struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data      []byte         `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`            // 交易数据
-	From      []byte         `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`            // 发送方公钥
-	Signature []byte         `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`  // 交易签名
-	Hash      []byte         `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`            // 交易哈希
-	FirstSeen int64          `protobuf:"varint,5,opt,name=firstSeen,proto3" json:"firstSeen,omitempty"` // 首次见到该交易的时间戳
-	Header    *ProtoTxHeader `protobuf:"bytes,6,opt,name=header,proto3" json:"header,omitempty"`        // 交易头
+	Data        []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                // 交易数据
+	From        []byte                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`                // 发送方公钥
+	Signature   []byte                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`      // 交易签名
+	Hash        []byte                 `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`                // 交易哈希
+	FirstSeen   int64                  `protobuf:"varint,5,opt,name=firstSeen,proto3" json:"firstSeen,omitempty"`     // 首次见到该交易的时间戳
+	Header      *ProtoTxHeader         `protobuf:"bytes,6,opt,name=header,proto3" json:"header,omitempty"`            // 交易头
+	To          []byte                 `protobuf:"bytes,7,opt,name=to,proto3" json:"to,omitempty"`                    // 接收方地址
+	Value       uint64                 `protobuf:"varint,8,opt,name=value,proto3" json:"value,omitempty"`             // 转账金额
+	Nonce       uint64                 `protobuf:"varint,9,opt,name=nonce,proto3" json:"nonce,omitempty"`             // 发送方账户的交易序号
+	ChainID     uint32                 `protobuf:"varint,10,opt,name=chainID,proto3" json:"chainID,omitempty"`        // 交易所属链的 ID
+	Fee         uint64                 `protobuf:"varint,11,opt,name=fee,proto3" json:"fee,omitempty"`                // 支付给区块验证者的手续费
+	Multisig    *ProtoMultisigAccount  `protobuf:"bytes,12,opt,name=multisig,proto3" json:"multisig,omitempty"`       // 多签发送方账户
+	Signatures  []*ProtoMultiSignature `protobuf:"bytes,13,rep,name=signatures,proto3" json:"signatures,omitempty"`   // 多签成员签名
+	ValidAfter  uint32                 `protobuf:"varint,14,opt,name=validAfter,proto3" json:"validAfter,omitempty"`  // 交易可被打包的最低区块高度
+	ValidUntil  uint32                 `protobuf:"varint,15,opt,name=validUntil,proto3" json:"validUntil,omitempty"`  // 交易可被打包的最高区块高度
+	Bundle      *ProtoBundleRef        `protobuf:"bytes,16,opt,name=bundle,proto3" json:"bundle,omitempty"`           // 所属的原子交易包
+	FromAddress []byte                 `protobuf:"bytes,17,opt,name=fromAddress,proto3" json:"fromAddress,omitempty"` // 可选的发送方地址
 }
 
 func (x *ProtoTransaction) Reset() {
 	*x = ProtoTransaction{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_core_blockchain_proto_msgTypes[1]
+		mi := &file_core_blockchain_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -128,7 +170,7 @@ func (x *ProtoTransaction) String() string {
 func (*ProtoTransaction) ProtoMessage() {}
 
 func (x *ProtoTransaction) ProtoReflect() protoreflect.Message {
-	mi := &file_core_blockchain_proto_msgTypes[1]
+	mi := &file_core_blockchain_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -141,7 +183,7 @@ func (x *ProtoTransaction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtoTransaction.ProtoReflect.Descriptor instead.
 func (*ProtoTransaction) Descriptor() ([]byte, []int) {
-	return file_core_blockchain_proto_rawDescGZIP(), []int{1}
+	return file_core_blockchain_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ProtoTransaction) GetData() []byte {
@@ -186,6 +228,303 @@ func (x *ProtoTransaction) GetHeader() *ProtoTxHeader {
 	return nil
 }
 
+func (x *ProtoTransaction) GetTo() []byte {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ProtoTransaction) GetValue() uint64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetChainID() uint32 {
+	if x != nil {
+		return x.ChainID
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetFee() uint64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetMultisig() *ProtoMultisigAccount {
+	if x != nil {
+		return x.Multisig
+	}
+	return nil
+}
+
+func (x *ProtoTransaction) GetSignatures() []*ProtoMultiSignature {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+func (x *ProtoTransaction) GetValidAfter() uint32 {
+	if x != nil {
+		return x.ValidAfter
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetValidUntil() uint32 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+func (x *ProtoTransaction) GetBundle() *ProtoBundleRef {
+	if x != nil {
+		return x.Bundle
+	}
+	return nil
+}
+
+func (x *ProtoTransaction) GetFromAddress() []byte {
+	if x != nil {
+		return x.FromAddress
+	}
+	return nil
+}
+
+type ProtoBundleRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`        // 交易包 ID
+	Index uint32 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"` // 交易在包中的索引
+	Size  uint32 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`   // 包中交易的数量
+}
+
+func (x *ProtoBundleRef) Reset() {
+	*x = ProtoBundleRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_core_blockchain_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoBundleRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoBundleRef) ProtoMessage() {}
+
+func (x *ProtoBundleRef) ProtoReflect() protoreflect.Message {
+	mi := &file_core_blockchain_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoBundleRef.ProtoReflect.Descriptor instead.
+func (*ProtoBundleRef) Descriptor() ([]byte, []int) {
+	return file_core_blockchain_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProtoBundleRef) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *ProtoBundleRef) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ProtoBundleRef) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ProtoBundle struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Transactions []*ProtoTransaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"` // 交易包的成员交易
+}
+
+func (x *ProtoBundle) Reset() {
+	*x = ProtoBundle{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_core_blockchain_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoBundle) ProtoMessage() {}
+
+func (x *ProtoBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_core_blockchain_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoBundle.ProtoReflect.Descriptor instead.
+func (*ProtoBundle) Descriptor() ([]byte, []int) {
+	return file_core_blockchain_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProtoBundle) GetTransactions() []*ProtoTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type ProtoMultisigAccount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Threshold  uint32   `protobuf:"varint,1,opt,name=threshold,proto3" json:"threshold,omitempty"`  // 需要的最少签名数
+	PublicKeys [][]byte `protobuf:"bytes,2,rep,name=publicKeys,proto3" json:"publicKeys,omitempty"` // 成员公钥
+}
+
+func (x *ProtoMultisigAccount) Reset() {
+	*x = ProtoMultisigAccount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_core_blockchain_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoMultisigAccount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoMultisigAccount) ProtoMessage() {}
+
+func (x *ProtoMultisigAccount) ProtoReflect() protoreflect.Message {
+	mi := &file_core_blockchain_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoMultisigAccount.ProtoReflect.Descriptor instead.
+func (*ProtoMultisigAccount) Descriptor() ([]byte, []int) {
+	return file_core_blockchain_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProtoMultisigAccount) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *ProtoMultisigAccount) GetPublicKeys() [][]byte {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+type ProtoMultiSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index     uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`        // 签名成员的索引
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"` // 成员签名
+}
+
+func (x *ProtoMultiSignature) Reset() {
+	*x = ProtoMultiSignature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_core_blockchain_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoMultiSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoMultiSignature) ProtoMessage() {}
+
+func (x *ProtoMultiSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_core_blockchain_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoMultiSignature.ProtoReflect.Descriptor instead.
+func (*ProtoMultiSignature) Descriptor() ([]byte, []int) {
+	return file_core_blockchain_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProtoMultiSignature) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ProtoMultiSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
 type ProtoBlockHeader struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -196,12 +535,13 @@ type ProtoBlockHeader struct {
 	PrevBlockHash []byte `protobuf:"bytes,3,opt,name=prevBlockHash,proto3" json:"prevBlockHash,omitempty"` // 前一个区块的哈希值
 	Timestamp     uint64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`        // 区块生成的时间戳
 	Height        uint32 `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`              // 区块高度
+	ChainID       uint32 `protobuf:"varint,6,opt,name=chainID,proto3" json:"chainID,omitempty"`            // 区块所属链的 ID
 }
 
 func (x *ProtoBlockHeader) Reset() {
 	*x = ProtoBlockHeader{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_core_blockchain_proto_msgTypes[2]
+		mi := &file_core_blockchain_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -214,7 +554,7 @@ func (x *ProtoBlockHeader) String() string {
 func (*ProtoBlockHeader) ProtoMessage() {}
 
 func (x *ProtoBlockHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_core_blockchain_proto_msgTypes[2]
+	mi := &file_core_blockchain_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -227,7 +567,7 @@ func (x *ProtoBlockHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtoBlockHeader.ProtoReflect.Descriptor instead.
 func (*ProtoBlockHeader) Descriptor() ([]byte, []int) {
-	return file_core_blockchain_proto_rawDescGZIP(), []int{2}
+	return file_core_blockchain_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ProtoBlockHeader) GetVersion() uint32 {
@@ -265,6 +605,13 @@ func (x *ProtoBlockHeader) GetHeight() uint32 {
 	return 0
 }
 
+func (x *ProtoBlockHeader) GetChainID() uint32 {
+	if x != nil {
+		return x.ChainID
+	}
+	return 0
+}
+
 type ProtoBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -280,7 +627,7 @@ type ProtoBlock struct {
 func (x *ProtoBlock) Reset() {
 	*x = ProtoBlock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_core_blockchain_proto_msgTypes[3]
+		mi := &file_core_blockchain_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -293,7 +640,7 @@ func (x *ProtoBlock) String() string {
 func (*ProtoBlock) ProtoMessage() {}
 
 func (x *ProtoBlock) ProtoReflect() protoreflect.Message {
-	mi := &file_core_blockchain_proto_msgTypes[3]
+	mi := &file_core_blockchain_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -306,7 +653,7 @@ func (x *ProtoBlock) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtoBlock.ProtoReflect.Descriptor instead.
 func (*ProtoBlock) Descriptor() ([]byte, []int) {
-	return file_core_blockchain_proto_rawDescGZIP(), []int{3}
+	return file_core_blockchain_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ProtoBlock) GetHeader() *ProtoBlockHeader {
@@ -348,55 +695,98 @@ var File_core_blockchain_proto protoreflect.FileDescriptor
 
 var file_core_blockchain_proto_rawDesc = []byte{
 	0x0a, 0x15, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69,
-	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x9b, 0x01,
-	0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x78, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
-	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65,
-	0x76, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12,
-	0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a,
-	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68,
-	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x22, 0xb7, 0x01, 0x0a, 0x10,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67,
-	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x69,
-	0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x66,
-	0x69, 0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x2b, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64,
-	0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x78, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0xa4, 0x01, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x48, 0x61, 0x73, 0x68,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x48, 0x61, 0x73, 0x68,
-	0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73,
-	0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
-	0x74, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0xc8, 0x01, 0x0a,
-	0x0a, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2e, 0x0a, 0x06, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f,
-	0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x0c, 0x74,
-	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x72,
-	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64,
-	0x61, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69,
-	0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x6f, 0x6e, 0x79, 0x53, 0x70, 0x2f, 0x67, 0x6f, 0x2d,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x3d, 0x0a,
+	0x0d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x78, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x4c, 0x0a, 0x14,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b,
+	0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x22, 0xa2, 0x04, 0x0a, 0x10, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x2b, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x54, 0x78, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x02, 0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65,
+	0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x36, 0x0a, 0x08,
+	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x73, 0x69, 0x67, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4d, 0x75, 0x6c, 0x74, 0x69,
+	0x73, 0x69, 0x67, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x08, 0x6d, 0x75, 0x6c, 0x74,
+	0x69, 0x73, 0x69, 0x67, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12,
+	0x1e, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12,
+	0x1e, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x12,
+	0x2c, 0x0a, 0x06, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x75, 0x6e, 0x64,
+	0x6c, 0x65, 0x52, 0x65, 0x66, 0x52, 0x06, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x20, 0x0a,
+	0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22,
+	0x4a, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x65,
+	0x66, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x49, 0x0a, 0x0b, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x3a, 0x0a, 0x0c, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x54, 0x0a, 0x14, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4d,
+	0x75, 0x6c, 0x74, 0x69, 0x73, 0x69, 0x67, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x1e, 0x0a, 0x0a,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x22, 0x49, 0x0a, 0x13,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0xbe, 0x01, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x48, 0x61,
+	0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x48, 0x61,
+	0x73, 0x68, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48,
+	0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x22, 0xc8, 0x01, 0x0a, 0x0a, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2e, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52,
+	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68,
+	0x61, 0x73, 0x68, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x6c, 0x6f, 0x6e, 0x79, 0x53, 0x70, 0x2f, 0x67, 0x6f, 0x2d, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -411,22 +801,31 @@ func file_core_blockchain_proto_rawDescGZIP() []byte {
 	return file_core_blockchain_proto_rawDescData
 }
 
-var file_core_blockchain_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_core_blockchain_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_core_blockchain_proto_goTypes = []any{
-	(*ProtoTxHeader)(nil),    // 0: core.ProtoTxHeader
-	(*ProtoTransaction)(nil), // 1: core.ProtoTransaction
-	(*ProtoBlockHeader)(nil), // 2: core.ProtoBlockHeader
-	(*ProtoBlock)(nil),       // 3: core.ProtoBlock
+	(*ProtoTxHeader)(nil),        // 0: core.ProtoTxHeader
+	(*ProtoValidatorUpdate)(nil), // 1: core.ProtoValidatorUpdate
+	(*ProtoTransaction)(nil),     // 2: core.ProtoTransaction
+	(*ProtoBundleRef)(nil),       // 3: core.ProtoBundleRef
+	(*ProtoBundle)(nil),          // 4: core.ProtoBundle
+	(*ProtoMultisigAccount)(nil), // 5: core.ProtoMultisigAccount
+	(*ProtoMultiSignature)(nil),  // 6: core.ProtoMultiSignature
+	(*ProtoBlockHeader)(nil),     // 7: core.ProtoBlockHeader
+	(*ProtoBlock)(nil),           // 8: core.ProtoBlock
 }
 var file_core_blockchain_proto_depIdxs = []int32{
 	0, // 0: core.ProtoTransaction.header:type_name -> core.ProtoTxHeader
-	2, // 1: core.ProtoBlock.header:type_name -> core.ProtoBlockHeader
-	1, // 2: core.ProtoBlock.transactions:type_name -> core.ProtoTransaction
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 1: core.ProtoTransaction.multisig:type_name -> core.ProtoMultisigAccount
+	6, // 2: core.ProtoTransaction.signatures:type_name -> core.ProtoMultiSignature
+	3, // 3: core.ProtoTransaction.bundle:type_name -> core.ProtoBundleRef
+	2, // 4: core.ProtoBundle.transactions:type_name -> core.ProtoTransaction
+	7, // 5: core.ProtoBlock.header:type_name -> core.ProtoBlockHeader
+	2, // 6: core.ProtoBlock.transactions:type_name -> core.ProtoTransaction
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_core_blockchain_proto_init() }
@@ -448,7 +847,7 @@ func file_core_blockchain_proto_init() {
 			}
 		}
 		file_core_blockchain_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*ProtoTransaction); i {
+			switch v := v.(*ProtoValidatorUpdate); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -460,7 +859,7 @@ func file_core_blockchain_proto_init() {
 			}
 		}
 		file_core_blockchain_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*ProtoBlockHeader); i {
+			switch v := v.(*ProtoTransaction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -472,6 +871,66 @@ func file_core_blockchain_proto_init() {
 			}
 		}
 		file_core_blockchain_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ProtoBundleRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_core_blockchain_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ProtoBundle); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_core_blockchain_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ProtoMultisigAccount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_core_blockchain_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ProtoMultiSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_core_blockchain_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ProtoBlockHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_core_blockchain_proto_msgTypes[8].Exporter = func(v any, i int) any {
 			switch v := v.(*ProtoBlock); i {
 			case 0:
 				return &v.state
@@ -490,7 +949,7 @@ func file_core_blockchain_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_core_blockchain_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -1,8 +1,12 @@
 package core
 
 import (
+	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
@@ -58,6 +62,26 @@ func TestGetHeader(t *testing.T) {
 	}
 }
 
+// TestGetBlock 测试获取已存储的区块
+// TestGetBlock tests getting stored blocks
+func TestGetBlock(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	for i := 0; i < 10; i++ {
+		block := randomBlock(t, uint32(i+1), getPrevBlockHash(t, bc, uint32(i+1)))
+		assert.Nil(t, bc.AddBlock(block))
+		stored, err := bc.GetBlock(block.Height)
+		assert.Nil(t, err)
+		assert.Equal(t, block, stored)
+	}
+
+	genesis, err := bc.GetBlock(0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), genesis.Height)
+	_, err = bc.GetBlock(11)
+	assert.NotNil(t, err)
+}
+
 // TestAddBlockToHigh 测试添加高度过高的区块
 // TestAddBlockToHigh tests adding a block with too high height
 func TestAddBlockToHigh(t *testing.T) {
@@ -67,6 +91,245 @@ func TestAddBlockToHigh(t *testing.T) {
 	assert.NotNil(t, bc.AddBlock(randomBlock(t, 3, types.Hash{})))
 }
 
+// TestNativeTransfer 测试原生转账对账户余额的影响
+// TestNativeTransfer tests how native transfers affect account balances
+func TestNativeTransfer(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	tx := NewTransferTransaction(to, 40)
+	assert.Nil(t, tx.Sign(privateKey))
+
+	block := randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})
+	assert.Nil(t, bc.AddBlock(block))
+	assert.Equal(t, uint64(60), bc.GetBalance(from))
+	assert.Equal(t, uint64(40), bc.GetBalance(to))
+}
+
+// TestNativeTransferOverdraw 测试透支账户的区块会被拒绝
+// TestNativeTransferOverdraw tests that a block overdrawing an account is rejected
+func TestNativeTransferOverdraw(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	txA := NewTransferTransaction(to, 60)
+	assert.Nil(t, txA.Sign(privateKey))
+	txB := NewTransferTransaction(to, 50)
+	txB.Nonce = 1
+	assert.Nil(t, txB.Sign(privateKey))
+
+	// 两笔转账合计超过余额 // Both transfers together exceed the balance
+	block := randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{txA, txB})
+	assert.NotNil(t, bc.AddBlock(block))
+	assert.Equal(t, uint64(100), bc.GetBalance(from))
+	assert.Equal(t, uint64(0), bc.GetBalance(to))
+
+	// 只保留可执行的交易 // Only keep the executable transactions
+	assert.Equal(t, []*Transaction{txA}, bc.ExecutableTransactions(types.Address{}, []*Transaction{txA, txB}))
+}
+
+// TestTransactionReplay 测试同一笔交易不能被重复执行
+// TestTransactionReplay tests that the same transaction cannot be executed twice
+func TestTransactionReplay(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	tx := NewTransferTransaction(to, 10)
+	assert.Nil(t, tx.Sign(privateKey))
+
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+	assert.Equal(t, uint64(1), bc.GetNonce(from))
+
+	// 在下一个区块中重放交易 // Replay the transaction in the next block
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 2, getPrevBlockHash(t, bc, 2), []*Transaction{tx})))
+	assert.Equal(t, uint64(90), bc.GetBalance(from))
+
+	// 跳过序号的交易同样会被拒绝 // A transaction skipping a nonce is rejected as well
+	txGap := NewTransferTransaction(to, 10)
+	txGap.Nonce = 2
+	assert.Nil(t, txGap.Sign(privateKey))
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 2, getPrevBlockHash(t, bc, 2), []*Transaction{txGap})))
+}
+
+// TestFeesAndBlockReward 测试手续费和区块奖励记入验证者账户
+// TestFeesAndBlockReward tests that fees and the block reward are credited to the validator
+func TestFeesAndBlockReward(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	bc.SetBlockReward(50)
+
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	tx := NewTransferTransaction(to, 60)
+	tx.Fee = 5
+	assert.Nil(t, tx.Sign(privateKey))
+
+	block := randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})
+	assert.Nil(t, bc.AddBlock(block))
+	assert.Equal(t, uint64(35), bc.GetBalance(from))
+	assert.Equal(t, uint64(60), bc.GetBalance(to))
+	assert.Equal(t, uint64(55), bc.GetBalance(block.Validator.Address()))
+
+	// 无法同时支付转账和手续费的交易会被拒绝 // A transaction that cannot pay both value and fee is rejected
+	tx = NewTransferTransaction(to, 30)
+	tx.Nonce = 1
+	tx.Fee = 10
+	assert.Nil(t, tx.Sign(privateKey))
+	block = randomBlockWithTransactions(t, 2, getPrevBlockHash(t, bc, 2), []*Transaction{tx})
+	assert.NotNil(t, bc.AddBlock(block))
+	assert.Equal(t, uint64(35), bc.GetBalance(from))
+}
+
+// TestTransactionValidityWindow 测试交易只能在有效高度范围内被打包
+// TestTransactionValidityWindow tests that transactions can only be included within their validity window
+func TestTransactionValidityWindow(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	privateKey := crypto.GeneratePrivateKey()
+
+	// 高度 2 之前无效 // Not valid before height 2
+	tx := NewDeployTransaction([]byte("foo"))
+	tx.ValidAfter = 2
+	tx.ValidUntil = 2
+	assert.Nil(t, tx.Sign(privateKey))
+
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+	assert.Nil(t, bc.AddBlock(randomBlock(t, 1, getPrevBlockHash(t, bc, 1))))
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 2, getPrevBlockHash(t, bc, 2), []*Transaction{tx})))
+
+	// 高度 3 已过期 // Expired at height 3
+	expired := NewDeployTransaction([]byte("bar"))
+	expired.Nonce = 1
+	expired.ValidUntil = 2
+	assert.Nil(t, expired.Sign(privateKey))
+	assert.True(t, expired.Expired(3))
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 3, getPrevBlockHash(t, bc, 3), []*Transaction{expired})))
+
+	// 空的有效范围不能通过格式校验 // An empty window fails payload validation
+	empty := NewDeployTransaction([]byte("baz"))
+	empty.ValidAfter = 5
+	empty.ValidUntil = 4
+	assert.NotNil(t, empty.ValidatePayload())
+}
+
+// TestMultisigTransfer 测试多签账户的转账
+// TestMultisigTransfer tests transfers from a multisig account
+func TestMultisigTransfer(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	keys := []crypto.PrivateKey{crypto.GeneratePrivateKey(), crypto.GeneratePrivateKey()}
+	account, err := crypto.NewMultisigAccount(2, []crypto.PublicKey{keys[0].PublicKey(), keys[1].PublicKey()})
+	assert.Nil(t, err)
+	assert.Nil(t, bc.accountState.AddBalance(account.Address(), 100))
+	to := crypto.GeneratePrivateKey().PublicKey().Address()
+
+	// 未达到门限的交易会被拒绝 // A transaction below the threshold is rejected
+	tx := NewTransferTransaction(to, 30)
+	assert.Nil(t, tx.SignMultisig(keys[0], account))
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+
+	assert.Nil(t, tx.SignMultisig(keys[1], account))
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+	assert.Equal(t, uint64(70), bc.GetBalance(account.Address()))
+	assert.Equal(t, uint64(30), bc.GetBalance(to))
+	assert.Equal(t, uint64(1), bc.GetNonce(account.Address()))
+}
+
+// TestAddBlockWrongChainID 测试拒绝属于其他链的区块和交易
+// TestAddBlockWrongChainID tests rejecting blocks and transactions of another chain
+func TestAddBlockWrongChainID(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+
+	block := randomBlock(t, 1, getPrevBlockHash(t, bc, 1))
+	block.ChainID = 7
+	assert.NotNil(t, bc.AddBlock(block))
+
+	tx := randomTxWithSignature(t)
+	tx.ChainID = 7
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	block = randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})
+	assert.NotNil(t, bc.AddBlock(block))
+
+	assert.Nil(t, bc.AddBlock(randomBlock(t, 1, getPrevBlockHash(t, bc, 1))))
+}
+
+// TestAddBlockUnsupportedVersion 测试拒绝不支持的区块头版本
+// TestAddBlockUnsupportedVersion tests rejecting unsupported header versions
+func TestAddBlockUnsupportedVersion(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	privateKey := crypto.GeneratePrivateKey()
+
+	block := randomBlock(t, 1, getPrevBlockHash(t, bc, 1))
+	block.Version = HeaderVersion + 1
+	assert.Nil(t, block.Sign(privateKey))
+	assert.NotNil(t, bc.AddBlock(block))
+}
+
+// TestAddBlockConcurrent 测试并发导入同一高度的竞争区块时只有一个成功
+// TestAddBlockConcurrent tests that only one of the competing blocks of the same height imported concurrently succeeds
+func TestAddBlockConcurrent(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	// 验证后暂停，让其他导入有机会插入 // Pause after validating so other imports get a chance to interleave
+	bc.SetValidator(slowValidator{Validator: NewBlockValidator(bc), delay: 10 * time.Millisecond})
+
+	blocks := make([]*Block, 4)
+	for i := range blocks {
+		privateKey := crypto.GeneratePrivateKey()
+		assert.Nil(t, bc.accountState.AddBalance(privateKey.PublicKey().Address(), 100))
+		tx := NewTransferTransaction(crypto.GeneratePrivateKey().PublicKey().Address(), 60)
+		assert.Nil(t, tx.Sign(privateKey))
+		blocks[i] = randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})
+	}
+
+	var (
+		wg    sync.WaitGroup
+		added atomic.Int32
+	)
+	for _, b := range blocks {
+		wg.Add(1)
+		go func(b *Block) {
+			defer wg.Done()
+			if bc.AddBlock(b) == nil {
+				added.Add(1)
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), added.Load())
+	assert.Equal(t, uint32(1), bc.Height())
+}
+
+// slowValidator 结构体在验证通过后暂停一段时间
+// slowValidator struct pauses for a while after a block validated
+type slowValidator struct {
+	Validator
+	delay time.Duration
+}
+
+// ValidateBlock 方法验证区块并在通过后暂停
+// ValidateBlock method validates the block and pauses once it passed
+func (v slowValidator) ValidateBlock(b *Block) error {
+	if err := v.Validator.ValidateBlock(b); err != nil {
+		return err
+	}
+	time.Sleep(v.delay)
+	return nil
+}
+
 // newBlockchainWithGenesis 创建带有创世区块的区块链
 // newBlockchainWithGenesis creates a blockchain with a genesis block
 func newBlockchainWithGenesis(t *testing.T) *Blockchain {
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/lonySp/go-blockchain/types"
+)
+
+// MaxBundleSize 是一个交易包最多包含的交易数量
+// MaxBundleSize is the maximum number of transactions in a bundle
+const MaxBundleSize = 16
+
+// BundleRef 结构体表示交易在原子交易包中的位置，属于签名数据
+// BundleRef struct represents the position of a transaction in an atomic bundle, it is part of the signed data
+type BundleRef struct {
+	ID    types.Hash // 交易包 ID，由所有成员交易派生 // Bundle ID, derived from all member transactions
+	Index uint32     // 交易在包中的索引 // Index of the transaction in the bundle
+	Size  uint32     // 包中交易的数量 // Number of transactions in the bundle
+}
+
+// Bundle 结构体表示一组必须在同一区块中全部执行或全部不执行的交易
+// Bundle struct represents a group of transactions that are executed all-or-nothing in one block
+type Bundle struct {
+	Transactions []*Transaction
+}
+
+// NewBundle 函数将未签名的交易组成交易包并设置它们的包引用，成员交易需要在之后签名
+// NewBundle function groups unsigned transactions into a bundle and sets their bundle references, the members have to be signed afterwards
+func NewBundle(txx []*Transaction) (*Bundle, error) {
+	if len(txx) == 0 || len(txx) > MaxBundleSize {
+		return nil, fmt.Errorf("bundle size %d out of range [1, %d]", len(txx), MaxBundleSize)
+	}
+	id := bundleID(txx)
+	for i, tx := range txx {
+		tx.Bundle = &BundleRef{ID: id, Index: uint32(i), Size: uint32(len(txx))}
+		tx.hash = types.Hash{}
+	}
+	return &Bundle{Transactions: txx}, nil
+}
+
+// ID 方法返回交易包的 ID
+// ID method returns the ID of the bundle
+func (b *Bundle) ID() types.Hash {
+	if len(b.Transactions) == 0 || b.Transactions[0].Bundle == nil {
+		return types.Hash{}
+	}
+	return b.Transactions[0].Bundle.ID
+}
+
+// Verify 方法验证交易包的结构和所有成员交易的签名
+// Verify method verifies the structure of the bundle and the signatures of all member transactions
+func (b *Bundle) Verify() error {
+	return b.VerifyCached(nil)
+}
+
+// VerifyCached 方法验证交易包，并使用签名缓存验证成员交易的签名
+// VerifyCached method verifies the bundle, checking the member signatures using the signature cache
+func (b *Bundle) VerifyCached(cache *SigCache) error {
+	if err := validateBundle(b.Transactions); err != nil {
+		return err
+	}
+	return verifyTransactions(b.Transactions, cache)
+}
+
+// Decode 方法从解码器中解码交易包
+// Decode method decodes the bundle from the decoder
+func (b *Bundle) Decode(dec Decoder[*Bundle]) error {
+	return dec.Decode(b)
+}
+
+// Encode 方法将交易包编码到编码器中
+// Encode method encodes the bundle into the encoder
+func (b *Bundle) Encode(enc Encoder[*Bundle]) error {
+	return enc.Encode(b)
+}
+
+// bundleID 函数根据成员交易除包引用外的规范编码计算交易包 ID，使成员无法被挪用到其他交易包中
+// bundleID function derives the bundle ID from the canonical encoding of the members without their bundle reference, so members cannot be moved into another bundle
+func bundleID(txx []*Transaction) types.Hash {
+	h := sha256.New()
+	for _, tx := range txx {
+		member := *tx
+		member.Bundle = nil
+		digest := member.SigningHash()
+		h.Write(digest[:])
+	}
+	return types.Hash(h.Sum(nil))
+}
+
+// validateBundle 函数检查一组交易是否按顺序组成一个完整的交易包
+// validateBundle function checks that the transactions form one complete bundle in order
+func validateBundle(txx []*Transaction) error {
+	if len(txx) == 0 || len(txx) > MaxBundleSize {
+		return fmt.Errorf("bundle size %d out of range [1, %d]", len(txx), MaxBundleSize)
+	}
+	for i, tx := range txx {
+		if tx == nil {
+			return fmt.Errorf("bundle is missing transaction %d", i)
+		}
+	}
+	first := txx[0].Bundle
+	if first == nil {
+		return fmt.Errorf("transaction (%s) is not part of a bundle", txx[0].Hash(TxHasher{}))
+	}
+	for i, tx := range txx {
+		ref := tx.Bundle
+		if ref == nil || ref.ID != first.ID || ref.Index != uint32(i) || ref.Size != uint32(len(txx)) {
+			return fmt.Errorf("transaction (%s) is out of place in bundle (%s)", tx.Hash(TxHasher{}), first.ID)
+		}
+	}
+	if id := bundleID(txx); id != first.ID {
+		return fmt.Errorf("bundle (%s) does not match its transactions => expected (%s)", first.ID, id)
+	}
+	return nil
+}
+
+// splitBlockTransactions 函数将区块的交易拆分为执行单元，普通交易单独成为一个单元，交易包必须连续且完整
+// splitBlockTransactions function splits the transactions of a block into execution units, plain transactions form a unit of their own and bundles must be contiguous and complete
+func splitBlockTransactions(txx []*Transaction) ([][]*Transaction, error) {
+	units := [][]*Transaction{}
+	for i := 0; i < len(txx); {
+		ref := txx[i].Bundle
+		if ref == nil {
+			units = append(units, txx[i:i+1])
+			i++
+			continue
+		}
+		end := i + int(ref.Size)
+		if ref.Index != 0 || ref.Size == 0 || end > len(txx) {
+			return nil, fmt.Errorf("bundle (%s) is incomplete", ref.ID)
+		}
+		if err := validateBundle(txx[i:end]); err != nil {
+			return nil, err
+		}
+		units = append(units, txx[i:end])
+		i = end
+	}
+	return units, nil
+}
+
+// groupTransactions 函数将无序的交易按交易包分组，普通交易单独成组，不完整的交易包被丢弃
+// groupTransactions function groups unordered transactions by bundle, plain transactions form a group of their own and incomplete bundles are dropped
+func groupTransactions(txx []*Transaction) [][]*Transaction {
+	units := [][]*Transaction{}
+	bundles := make(map[types.Hash][]*Transaction)
+	order := []types.Hash{}
+	for _, tx := range txx {
+		if tx.Bundle == nil {
+			units = append(units, []*Transaction{tx})
+			continue
+		}
+		members, ok := bundles[tx.Bundle.ID]
+		if !ok {
+			if tx.Bundle.Size == 0 || tx.Bundle.Size > MaxBundleSize {
+				continue
+			}
+			members = make([]*Transaction, tx.Bundle.Size)
+			bundles[tx.Bundle.ID] = members
+			order = append(order, tx.Bundle.ID)
+		}
+		if tx.Bundle.Size == uint32(len(members)) && tx.Bundle.Index < tx.Bundle.Size {
+			members[tx.Bundle.Index] = tx
+		}
+	}
+	for _, id := range order {
+		if validateBundle(bundles[id]) == nil {
+			units = append(units, bundles[id])
+		}
+	}
+	return units
+}
+
+// executeUnit 在同一个快照下执行一组交易，任意交易失败时全部回滚
+// executeUnit executes a group of transactions under a single snapshot and rolls all of them back when any fails
+func executeUnit(ctx *ExecutionContext, txx []*Transaction) error {
+	snapshot := ctx.Snapshot()
+	for _, tx := range txx {
+		if err := executeTransaction(ctx, tx); err != nil {
+			ctx.Restore(snapshot)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"bytes"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBundleEncodeDecode 测试交易包的编码和解码
+// TestBundleEncodeDecode tests encoding and decoding a bundle
+func TestBundleEncodeDecode(t *testing.T) {
+	privateKey := crypto.GeneratePrivateKey()
+	bundle := signedBundle(t, privateKey, NewTransferTransaction(types.Address{1}, 1), NewTransferTransaction(types.Address{2}, 2))
+	assert.Nil(t, bundle.Verify())
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, bundle.Encode(NewProtobufBundleEncoder(buf)))
+
+	bundleDecoded := new(Bundle)
+	assert.Nil(t, bundleDecoded.Decode(NewProtobufBundleDecoder(buf)))
+	assert.Nil(t, bundleDecoded.Verify())
+	assert.Equal(t, bundle.ID(), bundleDecoded.ID())
+	assert.Equal(t, *bundle.Transactions[1].Bundle, *bundleDecoded.Transactions[1].Bundle)
+}
+
+// TestBundleAtomicExecution 测试交易包在区块中全部执行或全部不执行
+// TestBundleAtomicExecution tests that a bundle is executed all-or-nothing in a block
+func TestBundleAtomicExecution(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	to := types.Address{1}
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	// 第二笔交易透支，整个交易包失败 // The second transaction overdraws, so the whole bundle fails
+	failing := signedBundle(t, privateKey, NewTransferTransaction(to, 60), NewTransferTransaction(to, 60))
+	assert.Empty(t, bc.ExecutableTransactions(from, failing.Transactions))
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), failing.Transactions)))
+	assert.Equal(t, uint64(100), bc.GetBalance(from))
+	assert.Equal(t, uint64(0), bc.GetNonce(from))
+
+	bundle := signedBundle(t, privateKey, NewTransferTransaction(to, 60), NewTransferTransaction(to, 40))
+	assert.Len(t, bc.ExecutableTransactions(from, bundle.Transactions), 2)
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), bundle.Transactions)))
+	assert.Equal(t, uint64(0), bc.GetBalance(from))
+	assert.Equal(t, uint64(100), bc.GetBalance(to))
+	assert.Equal(t, uint64(2), bc.GetNonce(from))
+}
+
+// TestBundleMustBeComplete 测试交易包不能被拆分或重新排序
+// TestBundleMustBeComplete tests that a bundle cannot be split or reordered
+func TestBundleMustBeComplete(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	privateKey := crypto.GeneratePrivateKey()
+	from := privateKey.PublicKey().Address()
+	assert.Nil(t, bc.accountState.AddBalance(from, 100))
+
+	bundle := signedBundle(t, privateKey, NewTransferTransaction(types.Address{1}, 1), NewTransferTransaction(types.Address{2}, 2))
+
+	// 不完整的交易包不会被打包 // Incomplete bundles are not packed
+	assert.Empty(t, bc.ExecutableTransactions(from, bundle.Transactions[:1]))
+
+	partial := randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), bundle.Transactions[:1])
+	assert.NotNil(t, bc.AddBlock(partial))
+
+	reordered := []*Transaction{bundle.Transactions[1], bundle.Transactions[0]}
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), reordered)))
+
+	// 修改交易包引用会使签名失效 // Changing the bundle reference invalidates the signature
+	tx := bundle.Transactions[0]
+	tx.Bundle = nil
+	tx.hash = types.Hash{}
+	assert.NotNil(t, tx.Verify())
+}
+
+// signedBundle 使用同一私钥按序号顺序签名交易包的成员交易
+// signedBundle signs the members of a bundle in nonce order with the same private key
+func signedBundle(t *testing.T, privateKey crypto.PrivateKey, txx ...*Transaction) *Bundle {
+	for i, tx := range txx {
+		tx.Nonce = uint64(i)
+	}
+	bundle, err := NewBundle(txx)
+	assert.Nil(t, err)
+	for _, tx := range txx {
+		assert.Nil(t, tx.Sign(privateKey))
+	}
+	return bundle
+}
@@ -1,10 +1,12 @@
 package core
 
 import (
+	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
 	"io"
+	"math"
 )
 
 // Encoder 接口定义了编码器
@@ -34,14 +36,7 @@ func NewProtobufTxEncoder(w io.Writer) *ProtobufTxEncoder {
 // Encode 方法将交易数据编码为字节流
 // Encode method encodes transaction data into a byte stream
 func (enc *ProtobufTxEncoder) Encode(tx *Transaction) error {
-	pbTx := &ProtoTransaction{
-		Data:      tx.Data,
-		From:      tx.From.ToSlice(),
-		Signature: tx.Signature.ToBytes(),
-		Hash:      tx.hash.ToSlice(),
-		FirstSeen: tx.firstSeen,
-	}
-	data, err := proto.Marshal(pbTx)
+	data, err := proto.Marshal(txToProto(tx))
 	if err != nil {
 		return err
 	}
@@ -72,11 +67,66 @@ func (dec *ProtobufTxDecoder) Decode(tx *Transaction) error {
 	if err := proto.Unmarshal(data, pbTx); err != nil {
 		return err
 	}
-	tx.Data = pbTx.Data
-	tx.From = crypto.PublicKeyFromBytes(pbTx.From)
-	tx.Signature = crypto.SignatureFromBytes(pbTx.Signature)
-	tx.hash = types.BytesToHash(pbTx.Hash)
-	tx.firstSeen = pbTx.FirstSeen
+	return txFromProto(pbTx, tx)
+}
+
+// ProtobufBundleEncoder 结构体用于基于 Protobuf 的交易包编码
+// ProtobufBundleEncoder struct is used for Protobuf-based bundle encoding
+type ProtobufBundleEncoder struct {
+	w io.Writer // 用于写入编码数据的 io.Writer
+}
+
+// NewProtobufBundleEncoder 函数创建一个新的 ProtobufBundleEncoder 实例
+// NewProtobufBundleEncoder function creates a new instance of ProtobufBundleEncoder
+func NewProtobufBundleEncoder(w io.Writer) *ProtobufBundleEncoder {
+	return &ProtobufBundleEncoder{w: w}
+}
+
+// Encode 方法将交易包编码为字节流
+// Encode method encodes a bundle into a byte stream
+func (enc *ProtobufBundleEncoder) Encode(b *Bundle) error {
+	pbBundle := &ProtoBundle{Transactions: make([]*ProtoTransaction, len(b.Transactions))}
+	for i, tx := range b.Transactions {
+		pbBundle.Transactions[i] = txToProto(tx)
+	}
+	data, err := proto.Marshal(pbBundle)
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.Write(data)
+	return err
+}
+
+// ProtobufBundleDecoder 结构体用于基于 Protobuf 的交易包解码
+// ProtobufBundleDecoder struct is used for Protobuf-based bundle decoding
+type ProtobufBundleDecoder struct {
+	r io.Reader // 用于读取编码数据的 io.Reader
+}
+
+// NewProtobufBundleDecoder 函数创建一个新的 ProtobufBundleDecoder 实例
+// NewProtobufBundleDecoder function creates a new instance of ProtobufBundleDecoder
+func NewProtobufBundleDecoder(r io.Reader) *ProtobufBundleDecoder {
+	return &ProtobufBundleDecoder{r: r}
+}
+
+// Decode 方法从字节流解码交易包
+// Decode method decodes a bundle from a byte stream
+func (dec *ProtobufBundleDecoder) Decode(b *Bundle) error {
+	data, err := io.ReadAll(dec.r)
+	if err != nil {
+		return err
+	}
+	pbBundle := &ProtoBundle{}
+	if err := proto.Unmarshal(data, pbBundle); err != nil {
+		return err
+	}
+	b.Transactions = make([]*Transaction, len(pbBundle.Transactions))
+	for i, pbTx := range pbBundle.Transactions {
+		b.Transactions[i] = new(Transaction)
+		if err := txFromProto(pbTx, b.Transactions[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -102,20 +152,14 @@ func (enc *ProtobufBlockEncoder) Encode(b *Block) error {
 			PrevBlockHash: b.PrevBlockHash.ToSlice(),
 			Timestamp:     b.Timestamp,
 			Height:        b.Height,
+			ChainID:       b.ChainID,
 		},
 		Validator:    b.Validator.ToSlice(),
 		Signature:    b.Signature.ToBytes(),
-		Hash:         b.hash.ToSlice(),
 		Transactions: make([]*ProtoTransaction, len(b.Transactions)),
 	}
 	for i, tx := range b.Transactions {
-		pbBlock.Transactions[i] = &ProtoTransaction{
-			Data:      tx.Data,
-			From:      tx.From.ToSlice(),
-			Signature: tx.Signature.ToBytes(),
-			Hash:      tx.hash.ToSlice(),
-			FirstSeen: tx.firstSeen,
-		}
+		pbBlock.Transactions[i] = txToProto(tx)
 	}
 	data, err := proto.Marshal(pbBlock)
 	if err != nil {
@@ -148,25 +192,168 @@ func (dec *ProtobufBlockDecoder) Decode(b *Block) error {
 	if err := proto.Unmarshal(data, pbBlock); err != nil {
 		return err
 	}
+	// 对方可以发送没有区块头的区块 // A peer can send a block without a header
+	pbHeader := pbBlock.GetHeader()
+	if pbHeader == nil {
+		return fmt.Errorf("block has no header")
+	}
 	b.Header = &Header{
-		Version:       pbBlock.Header.Version,
-		DataHash:      types.BytesToHash(pbBlock.Header.DataHash),
-		PrevBlockHash: types.BytesToHash(pbBlock.Header.PrevBlockHash),
-		Timestamp:     pbBlock.Header.Timestamp,
-		Height:        pbBlock.Header.Height,
-	}
-	b.Validator = crypto.PublicKeyFromBytes(pbBlock.Validator)
-	b.Signature = crypto.SignatureFromBytes(pbBlock.Signature)
-	b.hash = types.BytesToHash(pbBlock.Hash)
+		Version:       pbHeader.Version,
+		DataHash:      types.BytesToHash(pbHeader.DataHash),
+		PrevBlockHash: types.BytesToHash(pbHeader.PrevBlockHash),
+		Timestamp:     pbHeader.Timestamp,
+		Height:        pbHeader.Height,
+		ChainID:       pbHeader.ChainID,
+	}
+	validator, err := crypto.PublicKeyFromBytes(pbBlock.Validator)
+	if err != nil {
+		return fmt.Errorf("invalid block validator: %w", err)
+	}
+	b.Validator = validator
+	sig, err := crypto.SignatureFromBytes(pbBlock.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid block signature: %w", err)
+	}
+	b.Signature = sig
+	// 重新计算哈希，不信任对方发送的哈希 // Recompute the hash instead of trusting the one sent by the peer
+	b.hash = BlockHasher{}.Hash(b.Header)
 	b.Transactions = make([]*Transaction, len(pbBlock.Transactions))
 	for i, pbTx := range pbBlock.Transactions {
-		b.Transactions[i] = &Transaction{
-			Data:      pbTx.Data,
-			From:      crypto.PublicKeyFromBytes(pbTx.From),
-			Signature: crypto.SignatureFromBytes(pbTx.Signature),
-			hash:      types.BytesToHash(pbTx.Hash),
-			firstSeen: pbTx.FirstSeen,
+		b.Transactions[i] = new(Transaction)
+		if err := txFromProto(pbTx, b.Transactions[i]); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// txToProto 将交易转换为 Protobuf 消息
+// txToProto converts a transaction into its Protobuf message
+func txToProto(tx *Transaction) *ProtoTransaction {
+	pbTx := &ProtoTransaction{
+		Header: &ProtoTxHeader{
+			Version: tx.Version,
+			Type:    uint32(tx.Type),
+		},
+		Data:       tx.Data,
+		To:         tx.To.ToSlice(),
+		Value:      tx.Value,
+		Fee:        tx.Fee,
+		ValidAfter: tx.ValidAfter,
+		ValidUntil: tx.ValidUntil,
+		Nonce:      tx.Nonce,
+		ChainID:    tx.ChainID,
+		FirstSeen:  tx.firstSeen,
+	}
+	if tx.Signature != nil {
+		pbTx.Signature = tx.Signature.ToBytes()
+	}
+	// 可恢复签名的发送方公钥不需要编码 // The sender's public key need not be encoded with a recoverable signature
+	if tx.Signature == nil || !tx.Signature.Recoverable() {
+		pbTx.From = tx.From.ToSlice()
+	}
+	if tx.FromAddress != nil {
+		pbTx.FromAddress = tx.FromAddress.ToSlice()
+	}
+	if tx.Multisig != nil {
+		pbTx.Multisig = &ProtoMultisigAccount{Threshold: tx.Multisig.Threshold}
+		for _, key := range tx.Multisig.PublicKeys {
+			pbTx.Multisig.PublicKeys = append(pbTx.Multisig.PublicKeys, key.ToSlice())
+		}
+	}
+	for _, sig := range tx.Signatures {
+		pbTx.Signatures = append(pbTx.Signatures, &ProtoMultiSignature{
+			Index:     sig.Index,
+			Signature: sig.Signature.ToBytes(),
+		})
+	}
+	if tx.Bundle != nil {
+		pbTx.Bundle = &ProtoBundleRef{
+			Id:    tx.Bundle.ID.ToSlice(),
+			Index: tx.Bundle.Index,
+			Size:  tx.Bundle.Size,
+		}
+	}
+	return pbTx
+}
+
+// txFromProto 将 Protobuf 消息填充到交易中
+// txFromProto fills the transaction from its Protobuf message
+func txFromProto(pbTx *ProtoTransaction, tx *Transaction) error {
+	if len(pbTx.To) != len(tx.To) {
+		return fmt.Errorf("invalid transaction recipient length %d", len(pbTx.To))
+	}
+	if pbTx.GetHeader().GetType() > math.MaxUint8 {
+		return fmt.Errorf("invalid transaction type %d", pbTx.GetHeader().GetType())
+	}
+	tx.Version = pbTx.GetHeader().GetVersion()
+	tx.Type = TxType(pbTx.GetHeader().GetType())
+	tx.Data = pbTx.Data
+	tx.To = types.NewAddressFromBytes(pbTx.To)
+	tx.Value = pbTx.Value
+	tx.Fee = pbTx.Fee
+	tx.ValidAfter = pbTx.ValidAfter
+	tx.ValidUntil = pbTx.ValidUntil
+	tx.Nonce = pbTx.Nonce
+	tx.ChainID = pbTx.ChainID
+	from, err := crypto.PublicKeyFromBytes(pbTx.From)
+	if err != nil {
+		return fmt.Errorf("invalid transaction sender: %w", err)
+	}
+	tx.From = from
+	if len(pbTx.Signature) > 0 {
+		sig, err := crypto.SignatureFromBytes(pbTx.Signature)
+		if err != nil {
+			return fmt.Errorf("invalid transaction signature: %w", err)
+		}
+		tx.Signature = sig
+	}
+	if pbTx.Multisig != nil {
+		keys := make([]crypto.PublicKey, len(pbTx.Multisig.PublicKeys))
+		for i, key := range pbTx.Multisig.PublicKeys {
+			publicKey, err := crypto.PublicKeyFromBytes(key)
+			if err != nil {
+				return fmt.Errorf("invalid multisig key %d: %w", i, err)
+			}
+			keys[i] = publicKey
+		}
+		tx.Multisig = &crypto.MultisigAccount{Threshold: pbTx.Multisig.Threshold, PublicKeys: keys}
+	}
+	for _, pbSig := range pbTx.Signatures {
+		sig, err := crypto.SignatureFromBytes(pbSig.Signature)
+		if err != nil {
+			return fmt.Errorf("invalid multisig signature %d: %w", pbSig.Index, err)
+		}
+		tx.Signatures = append(tx.Signatures, crypto.MultiSignature{
+			Index:     pbSig.Index,
+			Signature: sig,
+		})
+	}
+	if pbTx.Bundle != nil {
+		if len(pbTx.Bundle.Id) != len(types.Hash{}) {
+			return fmt.Errorf("invalid bundle id length %d", len(pbTx.Bundle.Id))
+		}
+		tx.Bundle = &BundleRef{
+			ID:    types.BytesToHash(pbTx.Bundle.Id),
+			Index: pbTx.Bundle.Index,
+			Size:  pbTx.Bundle.Size,
+		}
+	}
+	if len(pbTx.FromAddress) > 0 {
+		if len(pbTx.FromAddress) != len(types.Address{}) {
+			return fmt.Errorf("invalid transaction sender address length %d", len(pbTx.FromAddress))
+		}
+		address := types.NewAddressFromBytes(pbTx.FromAddress)
+		tx.FromAddress = &address
+	}
+	// 没有发送方公钥时从可恢复签名恢复 // Recover the sender's public key from a recoverable signature when it is missing
+	if tx.From.IsZero() && tx.Signature != nil && tx.Signature.Recoverable() {
+		if err := tx.recoverSender(); err != nil {
+			return fmt.Errorf("invalid transaction signature: %w", err)
+		}
+	}
+	// 重新计算哈希，不信任对方发送的哈希 // Recompute the hash instead of trusting the one sent by the peer
+	tx.hash = TxHasher{}.Hash(tx)
+	tx.firstSeen = pbTx.FirstSeen
+	return nil
+}
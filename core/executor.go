@@ -0,0 +1,339 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+)
+
+// TxVersion 是当前的交易信封版本号
+// TxVersion is the current transaction envelope version
+const TxVersion uint32 = 1
+
+// TxType 表示交易类型，决定交易数据的格式、校验规则和执行方式
+// TxType represents the transaction type, which decides the data format, validation rules and execution of a transaction
+type TxType byte
+
+const (
+	TxTypeTransfer        TxType = 0x0 // 原生转账 // Native value transfer
+	TxTypeDeploy          TxType = 0x1 // 部署合约代码 // Deploy contract code
+	TxTypeCall            TxType = 0x2 // 调用已部署的合约 // Call a deployed contract
+	TxTypeValidatorUpdate TxType = 0x3 // 更新验证者集合 // Update the validator set
+)
+
+// String 方法返回交易类型的名称
+// String method returns the name of the transaction type
+func (t TxType) String() string {
+	switch t {
+	case TxTypeTransfer:
+		return "transfer"
+	case TxTypeDeploy:
+		return "deploy"
+	case TxTypeCall:
+		return "call"
+	case TxTypeValidatorUpdate:
+		return "validator-update"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(t))
+	}
+}
+
+// TxExecutor 接口定义了某一类交易的校验和执行
+// TxExecutor interface defines the validation and execution of one type of transaction
+type TxExecutor interface {
+	// ValidatePayload 校验不依赖状态的交易数据格式
+	// ValidatePayload validates the stateless format of the transaction data
+	ValidatePayload(tx *Transaction) error
+	// Execute 在执行上下文中执行交易
+	// Execute executes the transaction in the execution context
+	Execute(ctx *ExecutionContext, tx *Transaction) error
+}
+
+// txExecutors 是交易类型到执行器的映射
+// txExecutors maps the transaction types to their executors
+var txExecutors = map[TxType]TxExecutor{
+	TxTypeTransfer:        transferExecutor{},
+	TxTypeDeploy:          deployExecutor{},
+	TxTypeCall:            callExecutor{},
+	TxTypeValidatorUpdate: validatorUpdateExecutor{},
+}
+
+// ExecutionContext 结构体表示执行区块交易时的状态和环境
+// ExecutionContext struct represents the state and environment used to execute the transactions of a block
+type ExecutionContext struct {
+	Accounts   *AccountState // 账户状态 // Account state
+	Contracts  *State        // 合约状态 // Contract state
+	Validators *ValidatorSet // 验证者集合 // Validator set
+	Proposer   types.Address // 区块验证者地址，收取手续费 // Address of the block validator receiving the fees
+	Height     uint32        // 区块高度 // Block height
+}
+
+// Snapshot 返回执行上下文的深拷贝，可用于回滚
+// Snapshot returns a deep copy of the execution context that can be used to roll back
+func (ctx *ExecutionContext) Snapshot() *ExecutionContext {
+	return &ExecutionContext{
+		Accounts:   ctx.Accounts.Copy(),
+		Contracts:  ctx.Contracts.Copy(),
+		Validators: ctx.Validators.Copy(),
+		Proposer:   ctx.Proposer,
+		Height:     ctx.Height,
+	}
+}
+
+// Restore 将执行上下文回滚到快照
+// Restore rolls the execution context back to the snapshot
+func (ctx *ExecutionContext) Restore(snapshot *ExecutionContext) {
+	ctx.Accounts = snapshot.Accounts
+	ctx.Contracts = snapshot.Contracts
+	ctx.Validators = snapshot.Validators
+}
+
+// ValidatePayload 方法校验交易的信封版本和对应类型的数据格式
+// ValidatePayload method validates the envelope version and the type specific data format of the transaction
+func (tx *Transaction) ValidatePayload() error {
+	if tx.Version != TxVersion {
+		return fmt.Errorf("transaction (%s) has unsupported version %d", tx.Hash(TxHasher{}), tx.Version)
+	}
+	executor, ok := txExecutors[tx.Type]
+	if !ok {
+		return fmt.Errorf("transaction (%s) has unknown type %s", tx.Hash(TxHasher{}), tx.Type)
+	}
+	if tx.ValidUntil != 0 && tx.ValidUntil < tx.ValidAfter {
+		return fmt.Errorf("transaction (%s) has empty validity window [%d, %d]", tx.Hash(TxHasher{}), tx.ValidAfter, tx.ValidUntil)
+	}
+	if err := executor.ValidatePayload(tx); err != nil {
+		return fmt.Errorf("invalid %s transaction (%s): %w", tx.Type, tx.Hash(TxHasher{}), err)
+	}
+	return nil
+}
+
+// executeTransaction 校验有效高度和序号并收取手续费，然后按交易类型分发给对应的执行器
+// executeTransaction checks the validity window and the nonce and charges the fee, then dispatches the transaction to the executor of its type
+func executeTransaction(ctx *ExecutionContext, tx *Transaction) error {
+	if err := tx.ValidatePayload(); err != nil {
+		return err
+	}
+
+	if err := tx.ValidAt(ctx.Height); err != nil {
+		return fmt.Errorf("transaction (%s) rejected: %w", tx.Hash(TxHasher{}), err)
+	}
+
+	from := tx.Sender()
+	if nonce := ctx.Accounts.GetNonce(from); tx.Nonce != nonce {
+		return fmt.Errorf("transaction (%s) rejected: %w (%s): have %d, want %d", tx.Hash(TxHasher{}), ErrInvalidNonce, from, tx.Nonce, nonce)
+	}
+	cost, err := tx.Cost()
+	if err != nil {
+		return fmt.Errorf("transaction (%s) rejected: %w", tx.Hash(TxHasher{}), err)
+	}
+	if balance := ctx.Accounts.GetBalance(from); balance < cost {
+		return fmt.Errorf("transaction (%s) rejected: %w (%s): have %d, want %d", tx.Hash(TxHasher{}), ErrInsufficientBalance, from, balance, cost)
+	}
+
+	if err := txExecutors[tx.Type].Execute(ctx, tx); err != nil {
+		return fmt.Errorf("%s transaction (%s) failed: %w", tx.Type, tx.Hash(TxHasher{}), err)
+	}
+	if tx.Fee > 0 {
+		if err := ctx.Accounts.Transfer(from, ctx.Proposer, tx.Fee); err != nil {
+			return fmt.Errorf("transaction (%s) fee payment failed: %w", tx.Hash(TxHasher{}), err)
+		}
+	}
+	return ctx.Accounts.UseNonce(from, tx.Nonce)
+}
+
+// ContractAddress 函数根据部署者地址和部署交易的序号计算合约地址
+// ContractAddress function derives the contract address from the deployer address and the nonce of the deploy transaction
+func ContractAddress(deployer types.Address, nonce uint64) types.Address {
+	buf := binary.BigEndian.AppendUint64(deployer.ToSlice(), nonce)
+	h := sha256.Sum256(buf)
+	return types.NewAddressFromBytes(h[len(h)-20:])
+}
+
+// runCode 在合约状态上运行代码，并将虚拟机的 panic 转换为错误
+// runCode runs the code on the contract state and turns a panic of the VM into an error
+func runCode(code []byte, contracts *State) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("vm execution failed: %v", r)
+		}
+	}()
+	return NewVM(code, contracts).Run()
+}
+
+// transferExecutor 执行原生转账交易，交易数据必须为空
+// transferExecutor executes native value transfers, the transaction data must be empty
+type transferExecutor struct{}
+
+// ValidatePayload 校验转账交易的数据格式
+// ValidatePayload validates the data format of a transfer transaction
+func (transferExecutor) ValidatePayload(tx *Transaction) error {
+	if len(tx.Data) > 0 {
+		return fmt.Errorf("transfer must not carry data")
+	}
+	if tx.To == (types.Address{}) {
+		return fmt.Errorf("transfer has no recipient")
+	}
+	return nil
+}
+
+// Execute 执行转账交易
+// Execute executes a transfer transaction
+func (transferExecutor) Execute(ctx *ExecutionContext, tx *Transaction) error {
+	if tx.Value == 0 {
+		return nil
+	}
+	return ctx.Accounts.Transfer(tx.Sender(), tx.To, tx.Value)
+}
+
+// deployExecutor 执行部署交易，交易数据为合约代码，代码在部署时运行一次
+// deployExecutor executes deploy transactions, the transaction data is the contract code which runs once on deployment
+type deployExecutor struct{}
+
+// ValidatePayload 校验部署交易的数据格式
+// ValidatePayload validates the data format of a deploy transaction
+func (deployExecutor) ValidatePayload(tx *Transaction) error {
+	if len(tx.Data) == 0 {
+		return fmt.Errorf("deploy has no code")
+	}
+	if tx.To != (types.Address{}) {
+		return fmt.Errorf("deploy must not have a recipient")
+	}
+	return nil
+}
+
+// Execute 执行部署交易
+// Execute executes a deploy transaction
+func (deployExecutor) Execute(ctx *ExecutionContext, tx *Transaction) error {
+	from := tx.Sender()
+	contract := ContractAddress(from, tx.Nonce)
+	if err := ctx.Accounts.SetCode(contract, tx.Data); err != nil {
+		return err
+	}
+	if tx.Value > 0 {
+		if err := ctx.Accounts.Transfer(from, contract, tx.Value); err != nil {
+			return err
+		}
+	}
+	return runCode(tx.Data, ctx.Contracts)
+}
+
+// callExecutor 执行合约调用交易，运行接收方地址上已部署的代码，交易数据必须为空
+// callExecutor executes contract calls by running the code deployed at the recipient, the transaction data must be empty
+type callExecutor struct{}
+
+// ValidatePayload 校验合约调用交易的数据格式
+// ValidatePayload validates the data format of a call transaction
+func (callExecutor) ValidatePayload(tx *Transaction) error {
+	if len(tx.Data) > 0 {
+		return fmt.Errorf("call must not carry data")
+	}
+	if tx.To == (types.Address{}) {
+		return fmt.Errorf("call has no contract")
+	}
+	return nil
+}
+
+// Execute 执行合约调用交易
+// Execute executes a call transaction
+func (callExecutor) Execute(ctx *ExecutionContext, tx *Transaction) error {
+	code := ctx.Accounts.GetCode(tx.To)
+	if len(code) == 0 {
+		return fmt.Errorf("no contract deployed at (%s)", tx.To)
+	}
+	if tx.Value > 0 {
+		if err := ctx.Accounts.Transfer(tx.Sender(), tx.To, tx.Value); err != nil {
+			return err
+		}
+	}
+	return runCode(code, ctx.Contracts)
+}
+
+// ValidatorUpdate 结构体是验证者更新交易的数据
+// ValidatorUpdate struct is the payload of a validator update transaction
+type ValidatorUpdate struct {
+	PublicKey crypto.PublicKey // 被加入或移除的验证者公钥 // Public key of the validator being added or removed
+	Remove    bool             // 是否移除该验证者 // Whether the validator is removed
+}
+
+// Bytes 方法将验证者更新编码为 Protobuf 字节
+// Bytes method encodes the validator update into Protobuf bytes
+func (u *ValidatorUpdate) Bytes() ([]byte, error) {
+	return proto.Marshal(&ProtoValidatorUpdate{
+		PublicKey: u.PublicKey.ToSlice(),
+		Remove:    u.Remove,
+	})
+}
+
+// ValidatorUpdateFromBytes 函数从 Protobuf 字节解码验证者更新
+// ValidatorUpdateFromBytes function decodes a validator update from Protobuf bytes
+func ValidatorUpdateFromBytes(data []byte) (*ValidatorUpdate, error) {
+	pbUpdate := &ProtoValidatorUpdate{}
+	if err := proto.Unmarshal(data, pbUpdate); err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.PublicKeyFromBytes(pbUpdate.PublicKey)
+	if err != nil || publicKey.IsZero() {
+		return nil, fmt.Errorf("invalid validator public key")
+	}
+	return &ValidatorUpdate{
+		PublicKey: publicKey,
+		Remove:    pbUpdate.Remove,
+	}, nil
+}
+
+// validatorUpdateExecutor 执行验证者更新交易，加入或移除验证者需要现任验证者过半数的批准，单签交易的发送方算一票，多签交易中每个签名的现任验证者成员各算一票，因此验证者超过一个时需要通过多签账户共同发送
+// validatorUpdateExecutor executes validator updates, adding or removing a validator needs the approval of a majority of the current validators, the sender of a single signed transaction counts once and every current validator among the signing members of a multisig transaction counts once, so with more than one validator the update has to be sent through a multisig account
+type validatorUpdateExecutor struct{}
+
+// ValidatePayload 校验验证者更新交易的数据格式
+// ValidatePayload validates the data format of a validator update transaction
+func (validatorUpdateExecutor) ValidatePayload(tx *Transaction) error {
+	if tx.To != (types.Address{}) || tx.Value != 0 {
+		return fmt.Errorf("validator update must not transfer value")
+	}
+	_, err := ValidatorUpdateFromBytes(tx.Data)
+	return err
+}
+
+// Execute 执行验证者更新交易
+// Execute executes a validator update transaction
+func (validatorUpdateExecutor) Execute(ctx *ExecutionContext, tx *Transaction) error {
+	approvals, quorum := validatorApprovals(ctx.Validators, tx), ctx.Validators.Len()/2+1
+	if approvals == 0 {
+		return fmt.Errorf("sender (%s) is not a validator", tx.Sender())
+	}
+	if approvals < quorum {
+		return fmt.Errorf("validator update approved by %d validators => expected at least %d", approvals, quorum)
+	}
+	update, err := ValidatorUpdateFromBytes(tx.Data)
+	if err != nil {
+		return err
+	}
+	if update.Remove {
+		return ctx.Validators.Remove(update.PublicKey.Address())
+	}
+	ctx.Validators.Add(update.PublicKey)
+	return nil
+}
+
+// validatorApprovals 函数返回批准交易的现任验证者数量，多签交易按签名的成员计算
+// validatorApprovals function returns the number of current validators approving the transaction, multisig transactions count their signing members
+func validatorApprovals(validators *ValidatorSet, tx *Transaction) int {
+	if tx.Multisig == nil {
+		if validators.Contains(tx.Sender()) {
+			return 1
+		}
+		return 0
+	}
+	// 签名已在验证交易时检查过，索引严格递增 // The signatures were checked when verifying the transaction, their indexes are strictly increasing
+	approvals := 0
+	for _, sig := range tx.Signatures {
+		if int(sig.Index) < len(tx.Multisig.PublicKeys) && validators.Contains(tx.Multisig.PublicKeys[sig.Index].Address()) {
+			approvals++
+		}
+	}
+	return approvals
+}
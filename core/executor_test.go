@@ -0,0 +1,152 @@
+package core
+
+import (
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeployAndCallContract 测试部署合约并调用
+// TestDeployAndCallContract tests deploying a contract and calling it
+func TestDeployAndCallContract(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	privateKey := crypto.GeneratePrivateKey()
+	code := []byte{0x03, 0x0a, 0x46, 0x0c, 0x4f, 0x0c, 0x4f, 0x0c, 0x0d, 0x05, 0x0a, 0x0f}
+
+	deploy := NewDeployTransaction(code)
+	assert.Nil(t, deploy.Sign(privateKey))
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 1, getPrevBlockHash(t, bc, 1), []*Transaction{deploy})))
+
+	contract := ContractAddress(privateKey.PublicKey().Address(), 0)
+	assert.Equal(t, code, bc.GetCode(contract))
+	value, err := bc.contractState.Get([]byte("FOO"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), deserializeInt64(value))
+
+	call := NewCallTransaction(contract)
+	call.Nonce = 1
+	assert.Nil(t, call.Sign(privateKey))
+	assert.Nil(t, bc.AddBlock(randomBlockWithTransactions(t, 2, getPrevBlockHash(t, bc, 2), []*Transaction{call})))
+
+	// 调用不存在的合约会被拒绝 // Calling a missing contract is rejected
+	call = NewCallTransaction(types.Address{1})
+	call.Nonce = 2
+	assert.Nil(t, call.Sign(privateKey))
+	assert.NotNil(t, bc.AddBlock(randomBlockWithTransactions(t, 3, getPrevBlockHash(t, bc, 3), []*Transaction{call})))
+}
+
+// TestValidatePayload 测试各交易类型的数据格式校验
+// TestValidatePayload tests the data format validation of every transaction type
+func TestValidatePayload(t *testing.T) {
+	update, err := NewValidatorUpdateTransaction(&ValidatorUpdate{PublicKey: crypto.GeneratePrivateKey().PublicKey()})
+	assert.Nil(t, err)
+
+	valid := []*Transaction{
+		NewTransferTransaction(types.Address{1}, 1),
+		NewDeployTransaction([]byte{0x01}),
+		NewCallTransaction(types.Address{1}),
+		update,
+	}
+	for _, tx := range valid {
+		assert.Nil(t, tx.ValidatePayload(), tx.Type.String())
+	}
+
+	invalid := []*Transaction{
+		NewTransferTransaction(types.Address{}, 1),
+		NewTransaction(TxTypeTransfer, []byte{0x01}),
+		NewDeployTransaction(nil),
+		NewCallTransaction(types.Address{}),
+		NewTransaction(TxTypeValidatorUpdate, []byte{0x01}),
+		NewTransaction(TxType(0xff), nil),
+		{Type: TxTypeDeploy, Data: []byte{0x01}},
+	}
+	for _, tx := range invalid {
+		assert.NotNil(t, tx.ValidatePayload(), tx.Type.String())
+	}
+}
+
+// TestValidatorUpdate 测试只有验证者可以更新验证者集合，且只有验证者可以出块
+// TestValidatorUpdate tests that only validators can update the validator set and propose blocks
+func TestValidatorUpdate(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	validatorKey := crypto.GeneratePrivateKey()
+	bc.validatorSet.Add(validatorKey.PublicKey())
+
+	newValidator := crypto.GeneratePrivateKey().PublicKey()
+	update := &ValidatorUpdate{PublicKey: newValidator}
+
+	// 非验证者提出的区块会被拒绝 // Blocks proposed by non validators are rejected
+	assert.NotNil(t, bc.AddBlock(randomBlock(t, 1, getPrevBlockHash(t, bc, 1))))
+
+	// 非验证者不能更新验证者集合 // Non validators cannot update the validator set
+	tx, err := NewValidatorUpdateTransaction(update)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	assert.NotNil(t, bc.AddBlock(signedBlock(t, validatorKey, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+
+	tx, err = NewValidatorUpdateTransaction(update)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Sign(validatorKey))
+	assert.Nil(t, bc.AddBlock(signedBlock(t, validatorKey, 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx})))
+	assert.True(t, bc.IsValidator(newValidator.Address()))
+	assert.Equal(t, 2, bc.ValidatorCount())
+}
+
+// TestValidatorUpdateQuorum 测试单个验证者不能移除其他验证者，更新需要现任验证者过半数在多签交易中批准
+// TestValidatorUpdateQuorum tests that a lone validator cannot remove the others and that updates need a majority of the current validators approving in a multisig transaction
+func TestValidatorUpdateQuorum(t *testing.T) {
+	bc := newBlockchainWithGenesis(t)
+	keys := []crypto.PrivateKey{crypto.GeneratePrivateKey(), crypto.GeneratePrivateKey(), crypto.GeneratePrivateKey()}
+	for _, key := range keys {
+		bc.validatorSet.Add(key.PublicKey())
+	}
+	remove := &ValidatorUpdate{PublicKey: keys[1].PublicKey(), Remove: true}
+	propose := func(tx *Transaction) error {
+		return bc.AddBlock(signedBlock(t, keys[0], 1, getPrevBlockHash(t, bc, 1), []*Transaction{tx}))
+	}
+
+	// 单签的验证者不能移除其他验证者 // A single validator signing alone cannot remove another validator
+	tx, err := NewValidatorUpdateTransaction(remove)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Sign(keys[0]))
+	assert.NotNil(t, propose(tx))
+
+	// 门限低的多签账户只有一个验证者签名时同样不够 // A low threshold multisig account signed by one validator is not enough either
+	all := []crypto.PublicKey{keys[0].PublicKey(), keys[1].PublicKey(), keys[2].PublicKey()}
+	account, err := crypto.NewMultisigAccount(1, all)
+	assert.Nil(t, err)
+	tx, err = NewValidatorUpdateTransaction(remove)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SignMultisig(keys[0], account))
+	assert.NotNil(t, propose(tx))
+
+	// 非验证者成员的签名不计入批准 // Signatures of members that are not validators do not count
+	outsider := crypto.GeneratePrivateKey()
+	mixed, err := crypto.NewMultisigAccount(2, []crypto.PublicKey{keys[0].PublicKey(), outsider.PublicKey()})
+	assert.Nil(t, err)
+	tx, err = NewValidatorUpdateTransaction(remove)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SignMultisig(keys[0], mixed))
+	assert.Nil(t, tx.SignMultisig(outsider, mixed))
+	assert.NotNil(t, propose(tx))
+	assert.Equal(t, 3, bc.ValidatorCount())
+
+	// 过半数的验证者批准后移除成功 // The removal succeeds once a majority of the validators approved
+	tx, err = NewValidatorUpdateTransaction(remove)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SignMultisig(keys[0], account))
+	assert.Nil(t, tx.SignMultisig(keys[2], account))
+	assert.Nil(t, propose(tx))
+	assert.False(t, bc.IsValidator(keys[1].PublicKey().Address()))
+	assert.Equal(t, 2, bc.ValidatorCount())
+}
+
+// signedBlock 创建一个由指定私钥签名的区块
+// signedBlock creates a block signed by the given private key
+func signedBlock(t *testing.T, privateKey crypto.PrivateKey, height uint32, prevBlockHash types.Hash, txx []*Transaction) *Block {
+	b := randomBlockWithTransactions(t, height, prevBlockHash, txx)
+	assert.Nil(t, b.Sign(privateKey))
+	return b
+}
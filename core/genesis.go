@@ -0,0 +1,291 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// genesisDomain 是创世配置规范编码的域前缀
+// genesisDomain is the domain prefix of the canonical genesis encoding
+const genesisDomain = "go-blockchain/genesis/v1"
+
+// Genesis 结构体表示创世配置，决定链 ID、初始状态、初始验证者集合和共识参数
+// Genesis struct represents the genesis configuration deciding the chain ID, the initial state, the initial validator set and the consensus parameters
+type Genesis struct {
+	ChainID    uint32                           // 链 ID // Chain ID
+	Timestamp  uint64                           // 创世区块的时间戳 // Timestamp of the genesis block
+	Alloc      map[types.Address]GenesisAccount // 初始账户 // Initial accounts
+	Storage    map[string][]byte                // 初始合约状态 // Initial contract state
+	Validators []crypto.PublicKey               // 初始验证者集合，为空时不限制出块 // Initial validator set, block proposing is unrestricted when empty
+	Consensus  ConsensusParams                  // 共识参数 // Consensus parameters
+}
+
+// GenesisAccount 结构体表示创世时分配的账户
+// GenesisAccount struct represents an account allocated at genesis
+type GenesisAccount struct {
+	Balance uint64 // 初始余额 // Initial balance
+	Code    []byte // 预部署的合约代码 // Pre-deployed contract code
+}
+
+// ConsensusParams 结构体表示共识参数
+// ConsensusParams struct represents the consensus parameters
+type ConsensusParams struct {
+	BlockTime   time.Duration // 出块时间间隔 // Block time
+	BlockReward uint64        // 每个区块铸造给验证者的奖励 // Reward minted to the validator of every block
+}
+
+// DefaultGenesis 函数返回指定链 ID 的空创世配置
+// DefaultGenesis function returns an empty genesis configuration for the given chain ID
+func DefaultGenesis(chainID uint32) *Genesis {
+	return &Genesis{
+		ChainID:   chainID,
+		Alloc:     map[types.Address]GenesisAccount{},
+		Storage:   map[string][]byte{},
+		Consensus: ConsensusParams{BlockTime: 5 * time.Second},
+	}
+}
+
+// LoadGenesis 函数从 JSON 文件中读取创世配置
+// LoadGenesis function reads the genesis configuration from a JSON file
+func LoadGenesis(path string) (*Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	g := new(Genesis)
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// Validate 方法检查创世配置是否有效
+// Validate method checks that the genesis configuration is valid
+func (g *Genesis) Validate() error {
+	if g.ChainID == 0 {
+		return fmt.Errorf("genesis has no chain id")
+	}
+	if g.Consensus.BlockTime < 0 {
+		return fmt.Errorf("genesis has negative block time %s", g.Consensus.BlockTime)
+	}
+	seen := make(map[types.Address]struct{}, len(g.Validators))
+	for _, key := range g.Validators {
+		if key.IsZero() {
+			return fmt.Errorf("genesis has invalid validator key")
+		}
+		if _, ok := seen[key.Address()]; ok {
+			return fmt.Errorf("genesis has duplicate validator (%s)", key.Address())
+		}
+		seen[key.Address()] = struct{}{}
+	}
+	return nil
+}
+
+// Hash 方法返回创世配置规范编码的 SHA-256 摘要，写入创世区块的数据哈希
+// Hash method returns the SHA-256 digest of the canonical genesis encoding, which is written into the data hash of the genesis block
+//
+// 整数均为大端序，映射按键的字节序排序，验证者按地址的字节序排序 // All integers are big-endian, maps are sorted by key bytes and validators by address bytes:
+//
+//	domain ("go-blockchain/genesis/v1") | chainID (4) | timestamp (8)
+//	| len(alloc) (4) | { address (20) | balance (8) | len(code) (4) | code }
+//	| len(storage) (4) | { len(key) (4) | key | len(value) (4) | value }
+//	| len(validators) (4) | { len(key) (4) | compressed key }
+//	| blockTime in nanoseconds (8) | blockReward (8)
+func (g *Genesis) Hash() types.Hash {
+	buf := []byte(genesisDomain)
+	buf = binary.BigEndian.AppendUint32(buf, g.ChainID)
+	buf = binary.BigEndian.AppendUint64(buf, g.Timestamp)
+
+	addrs := make([]types.Address, 0, len(g.Alloc))
+	for addr := range g.Alloc {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(addrs)))
+	for _, addr := range addrs {
+		account := g.Alloc[addr]
+		buf = append(buf, addr[:]...)
+		buf = binary.BigEndian.AppendUint64(buf, account.Balance)
+		buf = appendBytes(buf, account.Code)
+	}
+
+	keys := make([]string, 0, len(g.Storage))
+	for k := range g.Storage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(keys)))
+	for _, k := range keys {
+		buf = appendBytes(buf, []byte(k))
+		buf = appendBytes(buf, g.Storage[k])
+	}
+
+	validators := make([]crypto.PublicKey, len(g.Validators))
+	copy(validators, g.Validators)
+	sort.Slice(validators, func(i, j int) bool {
+		a, b := validators[i].Address(), validators[j].Address()
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(validators)))
+	for _, key := range validators {
+		buf = appendBytes(buf, key.ToSlice())
+	}
+
+	buf = binary.BigEndian.AppendUint64(buf, uint64(g.Consensus.BlockTime))
+	buf = binary.BigEndian.AppendUint64(buf, g.Consensus.BlockReward)
+	return sha256.Sum256(buf)
+}
+
+// Block 方法根据创世配置创建创世区块，区块不包含交易，数据哈希为创世配置的哈希
+// Block method creates the genesis block from the configuration, the block has no transactions and its data hash is the genesis hash
+func (g *Genesis) Block() (*Block, error) {
+	header := &Header{
+		Version:   HeaderVersion,
+		Height:    0,
+		Timestamp: g.Timestamp,
+		DataHash:  g.Hash(),
+		ChainID:   g.ChainID,
+	}
+	return NewBlock(header, nil)
+}
+
+// genesisJSON 结构体是创世配置的 JSON 格式，地址为 bech32m 字符串，代码、状态和公钥均为十六进制字符串
+// genesisJSON struct is the JSON format of the genesis configuration, addresses are bech32m strings, code, state and public keys are hex strings
+type genesisJSON struct {
+	ChainID    uint32                        `json:"chainId"`
+	Timestamp  uint64                        `json:"timestamp"`
+	Alloc      map[string]genesisAccountJSON `json:"alloc,omitempty"`
+	Storage    map[string]string             `json:"storage,omitempty"`
+	Validators []string                      `json:"validators,omitempty"`
+	Consensus  consensusParamsJSON           `json:"consensus"`
+}
+
+// genesisAccountJSON 结构体是创世账户的 JSON 格式
+// genesisAccountJSON struct is the JSON format of a genesis account
+type genesisAccountJSON struct {
+	Balance uint64 `json:"balance"`
+	Code    string `json:"code,omitempty"`
+}
+
+// consensusParamsJSON 结构体是共识参数的 JSON 格式，出块时间为 Go 时间间隔字符串，例如 "5s"
+// consensusParamsJSON struct is the JSON format of the consensus parameters, the block time is a Go duration string such as "5s"
+type consensusParamsJSON struct {
+	BlockTime   string `json:"blockTime,omitempty"`
+	BlockReward uint64 `json:"blockReward"`
+}
+
+// MarshalJSON 方法将创世配置编码为 JSON
+// MarshalJSON method encodes the genesis configuration as JSON
+func (g *Genesis) MarshalJSON() ([]byte, error) {
+	out := genesisJSON{
+		ChainID:   g.ChainID,
+		Timestamp: g.Timestamp,
+		Alloc:     make(map[string]genesisAccountJSON, len(g.Alloc)),
+		Storage:   make(map[string]string, len(g.Storage)),
+		Consensus: consensusParamsJSON{BlockReward: g.Consensus.BlockReward},
+	}
+	for addr, account := range g.Alloc {
+		out.Alloc[addr.String()] = genesisAccountJSON{Balance: account.Balance, Code: hex.EncodeToString(account.Code)}
+	}
+	for k, v := range g.Storage {
+		out.Storage[hex.EncodeToString([]byte(k))] = hex.EncodeToString(v)
+	}
+	for _, key := range g.Validators {
+		out.Validators = append(out.Validators, hex.EncodeToString(key.ToSlice()))
+	}
+	if g.Consensus.BlockTime > 0 {
+		out.Consensus.BlockTime = g.Consensus.BlockTime.String()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 方法从 JSON 解码并校验创世配置
+// UnmarshalJSON method decodes and validates the genesis configuration from JSON
+func (g *Genesis) UnmarshalJSON(data []byte) error {
+	in := genesisJSON{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	out := Genesis{
+		ChainID:   in.ChainID,
+		Timestamp: in.Timestamp,
+		Alloc:     make(map[types.Address]GenesisAccount, len(in.Alloc)),
+		Storage:   make(map[string][]byte, len(in.Storage)),
+		Consensus: ConsensusParams{BlockReward: in.Consensus.BlockReward},
+	}
+	for s, account := range in.Alloc {
+		addr, err := types.ParseAddress(s)
+		if err != nil {
+			return fmt.Errorf("invalid genesis address: %w", err)
+		}
+		code, err := decodeHex(account.Code)
+		if err != nil {
+			return fmt.Errorf("invalid code of genesis account %q: %w", s, err)
+		}
+		if _, ok := out.Alloc[addr]; ok {
+			return fmt.Errorf("duplicate genesis address %q", s)
+		}
+		out.Alloc[addr] = GenesisAccount{Balance: account.Balance, Code: code}
+	}
+	for k, v := range in.Storage {
+		key, err := decodeHex(k)
+		if err != nil {
+			return fmt.Errorf("invalid genesis storage key %q: %w", k, err)
+		}
+		value, err := decodeHex(v)
+		if err != nil {
+			return fmt.Errorf("invalid genesis storage value of key %q: %w", k, err)
+		}
+		out.Storage[string(key)] = value
+	}
+	for _, s := range in.Validators {
+		b, err := decodeHex(s)
+		if err != nil || len(b) == 0 {
+			return fmt.Errorf("invalid genesis validator %q", s)
+		}
+		key, err := crypto.PublicKeyFromBytes(b)
+		if err != nil {
+			return fmt.Errorf("invalid genesis validator %q: %w", s, err)
+		}
+		out.Validators = append(out.Validators, key)
+	}
+	if in.Consensus.BlockTime != "" {
+		blockTime, err := time.ParseDuration(in.Consensus.BlockTime)
+		if err != nil {
+			return fmt.Errorf("invalid genesis block time: %w", err)
+		}
+		out.Consensus.BlockTime = blockTime
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+
+	*g = out
+	return nil
+}
+
+// decodeHex 函数解码可带 0x 前缀的十六进制字符串
+// decodeHex function decodes a hex string with an optional 0x prefix
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+}
+
+// appendBytes 函数追加带 4 字节长度前缀的字节切片
+// appendBytes function appends a byte slice prefixed with its 4 byte length
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
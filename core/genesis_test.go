@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenesisFromJSON 测试从 JSON 创世配置创建区块链
+// TestGenesisFromJSON tests creating a blockchain from a JSON genesis configuration
+func TestGenesisFromJSON(t *testing.T) {
+	validator := crypto.GeneratePrivateKey().PublicKey()
+	spec := fmt.Sprintf(`{
+		"chainId": 7,
+		"timestamp": 1700000000,
+		"alloc": {
+			"%s": {"balance": 1000},
+			"%s": {"balance": 5, "code": "0x030a"}
+		},
+		"storage": {"666f6f": "626172"},
+		"validators": ["%s"],
+		"consensus": {"blockTime": "2s", "blockReward": 10}
+	}`, types.Address{1}, types.Address{2}, hex.EncodeToString(validator.ToSlice()))
+
+	g := new(Genesis)
+	assert.Nil(t, json.Unmarshal([]byte(spec), g))
+	assert.Equal(t, 2*time.Second, g.Consensus.BlockTime)
+
+	bc, err := NewBlockchainFromGenesis(log.NewNopLogger(), g)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), bc.ChainID())
+	assert.Equal(t, uint64(1000), bc.GetBalance(types.Address{1}))
+	assert.Equal(t, []byte{0x03, 0x0a}, bc.GetCode(types.Address{2}))
+	assert.True(t, bc.IsValidator(validator.Address()))
+	assert.Equal(t, uint64(10), bc.BlockReward())
+
+	value, err := bc.contractState.Get([]byte("foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("bar"), value)
+
+	header, err := bc.GetHeader(0)
+	assert.Nil(t, err)
+	assert.Equal(t, g.Hash(), header.DataHash)
+	assert.Equal(t, uint64(1700000000), header.Timestamp)
+}
+
+// TestGenesisHashDeterministic 测试创世哈希与 JSON 的书写顺序和地址大小写无关，并覆盖所有配置
+// TestGenesisHashDeterministic tests that the genesis hash does not depend on the JSON layout or address case and covers the whole configuration
+func TestGenesisHashDeterministic(t *testing.T) {
+	addr1 := types.Address{1}.String()
+	addr2 := types.Address{2}.String()
+
+	a := new(Genesis)
+	assert.Nil(t, json.Unmarshal([]byte(fmt.Sprintf(`{"chainId": 1, "alloc": {"%s": {"balance": 1}, "%s": {"balance": 2}}}`, addr1, addr2)), a))
+	b := new(Genesis)
+	assert.Nil(t, json.Unmarshal([]byte(fmt.Sprintf(`{"alloc": {"%s": {"balance": 2}, "%s": {"balance": 1}}, "chainId": 1}`, strings.ToUpper(addr2), addr1)), b))
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	blockA, err := a.Block()
+	assert.Nil(t, err)
+	blockB, err := b.Block()
+	assert.Nil(t, err)
+	assert.Equal(t, blockA.Hash(BlockHasher{}), blockB.Hash(BlockHasher{}))
+
+	b.Consensus.BlockReward = 1
+	assert.NotEqual(t, a.Hash(), b.Hash())
+
+	// JSON 往返不改变哈希 // A JSON round trip keeps the hash
+	data, err := json.Marshal(a)
+	assert.Nil(t, err)
+	c := new(Genesis)
+	assert.Nil(t, json.Unmarshal(data, c))
+	assert.Equal(t, a.Hash(), c.Hash())
+}
+
+// TestGenesisInvalid 测试拒绝无效的创世配置
+// TestGenesisInvalid tests rejecting invalid genesis configurations
+func TestGenesisInvalid(t *testing.T) {
+	for _, spec := range []string{
+		`{}`,
+		`{"chainId": 1, "alloc": {"zz": {"balance": 1}}}`,
+		`{"chainId": 1, "alloc": {"01": {"balance": 1}}}`,
+		// 原始十六进制地址没有校验和 // Raw hex addresses have no checksum
+		`{"chainId": 1, "alloc": {"0100000000000000000000000000000000000000": {"balance": 1}}}`,
+		`{"chainId": 1, "validators": ["00"]}`,
+		`{"chainId": 1, "consensus": {"blockTime": "soon"}}`,
+	} {
+		assert.NotNil(t, json.Unmarshal([]byte(spec), new(Genesis)), spec)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"github.com/lonySp/go-blockchain/types"
 )
@@ -15,8 +16,8 @@ type Hasher[T any] interface {
 // BlockHasher implements the Hasher interface for calculating the hash of block headers
 type BlockHasher struct{}
 
-// Hash 方法计算区块头的哈希值
-// Hash method calculates the hash of the block header
+// Hash 方法计算区块头规范编码的 SHA-256 哈希值
+// Hash method calculates the SHA-256 hash of the canonical block header encoding
 func (BlockHasher) Hash(b *Header) types.Hash {
 	h := sha256.Sum256(b.Bytes())
 	return h
@@ -26,8 +27,11 @@ func (BlockHasher) Hash(b *Header) types.Hash {
 // TxHasher implements the Hasher interface for calculating the hash of transactions
 type TxHasher struct{}
 
-// Hash 方法计算交易的哈希值
-// Hash method calculates the hash of the transaction
+// Hash 方法计算交易的哈希值，哈希覆盖发送方和交易的规范编码，不同发送方的相同数据不会冲突
+// Hash method calculates the hash of the transaction, it covers the sender and the canonical transaction encoding so the same data from different senders does not collide
 func (TxHasher) Hash(tx *Transaction) types.Hash {
-	return sha256.Sum256(tx.Data)
+	buf := &bytes.Buffer{}
+	buf.Write(tx.From.ToSlice())
+	buf.Write(tx.CanonicalBytes())
+	return sha256.Sum256(buf.Bytes())
 }
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"github.com/lonySp/go-blockchain/types"
+	"sync"
+)
+
+// DefaultSigCacheSize 是区块链签名缓存的默认容量
+// DefaultSigCacheSize is the default capacity of the blockchain signature cache
+const DefaultSigCacheSize = 1 << 16
+
+// SigCache 结构体缓存已通过签名验证的交易，满时淘汰最早加入的条目
+// SigCache struct caches transactions whose signatures have been verified, the oldest entry is evicted when it is full
+type SigCache struct {
+	lock     sync.RWMutex
+	capacity int                     // 最大条目数 // Maximum number of entries
+	entries  map[types.Hash]struct{} // 已验证的条目 // Verified entries
+	order    []types.Hash            // 按加入顺序排列的环形缓冲区 // Ring buffer in insertion order
+	next     int                     // 下一个被覆盖的位置 // Next position to overwrite
+}
+
+// NewSigCache 函数创建一个指定容量的签名缓存
+// NewSigCache function creates a signature cache of the given capacity
+func NewSigCache(capacity int) *SigCache {
+	return &SigCache{
+		capacity: capacity,
+		entries:  make(map[types.Hash]struct{}, capacity),
+		order:    make([]types.Hash, 0, capacity),
+	}
+}
+
+// Contains 方法检查条目是否已验证，空缓存总是返回 false
+// Contains method checks if the entry has been verified, a nil cache always returns false
+func (c *SigCache) Contains(h types.Hash) bool {
+	if c == nil {
+		return false
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, ok := c.entries[h]
+	return ok
+}
+
+// Add 方法记录一个已验证的条目
+// Add method records a verified entry
+func (c *SigCache) Add(h types.Hash) {
+	if c == nil || c.capacity <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.entries[h]; ok {
+		return
+	}
+	if len(c.order) < c.capacity {
+		c.order = append(c.order, h)
+	} else {
+		delete(c.entries, c.order[c.next])
+		c.order[c.next] = h
+		c.next = (c.next + 1) % c.capacity
+	}
+	c.entries[h] = struct{}{}
+}
+
+// Len 方法返回缓存的条目数
+// Len method returns the number of cached entries
+func (c *SigCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.entries)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSigCacheEviction 测试签名缓存满时淘汰最早的条目
+// TestSigCacheEviction tests that the signature cache evicts the oldest entry when it is full
+func TestSigCacheEviction(t *testing.T) {
+	cache := NewSigCache(2)
+	cache.Add(types.Hash{1})
+	cache.Add(types.Hash{2})
+	cache.Add(types.Hash{2})
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Add(types.Hash{3})
+	assert.False(t, cache.Contains(types.Hash{1}))
+	assert.True(t, cache.Contains(types.Hash{2}))
+	assert.True(t, cache.Contains(types.Hash{3}))
+
+	var nilCache *SigCache
+	assert.False(t, nilCache.Contains(types.Hash{1}))
+}
+
+// TestVerifyCachedCoversSignature 测试缓存键覆盖签名，被替换的签名不会因缓存而通过验证
+// TestVerifyCachedCoversSignature tests that the cache key covers the signature, so a swapped signature is not accepted because of the cache
+func TestVerifyCachedCoversSignature(t *testing.T) {
+	cache := NewSigCache(DefaultSigCacheSize)
+	tx := randomTxWithSignature(t)
+	assert.Nil(t, tx.VerifyCached(cache))
+	assert.Equal(t, 1, cache.Len())
+
+	other := randomTxWithSignature(t)
+	tx.Signature = other.Signature
+	assert.NotNil(t, tx.VerifyCached(cache))
+	assert.Equal(t, 1, cache.Len())
+}
+
+// TestVerifyBlockParallel 测试并行和批量验证大量交易，包括批量验证失败后定位无效交易
+// TestVerifyBlockParallel tests parallel and batch verification of many transactions, including finding the invalid transaction after a failed batch
+func TestVerifyBlockParallel(t *testing.T) {
+	var txx []*Transaction
+	for i := 0; i < 64; i++ {
+		keyType := []crypto.KeyType{crypto.KeyTypeP256, crypto.KeyTypeEd25519, crypto.KeyTypeSecp256k1}[i%3]
+		privateKey, err := crypto.GenerateKey(keyType)
+		assert.Nil(t, err)
+		tx := NewTransferTransaction(types.Address{byte(i)}, uint64(i))
+		assert.Nil(t, tx.Sign(privateKey))
+		txx = append(txx, tx)
+	}
+
+	cache := NewSigCache(DefaultSigCacheSize)
+	b := randomBlockWithTransactions(t, 1, types.Hash{}, txx)
+	assert.Nil(t, b.VerifyCached(cache))
+	assert.Equal(t, len(txx), cache.Len())
+	assert.Nil(t, b.Verify())
+
+	// 篡改一个 Ed25519 交易，批量验证失败后逐个验证找到它 // Tamper with an Ed25519 transaction, the failed batch falls back to verifying one by one
+	tampered := randomBlockWithTransactions(t, 1, types.Hash{}, txx)
+	txx[1].Value++
+	assert.Equal(t, crypto.KeyTypeEd25519, txx[1].From.Type)
+	assert.ErrorContains(t, tampered.VerifyCached(NewSigCache(DefaultSigCacheSize)), "invalid signature")
+	assert.NotNil(t, tampered.VerifyCached(cache))
+}
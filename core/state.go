@@ -1,10 +1,14 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // State 结构体表示合约的状态存储
 // State struct represents the state storage for contracts
 type State struct {
+	lock sync.RWMutex      // 读写锁 // Read-write lock
 	data map[string][]byte // 存储键值对的映射 // Map for storing key-value pairs
 }
 
@@ -19,6 +23,9 @@ func NewState() *State {
 // Put 将键值对存储到状态中
 // Put stores a key-value pair in the state
 func (s *State) Put(k, v []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	s.data[string(k)] = v // 将值存储在映射中 // Store the value in the map
 	return nil
 }
@@ -26,6 +33,9 @@ func (s *State) Put(k, v []byte) error {
 // Delete 从状态中删除指定键的值
 // Delete removes the value for the specified key from the state
 func (s *State) Delete(k []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	delete(s.data, string(k)) // 从映射中删除键值对 // Remove the key-value pair from the map
 	return nil
 }
@@ -33,6 +43,9 @@ func (s *State) Delete(k []byte) error {
 // Get 获取指定键的值
 // Get retrieves the value for the specified key from the state
 func (s *State) Get(k []byte) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	key := string(k)         // 将键转换为字符串 // Convert the key to a string
 	value, ok := s.data[key] // 从映射中获取值 // Get the value from the map
 	if !ok {                 // 如果键不存在，返回错误 // Return an error if the key does not exist
@@ -40,3 +53,16 @@ func (s *State) Get(k []byte) ([]byte, error) {
 	}
 	return value, nil // 返回值 // Return the value
 }
+
+// Copy 返回状态的拷贝，用于在不修改真实状态的情况下模拟执行
+// Copy returns a copy of the state, used to simulate execution without touching the real state
+func (s *State) Copy() *State {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	cp := NewState()
+	for k, v := range s.data {
+		cp.data[k] = v
+	}
+	return cp
+}
@@ -1,24 +1,49 @@
 package core
 
+import (
+	"fmt"
+	"sync"
+)
+
 // Storage 接口定义了存储方法
 // Storage interface defines storage methods
 type Storage interface {
 	Put(block *Block) error
+	Get(height uint32) (*Block, error)
 }
 
-// MemoryStore 结构体表示内存存储
-// MemoryStore struct represents an in-memory storage
+// MemoryStore 结构体表示内存存储，按高度保存区块
+// MemoryStore struct represents an in-memory storage keeping blocks by height
 type MemoryStore struct {
+	lock   sync.RWMutex      // 保护区块映射的读写锁 // Read-write lock guarding the block map
+	blocks map[uint32]*Block // 按高度保存的区块 // Blocks by height
 }
 
 // NewMemoryStore 创建一个新的内存存储
 // NewMemoryStore creates a new in-memory storage
 func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{}
+	return &MemoryStore{blocks: make(map[uint32]*Block)}
 }
 
 // Put 方法将区块存储在内存中
 // Put method stores the block in memory
 func (s *MemoryStore) Put(b *Block) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.blocks[b.Height] = b
 	return nil
 }
+
+// Get 方法返回指定高度的区块
+// Get method returns the block at the given height
+func (s *MemoryStore) Get(height uint32) (*Block, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	b, ok := s.blocks[height]
+	if !ok {
+		return nil, fmt.Errorf("no block at height (%d)", height)
+	}
+	return b, nil
+}
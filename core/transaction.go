@@ -1,18 +1,39 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
+	"math"
+	"sort"
 )
 
 // Transaction 结构体表示区块链中的交易
 // Transaction struct represents a transaction in the blockchain
 type Transaction struct {
-	Data      []byte            // 交易数据
-	From      crypto.PublicKey  // 发送方公钥
+	Version   uint32            // 交易信封版本号
+	Type      TxType            // 交易类型
+	Data      []byte            // 交易数据，格式由交易类型决定
+	To        types.Address     // 接收方地址
+	Value     uint64            // 转账金额
+	Fee       uint64            // 支付给区块验证者的手续费
+	Nonce     uint64            // 发送方账户的交易序号
+	ChainID   uint32            // 交易所属链的 ID
+	From      crypto.PublicKey  // 发送方公钥，可恢复签名的交易在编码时省略并在解码时恢复
 	Signature *crypto.Signature // 交易签名
 
+	FromAddress *types.Address // 可选的发送方地址，设置后必须与恢复出的地址一致 // Optional sender address, it has to match the recovered address when set
+
+	ValidAfter uint32 // 可被打包的最低区块高度，0 表示不限制 // Lowest block height the tx may be included at, 0 means unbounded
+	ValidUntil uint32 // 可被打包的最高区块高度，0 表示不限制 // Highest block height the tx may be included at, 0 means unbounded
+
+	Multisig   *crypto.MultisigAccount // 多签发送方账户，为空时使用单签 // Multisig sender account, single key signing when nil
+	Signatures []crypto.MultiSignature // 多签成员签名 // Signatures of the multisig members
+
+	Bundle *BundleRef // 所属的原子交易包，为空时单独执行 // Atomic bundle the tx belongs to, executed on its own when nil
+
 	// tx 数据哈希的缓存版本
 	// cached version of the tx data hash
 	hash types.Hash
@@ -21,11 +42,13 @@ type Transaction struct {
 	firstSeen int64
 }
 
-// NewTransaction 函数创建一个新的交易实例
-// NewTransaction function creates a new instance of Transaction
-func NewTransaction(data []byte) *Transaction {
+// NewTransaction 函数创建一个指定类型的交易实例
+// NewTransaction function creates a new instance of Transaction of the given type
+func NewTransaction(txType TxType, data []byte) *Transaction {
 	return &Transaction{
-		Data: data,
+		Version: TxVersion,
+		Type:    txType,
+		Data:    data,
 	}
 }
 
@@ -40,12 +63,73 @@ func (tx *Transaction) Hash(hasher Hasher[*Transaction]) types.Hash {
 	return tx.hash
 }
 
-// Sign 方法使用私钥对交易数据进行签名
-// Sign method signs the transaction data using the private key
+// NewTransferTransaction 函数创建一个原生转账交易
+// NewTransferTransaction function creates a native value transfer transaction
+func NewTransferTransaction(to types.Address, value uint64) *Transaction {
+	tx := NewTransaction(TxTypeTransfer, nil)
+	tx.To = to
+	tx.Value = value
+	return tx
+}
+
+// NewDeployTransaction 函数创建一个部署合约代码的交易
+// NewDeployTransaction function creates a transaction deploying contract code
+func NewDeployTransaction(code []byte) *Transaction {
+	return NewTransaction(TxTypeDeploy, code)
+}
+
+// NewCallTransaction 函数创建一个调用已部署合约的交易
+// NewCallTransaction function creates a transaction calling a deployed contract
+func NewCallTransaction(contract types.Address) *Transaction {
+	tx := NewTransaction(TxTypeCall, nil)
+	tx.To = contract
+	return tx
+}
+
+// NewValidatorUpdateTransaction 函数创建一个加入或移除验证者的交易
+// NewValidatorUpdateTransaction function creates a transaction adding or removing a validator
+func NewValidatorUpdateTransaction(update *ValidatorUpdate) (*Transaction, error) {
+	data, err := update.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(TxTypeValidatorUpdate, data), nil
+}
+
+// Cost 方法返回交易从发送方扣除的总金额，即转账金额加手续费
+// Cost method returns the total amount debited from the sender, i.e. the value plus the fee
+func (tx *Transaction) Cost() (uint64, error) {
+	if tx.Value > math.MaxUint64-tx.Fee {
+		return 0, fmt.Errorf("transaction value %d plus fee %d overflows", tx.Value, tx.Fee)
+	}
+	return tx.Value + tx.Fee, nil
+}
+
+// ValidAt 方法检查交易是否可以被打包到指定高度的区块中
+// ValidAt method checks if the transaction may be included in a block at the given height
+func (tx *Transaction) ValidAt(height uint32) error {
+	if height < tx.ValidAfter {
+		return fmt.Errorf("transaction is not valid before height %d, got %d", tx.ValidAfter, height)
+	}
+	if tx.Expired(height) {
+		return fmt.Errorf("transaction expired at height %d, got %d", tx.ValidUntil, height)
+	}
+	return nil
+}
+
+// Expired 方法检查交易在指定高度及之后是否已无法被打包
+// Expired method checks if the transaction can no longer be included at the given height or later
+func (tx *Transaction) Expired(height uint32) bool {
+	return tx.ValidUntil != 0 && height > tx.ValidUntil
+}
+
+// Sign 方法使用私钥对交易所有共识字段的摘要进行签名
+// Sign method signs the digest of every consensus field of the transaction using the private key
 func (tx *Transaction) Sign(privateKey crypto.PrivateKey) error {
-	// 使用私钥对交易数据进行签名
-	// Sign the transaction data using the private key
-	sig, err := privateKey.Sign(tx.Data)
+	// 使用私钥对交易规范编码的摘要进行签名
+	// Sign the digest of the canonical transaction encoding using the private key
+	digest := tx.SigningHash()
+	sig, err := privateKey.Sign(digest[:])
 	if err != nil {
 		return err
 	}
@@ -53,25 +137,207 @@ func (tx *Transaction) Sign(privateKey crypto.PrivateKey) error {
 	// Set the sender's public key and transaction signature
 	tx.From = privateKey.PublicKey()
 	tx.Signature = sig
+	// 签名改变了发送方，需要重新计算哈希
+	// The signature changes the sender, so the hash has to be recomputed
+	tx.hash = types.Hash{}
+	return nil
+}
+
+// SignRecoverable 方法使用可恢复签名对交易签名，编码时省略发送方公钥，由接收方从签名恢复
+// SignRecoverable method signs the transaction with a recoverable signature, the sender's public key is omitted from the encoding and recovered by the receiver
+func (tx *Transaction) SignRecoverable(privateKey crypto.PrivateKey) error {
+	digest := tx.SigningHash()
+	sig, err := privateKey.SignRecoverable(digest[:])
+	if err != nil {
+		return err
+	}
+	tx.From = privateKey.PublicKey()
+	tx.Signature = sig
+	tx.hash = types.Hash{}
+	return nil
+}
+
+// recoverSender 方法从可恢复签名恢复发送方公钥
+// recoverSender method recovers the sender's public key from the recoverable signature
+func (tx *Transaction) recoverSender() error {
+	digest := tx.SigningHash()
+	from, err := tx.Signature.RecoverPublicKey(digest[:])
+	if err != nil {
+		return err
+	}
+	tx.From = from
+	tx.hash = types.Hash{}
+	return nil
+}
+
+// SignMultisig 方法以多签账户成员的身份对交易签名，签名按成员索引排序
+// SignMultisig method signs the transaction as a member of the multisig account, the signatures are kept ordered by member index
+func (tx *Transaction) SignMultisig(privateKey crypto.PrivateKey, account *crypto.MultisigAccount) error {
+	if tx.Multisig != nil && tx.Multisig.Address() != account.Address() {
+		return fmt.Errorf("transaction is already signed for multisig account (%s)", tx.Multisig.Address())
+	}
+	index := account.IndexOf(privateKey.PublicKey())
+	if index < 0 {
+		return fmt.Errorf("key is not a member of multisig account (%s)", account.Address())
+	}
+
+	// 多签账户地址属于签名数据，必须在签名前设置
+	// The multisig account address is part of the signed data, so it must be set before signing
+	tx.Multisig = account
+	digest := tx.SigningHash()
+	sig, err := privateKey.Sign(digest[:])
+	if err != nil {
+		return err
+	}
+
+	sigs := make([]crypto.MultiSignature, 0, len(tx.Signatures)+1)
+	for _, other := range tx.Signatures {
+		if other.Index != uint32(index) {
+			sigs = append(sigs, other)
+		}
+	}
+	sigs = append(sigs, crypto.MultiSignature{Index: uint32(index), Signature: sig})
+	sort.Slice(sigs, func(i, j int) bool {
+		return sigs[i].Index < sigs[j].Index
+	})
+	tx.Signatures = sigs
+	tx.hash = types.Hash{}
+	return nil
+}
+
+// Sender 方法返回交易发送方的地址，多签交易返回多签账户地址
+// Sender method returns the address of the transaction sender, the multisig account address for multisig transactions
+func (tx *Transaction) Sender() types.Address {
+	if tx.Multisig != nil {
+		return tx.Multisig.Address()
+	}
+	return tx.From.Address()
+}
+
+// VerifyCached 方法验证交易签名，已在缓存中的交易不再重复验证，验证通过后写入缓存
+// VerifyCached method verifies the transaction signature, skipping transactions already in the cache and adding them once verified
+func (tx *Transaction) VerifyCached(cache *SigCache) error {
+	if cache == nil {
+		return tx.Verify()
+	}
+	key := tx.verificationHash()
+	if cache.Contains(key) {
+		return nil
+	}
+	if err := tx.Verify(); err != nil {
+		return err
+	}
+	cache.Add(key)
 	return nil
 }
 
+// verificationHash 方法返回签名验证覆盖的全部内容的摘要，包括签名本身，用作签名缓存的键
+// verificationHash method returns the digest of everything signature verification covers, including the signatures themselves, used as the signature cache key
+func (tx *Transaction) verificationHash() types.Hash {
+	digest := tx.SigningHash()
+	buf := append([]byte(nil), digest[:]...)
+	buf = appendBytes(buf, tx.From.ToSlice())
+	if tx.FromAddress != nil {
+		buf = append(buf, tx.FromAddress.ToSlice()...)
+	}
+	if tx.Signature != nil {
+		buf = appendBytes(buf, tx.Signature.ToBytes())
+	}
+	for _, sig := range tx.Signatures {
+		buf = binary.BigEndian.AppendUint32(buf, sig.Index)
+		buf = appendBytes(buf, sig.Signature.ToBytes())
+	}
+	if tx.Multisig != nil {
+		for _, key := range tx.Multisig.PublicKeys {
+			buf = appendBytes(buf, key.ToSlice())
+		}
+	}
+	return sha256.Sum256(buf)
+}
+
 // Verify 方法验证交易签名的有效性
 // Verify method verifies the validity of the transaction signature
 func (tx *Transaction) Verify() error {
+	digest := tx.SigningHash()
+
+	// 多签交易需要满足门限 // Multisig transactions have to meet the threshold
+	if tx.Multisig != nil {
+		return tx.Multisig.Verify(tx.Signatures, digest[:])
+	}
+
 	// 如果签名为空，则返回错误
 	// Return an error if the signature is nil
 	if tx.Signature == nil {
 		return fmt.Errorf("transaction has no signature")
 	}
-	// 验证签名，如果无效则返回错误
-	// Verify the signature, return an error if invalid
-	if !tx.Signature.Verify(tx.From, tx.Data) {
+	// 使用相同的摘要验证签名，如果无效则返回错误
+	// Verify the signature over the same digest, return an error if invalid
+	if !tx.Signature.Verify(tx.From, digest[:]) {
 		return fmt.Errorf("invalid signature")
 	}
+	return tx.verifyFromAddress()
+}
+
+// verifyFromAddress 方法检查可选的发送方地址是否与签名者一致
+// verifyFromAddress method checks that the optional sender address matches the signer
+func (tx *Transaction) verifyFromAddress() error {
+	if tx.FromAddress != nil && *tx.FromAddress != tx.From.Address() {
+		return fmt.Errorf("signer (%s) does not match sender address (%s)", tx.From.Address(), tx.FromAddress)
+	}
 	return nil
 }
 
+// txSigningDomain 是交易规范编码的域前缀，用于区分交易签名和其他签名
+// txSigningDomain is the domain prefix of the canonical transaction encoding, separating transaction signatures from other signatures
+const txSigningDomain = "go-blockchain/tx/v1"
+
+// CanonicalBytes 方法返回交易所有共识字段的规范编码
+// CanonicalBytes method returns the canonical encoding of every consensus field of the transaction
+//
+// 整数均为大端序 // All integers are big-endian:
+//
+//	domain ("go-blockchain/tx/v1") | version (4) | type (1) | chainID (4) | nonce (8) | to (20) | value (8) | fee (8) | validAfter (4) | validUntil (4) | len(data) (4) | data | multisig (1) [| multisig address (20)] | bundle (1) [| bundle id (32) | index (4) | size (4)]
+//
+// 多签交易在末尾附加多签账户地址，防止成员签名被挪用到包含相同公钥的其他多签账户
+// Multisig transactions append the multisig account address so member signatures cannot be reused for another multisig account sharing the same keys
+//
+// 交易包成员附加包引用，防止成员被拆出交易包单独打包
+// Bundle members append their bundle reference so a member cannot be taken out of its bundle and included on its own
+func (tx *Transaction) CanonicalBytes() []byte {
+	buf := make([]byte, 0, len(txSigningDomain)+len(tx.To)+len(tx.Data)+66)
+	buf = append(buf, txSigningDomain...)
+	buf = binary.BigEndian.AppendUint32(buf, tx.Version)
+	buf = append(buf, byte(tx.Type))
+	buf = binary.BigEndian.AppendUint32(buf, tx.ChainID)
+	buf = binary.BigEndian.AppendUint64(buf, tx.Nonce)
+	buf = append(buf, tx.To[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, tx.Value)
+	buf = binary.BigEndian.AppendUint64(buf, tx.Fee)
+	buf = binary.BigEndian.AppendUint32(buf, tx.ValidAfter)
+	buf = binary.BigEndian.AppendUint32(buf, tx.ValidUntil)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(tx.Data)))
+	buf = append(buf, tx.Data...)
+	if tx.Multisig == nil {
+		buf = append(buf, 0x00)
+	} else {
+		buf = append(buf, 0x01)
+		buf = append(buf, tx.Multisig.Address().ToSlice()...)
+	}
+	if tx.Bundle == nil {
+		return append(buf, 0x00)
+	}
+	buf = append(buf, 0x01)
+	buf = append(buf, tx.Bundle.ID.ToSlice()...)
+	buf = binary.BigEndian.AppendUint32(buf, tx.Bundle.Index)
+	return binary.BigEndian.AppendUint32(buf, tx.Bundle.Size)
+}
+
+// SigningHash 方法返回签名所覆盖的规范编码的 SHA-256 摘要
+// SigningHash method returns the SHA-256 digest of the canonical encoding that the signature covers
+func (tx *Transaction) SigningHash() types.Hash {
+	return sha256.Sum256(tx.CanonicalBytes())
+}
+
 // Decode 方法从解码器中解码交易数据
 // Decode method decodes the transaction data from the decoder
 func (tx *Transaction) Decode(dec Decoder[*Transaction]) error {
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
 	"github.com/stretchr/testify/assert"
@@ -42,6 +43,138 @@ func TestVerifyTransaction(t *testing.T) {
 	assert.NotNil(t, tx.Verify())
 }
 
+// TestTxHashCoversSenderAndNonce 测试交易哈希覆盖发送方和序号
+// TestTxHashCoversSenderAndNonce tests that the transaction hash covers the sender and the nonce
+func TestTxHashCoversSenderAndNonce(t *testing.T) {
+	txA := randomTxWithSignature(t)
+	txB := randomTxWithSignature(t)
+	assert.NotEqual(t, txA.Hash(TxHasher{}), txB.Hash(TxHasher{}))
+
+	privateKey := crypto.GeneratePrivateKey()
+	txC := &Transaction{Data: []byte("foo")}
+	assert.Nil(t, txC.Sign(privateKey))
+	txD := &Transaction{Data: []byte("foo"), Nonce: 1}
+	assert.Nil(t, txD.Sign(privateKey))
+	assert.NotEqual(t, txC.Hash(TxHasher{}), txD.Hash(TxHasher{}))
+
+	// 修改序号会使签名失效 // Changing the nonce invalidates the signature
+	txD.Nonce = 0
+	assert.NotNil(t, txD.Verify())
+}
+
+// TestTxSignatureCoversChainID 测试交易签名绑定链 ID
+// TestTxSignatureCoversChainID tests that the transaction signature is bound to the chain ID
+func TestTxSignatureCoversChainID(t *testing.T) {
+	tx := &Transaction{Data: []byte("foo"), ChainID: 1}
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	assert.Nil(t, tx.Verify())
+
+	tx.ChainID = 2
+	assert.NotNil(t, tx.Verify())
+}
+
+// TestTxSignatureCoversAllFields 测试签名覆盖交易的所有共识字段
+// TestTxSignatureCoversAllFields tests that the signature covers every consensus field of the transaction
+func TestTxSignatureCoversAllFields(t *testing.T) {
+	privateKey := crypto.GeneratePrivateKey()
+	mutations := []func(tx *Transaction){
+		func(tx *Transaction) { tx.Data[len(tx.Data)-1] ^= 0xff },
+		func(tx *Transaction) { tx.To[19] ^= 0xff },
+		func(tx *Transaction) { tx.Value++ },
+		func(tx *Transaction) { tx.Fee++ },
+		func(tx *Transaction) { tx.Nonce++ },
+		func(tx *Transaction) { tx.ChainID++ },
+	}
+
+	for _, mutate := range mutations {
+		// 数据长度超过 32 字节，确保尾部同样被签名覆盖
+		// The data is longer than 32 bytes to make sure the tail is covered by the signature as well
+		tx := &Transaction{Data: types.RandomBytes(64), To: types.Address{1}, Value: 10, Fee: 1, ChainID: 1}
+		assert.Nil(t, tx.Sign(privateKey))
+		assert.Nil(t, tx.Verify())
+
+		mutate(tx)
+		assert.NotNil(t, tx.Verify())
+	}
+}
+
+// TestTxCanonicalBytes 测试交易规范编码的格式
+// TestTxCanonicalBytes tests the layout of the canonical transaction encoding
+func TestTxCanonicalBytes(t *testing.T) {
+	tx := &Transaction{
+		Version: TxVersion,
+		Type:    TxTypeDeploy,
+		ChainID: 1,
+		Nonce:   2,
+		To:      types.Address{0xaa},
+		Value:   3,
+		Fee:     4,
+		Data:    []byte{0xde, 0xad},
+
+		ValidAfter: 5,
+		ValidUntil: 6,
+	}
+
+	expected := append([]byte("go-blockchain/tx/v1"),
+		0x00, 0x00, 0x00, 0x01, // version
+		0x01,                   // type
+		0x00, 0x00, 0x00, 0x01, // chainID
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, // nonce
+		0xaa, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // to
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, // value
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, // fee
+		0x00, 0x00, 0x00, 0x05, // validAfter
+		0x00, 0x00, 0x00, 0x06, // validUntil
+		0x00, 0x00, 0x00, 0x02, // len(data)
+		0xde, 0xad, // data
+		0x00, // multisig
+		0x00, // bundle
+	)
+	assert.Equal(t, expected, tx.CanonicalBytes())
+	assert.Equal(t, types.Hash(sha256.Sum256(expected)), tx.SigningHash())
+}
+
+// TestMultisigTransaction 测试多签交易的签名、验证和编解码
+// TestMultisigTransaction tests signing, verifying and encoding multisig transactions
+func TestMultisigTransaction(t *testing.T) {
+	keys := []crypto.PrivateKey{crypto.GeneratePrivateKey(), crypto.GeneratePrivateKey(), crypto.GeneratePrivateKey()}
+	account, err := crypto.NewMultisigAccount(2, []crypto.PublicKey{keys[0].PublicKey(), keys[1].PublicKey(), keys[2].PublicKey()})
+	assert.Nil(t, err)
+
+	tx := NewTransferTransaction(types.Address{1}, 10)
+	assert.Nil(t, tx.SignMultisig(keys[2], account))
+	assert.NotNil(t, tx.Verify())
+
+	assert.Nil(t, tx.SignMultisig(keys[0], account))
+	assert.Nil(t, tx.Verify())
+	assert.Equal(t, account.Address(), tx.Sender())
+
+	// 非成员不能签名 // Non members cannot sign
+	assert.NotNil(t, tx.SignMultisig(crypto.GeneratePrivateKey(), account))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, tx.Encode(NewProtobufTxEncoder(buf)))
+	txDecoded := new(Transaction)
+	assert.Nil(t, txDecoded.Decode(NewProtobufTxDecoder(buf)))
+	assert.Nil(t, txDecoded.Verify())
+	assert.Equal(t, tx.Sender(), txDecoded.Sender())
+
+	// 修改交易会使多签失效 // Changing the transaction invalidates the multisig
+	txDecoded.Value++
+	assert.NotNil(t, txDecoded.Verify())
+}
+
+// TestTxDecodeInvalidSignature 测试解码签名长度错误的交易返回错误
+// TestTxDecodeInvalidSignature tests that decoding a transaction with a wrongly sized signature returns an error
+func TestTxDecodeInvalidSignature(t *testing.T) {
+	pbTx := txToProto(randomTxWithSignature(t))
+	pbTx.Signature = pbTx.Signature[:40]
+
+	tx := new(Transaction)
+	assert.NotNil(t, txFromProto(pbTx, tx))
+}
+
 // TestTxEncodeDecode 测试交易的编码和解码
 // TestTxEncodeDecode tests the encoding and decoding of a transaction
 func TestTxEncodeDecode(t *testing.T) {
@@ -52,9 +185,9 @@ func TestTxEncodeDecode(t *testing.T) {
 	// Encode the transaction
 	assert.Nil(t, tx.Encode(NewProtobufTxEncoder(buf)))
 
-	// 清空交易哈希
-	// Clear the transaction hash
-	tx.hash = types.Hash{}
+	// 解码时会重新计算交易哈希
+	// The transaction hash is recomputed on decoding
+	tx.Hash(TxHasher{})
 
 	txDecoded := new(Transaction)
 
@@ -67,13 +200,60 @@ func TestTxEncodeDecode(t *testing.T) {
 	assert.Equal(t, tx, txDecoded)
 }
 
+// TestTxDecodeIgnoresWireHash 测试解码时重新计算交易哈希，而不是使用编码中的哈希
+// TestTxDecodeIgnoresWireHash tests that decoding recomputes the transaction hash instead of using the one in the encoding
+func TestTxDecodeIgnoresWireHash(t *testing.T) {
+	tx := randomTxWithSignature(t)
+	pbTx := txToProto(tx)
+	pbTx.Hash = types.RandomHash().ToSlice()
+
+	txDecoded := new(Transaction)
+	assert.Nil(t, txFromProto(pbTx, txDecoded))
+	assert.Equal(t, TxHasher{}.Hash(tx), txDecoded.Hash(TxHasher{}))
+}
+
+// TestTxRecoverableSender 测试可恢复签名的交易在编码中省略发送方公钥，解码时恢复，并校验可选的发送方地址
+// TestTxRecoverableSender tests that transactions with a recoverable signature omit the sender's public key from the encoding, recover it on decoding and check the optional sender address
+func TestTxRecoverableSender(t *testing.T) {
+	privateKey, err := crypto.GenerateKey(crypto.KeyTypeSecp256k1)
+	assert.Nil(t, err)
+	tx := NewTransferTransaction(types.Address{1}, 10)
+	tx.ChainID = 1
+	assert.Nil(t, tx.SignRecoverable(privateKey))
+	assert.Nil(t, tx.Verify())
+
+	pbTx := txToProto(tx)
+	assert.Empty(t, pbTx.From)
+
+	decoded := new(Transaction)
+	assert.Nil(t, txFromProto(pbTx, decoded))
+	assert.Equal(t, privateKey.PublicKey(), decoded.From)
+	assert.Equal(t, tx.Hash(TxHasher{}), decoded.Hash(TxHasher{}))
+	assert.Nil(t, decoded.Verify())
+
+	// 发送方地址不一致时验证失败 // Verification fails when the sender address does not match
+	address := crypto.GeneratePrivateKey().PublicKey().Address()
+	decoded.FromAddress = &address
+	assert.NotNil(t, decoded.Verify())
+	address = privateKey.PublicKey().Address()
+	assert.Nil(t, decoded.Verify())
+
+	// 修改已签名的字段会恢复出其他发送方 // Changing a signed field recovers another sender
+	pbTx.Value = 11
+	tampered := new(Transaction)
+	if txFromProto(pbTx, tampered) == nil {
+		assert.NotEqual(t, tx.Sender(), tampered.Sender())
+	}
+
+	// 不支持恢复的算法无法使用可恢复签名 // Schemes without recovery cannot sign recoverably
+	assert.NotNil(t, NewTransferTransaction(types.Address{1}, 10).SignRecoverable(crypto.GeneratePrivateKey()))
+}
+
 // randomTxWithSignature 创建一个带签名的随机交易
 // randomTxWithSignature creates a random transaction with a signature
 func randomTxWithSignature(t *testing.T) *Transaction {
 	privateKey := crypto.GeneratePrivateKey()
-	tx := Transaction{
-		Data: []byte("foo"),
-	}
+	tx := NewDeployTransaction([]byte("foo"))
 	assert.Nil(t, tx.Sign(privateKey))
-	return &tx
+	return tx
 }
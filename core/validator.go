@@ -20,8 +20,8 @@ func NewBlockValidator(bc *Blockchain) *BlockValidator {
 	return &BlockValidator{bc: bc}
 }
 
-// ValidateBlock 方法验证区块的有效性
-// ValidateBlock method validates the validity of the block
+// ValidateBlock 方法验证区块的有效性，交易的执行结果由导入区块时的唯一一次执行检查
+// ValidateBlock method validates the validity of the block, the outcome of the transactions is checked by the single execution when the block is imported
 func (v *BlockValidator) ValidateBlock(b *Block) error {
 	// 检查区块链中是否已经包含该高度的区块
 	// Check if the blockchain already contains a block at this height
@@ -35,6 +35,24 @@ func (v *BlockValidator) ValidateBlock(b *Block) error {
 		return fmt.Errorf("block (%s) with height (%d) is too high => current height(%d)", b.Hash(BlockHasher{}), b.Height, v.bc.Height())
 	}
 
+	// 检查区块头版本，规范编码只对支持的版本有定义
+	// Check the header version, the canonical encoding is only defined for supported versions
+	if b.Version != HeaderVersion {
+		return fmt.Errorf("block (%s) has unsupported header version %d", b.Hash(BlockHasher{}), b.Version)
+	}
+
+	// 检查区块和交易是否属于本链
+	// Check if the block and its transactions belong to this chain
+	chainID := v.bc.ChainID()
+	if b.ChainID != chainID {
+		return fmt.Errorf("block (%s) has chain id (%d) => expected (%d)", b.Hash(BlockHasher{}), b.ChainID, chainID)
+	}
+	for _, tx := range b.Transactions {
+		if tx.ChainID != chainID {
+			return fmt.Errorf("transaction (%s) has chain id (%d) => expected (%d)", tx.Hash(TxHasher{}), tx.ChainID, chainID)
+		}
+	}
+
 	// 获取前一个区块头
 	// Get the previous block header
 	prevHeader, err := v.bc.GetHeader(b.Height - 1)
@@ -51,9 +69,15 @@ func (v *BlockValidator) ValidateBlock(b *Block) error {
 
 	// 验证区块签名和交易
 	// Verify the block signature and transactions
-	if err := b.Verify(); err != nil {
+	if err := b.VerifyCached(v.bc.SigCache()); err != nil {
 		return err
 	}
 
+	// 验证者集合不为空时，只有集合中的验证者可以出块
+	// When the validator set is not empty only its members may propose blocks
+	if v.bc.ValidatorCount() > 0 && !v.bc.IsValidator(b.Validator.Address()) {
+		return fmt.Errorf("block (%s) proposed by (%s) which is not a validator", b.Hash(BlockHasher{}), b.Validator.Address())
+	}
+
 	return nil
 }
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"sort"
+	"sync"
+)
+
+// ValidatorSet 结构体表示允许出块的验证者集合
+// ValidatorSet struct represents the set of validators allowed to propose blocks
+type ValidatorSet struct {
+	lock       sync.RWMutex                       // 读写锁 // Read-write lock
+	validators map[types.Address]crypto.PublicKey // 地址到验证者公钥的映射 // Map from address to validator public key
+}
+
+// NewValidatorSet 创建一个新的 ValidatorSet 实例
+// NewValidatorSet creates a new instance of ValidatorSet
+func NewValidatorSet() *ValidatorSet {
+	return &ValidatorSet{
+		validators: make(map[types.Address]crypto.PublicKey),
+	}
+}
+
+// Add 将验证者加入集合
+// Add adds a validator to the set
+func (vs *ValidatorSet) Add(publicKey crypto.PublicKey) {
+	vs.lock.Lock()
+	defer vs.lock.Unlock()
+
+	vs.validators[publicKey.Address()] = publicKey
+}
+
+// Remove 将验证者移出集合，集合不能被清空
+// Remove removes a validator from the set, the set cannot become empty
+func (vs *ValidatorSet) Remove(address types.Address) error {
+	vs.lock.Lock()
+	defer vs.lock.Unlock()
+
+	if _, ok := vs.validators[address]; !ok {
+		return fmt.Errorf("validator (%s) not found", address)
+	}
+	if len(vs.validators) == 1 {
+		return fmt.Errorf("cannot remove the last validator (%s)", address)
+	}
+	delete(vs.validators, address)
+	return nil
+}
+
+// Contains 检查地址是否属于验证者集合
+// Contains checks if the address belongs to the validator set
+func (vs *ValidatorSet) Contains(address types.Address) bool {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+
+	_, ok := vs.validators[address]
+	return ok
+}
+
+// Len 返回验证者的数量
+// Len returns the number of validators
+func (vs *ValidatorSet) Len() int {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+
+	return len(vs.validators)
+}
+
+// Addresses 返回按字节序排序的验证者地址
+// Addresses returns the validator addresses sorted by bytes
+func (vs *ValidatorSet) Addresses() []types.Address {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+
+	addrs := make([]types.Address, 0, len(vs.validators))
+	for addr := range vs.validators {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
+
+// Copy 返回验证者集合的拷贝
+// Copy returns a copy of the validator set
+func (vs *ValidatorSet) Copy() *ValidatorSet {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+
+	cp := NewValidatorSet()
+	for addr, publicKey := range vs.validators {
+		cp.validators[addr] = publicKey
+	}
+	return cp
+}
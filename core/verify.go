@@ -0,0 +1,87 @@
+package core
+
+import (
+	"github.com/lonySp/go-blockchain/crypto"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// verifyTransactions 函数验证一组交易的签名，跳过缓存中的交易，能批量验证的签名先批量验证，其余交易由 GOMAXPROCS 个工作协程并行验证，遇到第一个错误即停止
+// verifyTransactions function verifies the signatures of a set of transactions, skipping cached ones, batchable signatures are verified in one batch first and the rest are verified in parallel by GOMAXPROCS workers, stopping at the first error
+func verifyTransactions(txx []*Transaction, cache *SigCache) error {
+	var (
+		batch   = crypto.NewBatchVerifier()
+		batched []*Transaction
+		pending []*Transaction
+	)
+	for _, tx := range txx {
+		if cache.Contains(tx.verificationHash()) {
+			continue
+		}
+		if tx.Multisig == nil && tx.verifyFromAddress() == nil {
+			digest := tx.SigningHash()
+			if batch.Add(tx.From, digest[:], tx.Signature) {
+				batched = append(batched, tx)
+				continue
+			}
+		}
+		pending = append(pending, tx)
+	}
+
+	if batch.Len() > 0 {
+		if batch.Verify() {
+			for _, tx := range batched {
+				cache.Add(tx.verificationHash())
+			}
+		} else {
+			// 批量验证失败时逐个验证以找到无效的交易 // When the batch fails verify one by one to find the invalid transaction
+			pending = append(pending, batched...)
+		}
+	}
+	return verifyParallel(pending, cache)
+}
+
+// verifyParallel 函数使用工作协程池并行验证交易签名，返回第一个观察到的错误
+// verifyParallel function verifies transaction signatures in parallel with a worker pool, returning the first error observed
+func verifyParallel(txx []*Transaction, cache *SigCache) error {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(txx) {
+		workers = len(txx)
+	}
+	if workers <= 1 {
+		for _, tx := range txx {
+			if err := tx.VerifyCached(cache); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		wg       sync.WaitGroup
+		next     atomic.Int64 // 下一个待验证交易的索引 // Index of the next transaction to verify
+		failed   atomic.Bool  // 是否已有交易验证失败 // Whether a transaction has failed verification
+		once     sync.Once
+		firstErr error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= len(txx) {
+					return
+				}
+				if err := txx[i].VerifyCached(cache); err != nil {
+					once.Do(func() { firstErr = err })
+					failed.Store(true)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,74 @@
+package crypto
+
+// BatchScheme 接口定义了支持批量验证的签名算法
+// BatchScheme interface defines a signature scheme that supports batch verification
+type BatchScheme interface {
+	Scheme
+	// NewBatch 创建一个空的批量验证器 // NewBatch creates an empty batch verifier
+	NewBatch() Batch
+}
+
+// Batch 接口定义了单一算法的批量验证器，使用原始公钥和签名
+// Batch interface defines the batch verifier of a single scheme, working on raw public keys and signatures
+type Batch interface {
+	// Add 加入一个待验证的签名 // Add adds a signature to verify
+	Add(publicKey, data, sig []byte)
+	// Verify 当且仅当所有签名有效时返回 true // Verify returns true if and only if every signature is valid
+	Verify() bool
+}
+
+// BatchVerifier 结构体收集不同算法的签名，对支持批量验证的算法一次性验证
+// BatchVerifier struct collects signatures of different schemes and verifies those whose scheme supports batching in one go
+type BatchVerifier struct {
+	batches map[KeyType]Batch // 每种算法的批量验证器 // Batch verifier of each scheme
+	size    int               // 已加入的签名数 // Number of signatures added
+}
+
+// NewBatchVerifier 函数创建一个空的批量验证器
+// NewBatchVerifier function creates an empty batch verifier
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{batches: make(map[KeyType]Batch)}
+}
+
+// Add 方法加入一个待验证的签名，算法不支持批量验证时返回 false，调用方需要单独验证该签名
+// Add method adds a signature to verify, it returns false when the scheme does not support batching and the caller has to verify the signature on its own
+func (v *BatchVerifier) Add(publicKey PublicKey, data []byte, sig *Signature) bool {
+	// 可恢复签名需要校验恢复 ID，不能批量验证 // Recoverable signatures need their recovery id checked and cannot be batched
+	if sig == nil || publicKey.IsZero() || sig.Type != publicKey.Type || sig.Recoverable() {
+		return false
+	}
+	scheme, err := SchemeOf(sig.Type)
+	if err != nil {
+		return false
+	}
+	batchScheme, ok := scheme.(BatchScheme)
+	if !ok {
+		return false
+	}
+
+	batch, ok := v.batches[sig.Type]
+	if !ok {
+		batch = batchScheme.NewBatch()
+		v.batches[sig.Type] = batch
+	}
+	batch.Add(publicKey.Key, data, sig.Data)
+	v.size++
+	return true
+}
+
+// Len 方法返回已加入的签名数
+// Len method returns the number of signatures added
+func (v *BatchVerifier) Len() int {
+	return v.size
+}
+
+// Verify 方法验证所有已加入的签名，失败时无法得知哪个签名无效，调用方需要逐个验证
+// Verify method verifies every signature added, on failure it is unknown which signature is invalid and the caller has to verify them one by one
+func (v *BatchVerifier) Verify() bool {
+	for _, batch := range v.batches {
+		if !batch.Verify() {
+			return false
+		}
+	}
+	return true
+}
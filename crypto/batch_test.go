@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestBatchVerifier 测试批量验证多个签名，只接受支持批量验证的算法
+// TestBatchVerifier tests verifying many signatures in a batch, only accepting schemes that support batching
+func TestBatchVerifier(t *testing.T) {
+	msg := []byte("hello world")
+	batch := NewBatchVerifier()
+	var keys []PublicKey
+	var sigs []*Signature
+	for i := 0; i < 16; i++ {
+		privateKey, err := GenerateKey(KeyTypeEd25519)
+		assert.Nil(t, err)
+		signature, err := privateKey.Sign(msg)
+		assert.Nil(t, err)
+		assert.True(t, batch.Add(privateKey.PublicKey(), msg, signature))
+		keys = append(keys, privateKey.PublicKey())
+		sigs = append(sigs, signature)
+	}
+	assert.Equal(t, 16, batch.Len())
+	assert.True(t, batch.Verify())
+
+	// 不支持批量验证的算法和可恢复签名 // Schemes without batching and recoverable signatures
+	p256 := GeneratePrivateKey()
+	signature, err := p256.Sign(msg)
+	assert.Nil(t, err)
+	assert.False(t, batch.Add(p256.PublicKey(), msg, signature))
+	secp, err := GenerateKey(KeyTypeSecp256k1)
+	assert.Nil(t, err)
+	signature, err = secp.SignRecoverable(msg)
+	assert.Nil(t, err)
+	assert.False(t, batch.Add(secp.PublicKey(), msg, signature))
+	assert.False(t, batch.Add(keys[0], msg, nil))
+
+	// 一个无效签名导致整批失败 // One invalid signature fails the whole batch
+	bad := NewBatchVerifier()
+	for i := range keys {
+		data := msg
+		if i == 7 {
+			data = []byte("xxxxxx")
+		}
+		bad.Add(keys[i], data, sigs[i])
+	}
+	assert.False(t, bad.Verify())
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// ed25519Scheme 实现 Ed25519，私钥为 32 字节种子，公钥为 32 字节，签名为 64 字节
+// ed25519Scheme implements Ed25519, private keys are 32 byte seeds, public keys are 32 bytes and signatures are 64 bytes
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) Type() KeyType { return KeyTypeEd25519 }
+
+func (ed25519Scheme) GenerateKey() ([]byte, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return key.Seed(), nil
+}
+
+func (ed25519Scheme) PublicKey(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key length %d, expected %d", len(privateKey), ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey), nil
+}
+
+// Sign 方法对数据本身签名，Ed25519 签名是确定性的
+// Sign method signs the data itself, Ed25519 signatures are deterministic
+func (ed25519Scheme) Sign(privateKey, data []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key length %d, expected %d", len(privateKey), ed25519.SeedSize)
+	}
+	return ed25519.Sign(ed25519.NewKeyFromSeed(privateKey), data), nil
+}
+
+// Verify 方法按 ZIP-215 规则验证签名，单个验证和批量验证接受的签名完全相同，S 不小于群阶的签名会被拒绝
+// Verify method verifies the signature under the ZIP-215 rules, so single and batch verification accept exactly the same signatures, signatures whose S is not below the group order are rejected
+func (s ed25519Scheme) Verify(publicKey, data, sig []byte) bool {
+	if s.ValidatePublicKey(publicKey) != nil || s.ValidateSignature(sig) != nil {
+		return false
+	}
+	return ed25519consensus.Verify(publicKey, data, sig)
+}
+
+// NewBatch 方法创建 Ed25519 批量验证器
+// NewBatch method creates an Ed25519 batch verifier
+func (ed25519Scheme) NewBatch() Batch {
+	return &ed25519Batch{verifier: ed25519consensus.NewBatchVerifier()}
+}
+
+// ed25519Batch 结构体使用随机线性组合一次验证多个 Ed25519 签名
+// ed25519Batch struct verifies many Ed25519 signatures at once with a random linear combination
+type ed25519Batch struct {
+	verifier ed25519consensus.BatchVerifier
+}
+
+func (b *ed25519Batch) Add(publicKey, data, sig []byte) {
+	b.verifier.Add(publicKey, data, sig)
+}
+
+func (b *ed25519Batch) Verify() bool {
+	return b.verifier.Verify()
+}
+
+func (ed25519Scheme) ValidatePublicKey(publicKey []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key length %d", len(publicKey))
+	}
+	return nil
+}
+
+func (ed25519Scheme) ValidateSignature(sig []byte) error {
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid ed25519 signature length %d", len(sig))
+	}
+	return nil
+}
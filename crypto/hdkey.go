@@ -0,0 +1,190 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart 是强化派生的起始索引，强化子密钥无法从父公钥推导
+// HardenedKeyStart is the first hardened child index, hardened children cannot be derived from the parent public key
+const HardenedKeyStart uint32 = 0x80000000
+
+// hdCurveSeeds 是 SLIP-10 中各曲线主密钥派生使用的 HMAC 密钥
+// hdCurveSeeds are the HMAC keys of the SLIP-10 master key derivation for each curve
+var hdCurveSeeds = map[KeyType]string{
+	KeyTypeSecp256k1: "Bitcoin seed",
+	KeyTypeP256:      "Nist256p1 seed",
+	KeyTypeEd25519:   "ed25519 seed",
+}
+
+// hdCurveOrders 是 ECDSA 曲线的阶，Ed25519 不需要 // hdCurveOrders are the orders of the ECDSA curves, Ed25519 needs none
+var hdCurveOrders = map[KeyType]*big.Int{
+	KeyTypeSecp256k1: secp256k1.S256().Params().N,
+	KeyTypeP256:      curveOrder,
+}
+
+// ExtendedKey 结构体表示 SLIP-10 分层确定性私钥，secp256k1 的派生与 BIP-32 一致
+// ExtendedKey struct represents a SLIP-10 hierarchical deterministic private key, secp256k1 derivation matches BIP-32
+type ExtendedKey struct {
+	keyType   KeyType
+	key       []byte // 32 字节私钥 // 32 byte private key
+	chainCode []byte // 32 字节链码 // 32 byte chain code
+	depth     uint8  // 派生深度，主密钥为 0 // Derivation depth, 0 for the master key
+	index     uint32 // 子密钥索引 // Child index
+}
+
+// NewMasterKey 函数从种子派生指定曲线的主密钥，种子长度为 16 到 64 字节
+// NewMasterKey function derives the master key of the given curve from the seed, the seed is 16 to 64 bytes
+func NewMasterKey(t KeyType, seed []byte) (*ExtendedKey, error) {
+	curveSeed, ok := hdCurveSeeds[t]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %d", uint8(t))
+	}
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %d", len(seed))
+	}
+
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, []byte(curveSeed))
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		// 私钥无效时以 I 作为新数据重试 // Retry with I as the new data when the key is invalid
+		if order := hdCurveOrders[t]; order == nil || validScalar(sum[:32], order) {
+			return &ExtendedKey{keyType: t, key: sum[:32], chainCode: sum[32:]}, nil
+		}
+		data = sum
+	}
+}
+
+// Child 方法派生指定索引的子密钥，Ed25519 只支持强化派生
+// Child method derives the child key at the given index, Ed25519 only supports hardened derivation
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	hardened := index >= HardenedKeyStart
+	if k.keyType == KeyTypeEd25519 && !hardened {
+		return nil, fmt.Errorf("ed25519 only supports hardened derivation")
+	}
+	if k.depth == 255 {
+		return nil, fmt.Errorf("maximum derivation depth reached")
+	}
+
+	var data []byte
+	if hardened {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		scheme, err := SchemeOf(k.keyType)
+		if err != nil {
+			return nil, err
+		}
+		// 压缩公钥 // Compressed public key
+		publicKey, err := scheme.PublicKey(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = publicKey
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	order := hdCurveOrders[k.keyType]
+	for {
+		mac := hmac.New(sha512.New, k.chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		child := &ExtendedKey{keyType: k.keyType, chainCode: sum[32:], depth: k.depth + 1, index: index}
+		if order == nil {
+			child.key = sum[:32]
+			return child, nil
+		}
+
+		// k_i = I_L + k_par (mod n)
+		if validScalar(sum[:32], order) {
+			key := new(big.Int).SetBytes(sum[:32])
+			key.Add(key, new(big.Int).SetBytes(k.key)).Mod(key, order)
+			if key.Sign() != 0 {
+				child.key = key.FillBytes(make([]byte, 32))
+				return child, nil
+			}
+		}
+		// 子密钥无效时按 SLIP-10 以 0x01 || I_R || index 重试 // Retry with 0x01 || I_R || index as SLIP-10 specifies when the child is invalid
+		data = binary.BigEndian.AppendUint32(append([]byte{0x01}, sum[32:]...), index)
+	}
+}
+
+// Derive 方法沿派生路径派生子密钥，例如 m/44'/0'/0'/0/0
+// Derive method derives the child key along the derivation path, for example m/44'/0'/0'/0/0
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey 方法返回扩展密钥对应的私钥
+// PrivateKey method returns the private key of the extended key
+func (k *ExtendedKey) PrivateKey() (PrivateKey, error) {
+	return NewPrivateKey(k.keyType, k.key)
+}
+
+// ChainCode 方法返回链码
+// ChainCode method returns the chain code
+func (k *ExtendedKey) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode...)
+}
+
+// Depth 方法返回派生深度
+// Depth method returns the derivation depth
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// Index 方法返回子密钥索引
+// Index method returns the child index
+func (k *ExtendedKey) Index() uint32 {
+	return k.index
+}
+
+// ParseDerivationPath 函数解析以 m 开头的派生路径，以 ' 或 h 结尾的分量表示强化派生
+// ParseDerivationPath function parses a derivation path starting with m, components ending in ' or h are hardened
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path %q must start with m", path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path component %q in %q", part, path)
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// validScalar 函数检查标量是否在 [1, n) 范围内
+// validScalar function checks that the scalar is within [1, n)
+func validScalar(data []byte, order *big.Int) bool {
+	scalar := new(big.Int).SetBytes(data)
+	return scalar.Sign() > 0 && scalar.Cmp(order) < 0
+}
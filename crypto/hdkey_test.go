@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestHDKeyVectors 测试 SLIP-10 测试向量 1，secp256k1 的结果与 BIP-32 一致
+// TestHDKeyVectors tests SLIP-10 test vector 1, the secp256k1 results match BIP-32
+func TestHDKeyVectors(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	vectors := []struct {
+		keyType   KeyType
+		path      string
+		chainCode string
+		key       string
+	}{
+		{KeyTypeSecp256k1, "m", "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{KeyTypeSecp256k1, "m/0'", "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{KeyTypeSecp256k1, "m/0'/1", "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{KeyTypeP256, "m", "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2"},
+		{KeyTypeP256, "m/0'", "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c"},
+		{KeyTypeEd25519, "m", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{KeyTypeEd25519, "m/0'", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+	}
+
+	for _, v := range vectors {
+		master, err := NewMasterKey(v.keyType, seed)
+		assert.Nil(t, err)
+		key, err := master.Derive(v.path)
+		assert.Nil(t, err)
+		assert.Equal(t, v.chainCode, hex.EncodeToString(key.ChainCode()), v.path)
+
+		privateKey, err := key.PrivateKey()
+		assert.Nil(t, err)
+		assert.Equal(t, v.key, hex.EncodeToString(privateKey.Bytes()), v.path)
+		assert.Equal(t, v.keyType, privateKey.Type())
+	}
+}
+
+// TestHDKeyDerivation 测试派生路径解析和非强化派生的限制
+// TestHDKeyDerivation tests derivation path parsing and the restrictions on non-hardened derivation
+func TestHDKeyDerivation(t *testing.T) {
+	indexes, err := ParseDerivationPath("m/44'/0h/1")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{HardenedKeyStart + 44, HardenedKeyStart, 1}, indexes)
+
+	for _, path := range []string{"", "44'/0", "m/x", "m/2147483648", "m//1"} {
+		_, err := ParseDerivationPath(path)
+		assert.NotNil(t, err, path)
+	}
+
+	seed := make([]byte, MnemonicSeedLength)
+	master, err := NewMasterKey(KeyTypeEd25519, seed)
+	assert.Nil(t, err)
+	_, err = master.Child(0)
+	assert.NotNil(t, err)
+
+	// 不同路径得到不同的账户 // Different paths give different accounts
+	master, err = NewMasterKey(KeyTypeSecp256k1, seed)
+	assert.Nil(t, err)
+	a, err := master.Derive("m/44'/0'/0'/0/0")
+	assert.Nil(t, err)
+	b, err := master.Derive("m/44'/0'/0'/0/1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(5), a.Depth())
+	assert.Equal(t, uint32(1), b.Index())
+	keyA, _ := a.PrivateKey()
+	keyB, _ := b.PrivateKey()
+	assert.NotEqual(t, keyA.PublicKey().Address(), keyB.PublicKey().Address())
+
+	_, err = NewMasterKey(KeyTypeSecp256k1, seed[:8])
+	assert.NotNil(t, err)
+}
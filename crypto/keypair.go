@@ -1,93 +1,255 @@
 package crypto
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
+	"bytes"
 	"crypto/sha256"
+	"fmt"
 	"github.com/lonySp/go-blockchain/types"
-	"math/big"
+)
+
+// SignatureLength 是签名编码的固定长度：1 字节算法标签加 64 字节签名
+// SignatureLength is the fixed length of the signature encoding: a 1 byte scheme tag followed by the 64 byte signature
+const SignatureLength = 1 + signatureSize
+
+// RecoverableSignatureLength 是可恢复签名编码的固定长度，在签名后附加 1 字节恢复 ID
+// RecoverableSignatureLength is the fixed length of the recoverable signature encoding, a 1 byte recovery id follows the signature
+const RecoverableSignatureLength = SignatureLength + 1
+
+const (
+	signatureSize            = 64                // 所有算法原始签名的长度 // Raw signature length of every scheme
+	recoverableSignatureSize = signatureSize + 1 // 带恢复 ID 的原始签名长度 // Raw signature length with the recovery id
 )
 
 // PrivateKey 结构体表示一个私钥
 // PrivateKey struct represents a private key
 type PrivateKey struct {
-	key *ecdsa.PrivateKey
+	scheme    Scheme // 签名算法 // Signature scheme
+	key       []byte // 原始私钥 // Raw private key
+	publicKey []byte // 原始公钥 // Raw public key
 }
 
-// Sign 方法使用私钥对数据进行签名
-// Sign method signs the data using the private key
+// Sign 方法使用私钥对数据进行签名，返回规范签名
+// Sign method signs the data using the private key and returns a canonical signature
 func (k PrivateKey) Sign(data []byte) (*Signature, error) {
-	r, s, err := ecdsa.Sign(rand.Reader, k.key, data)
+	if k.scheme == nil {
+		return nil, fmt.Errorf("empty private key")
+	}
+	sig, err := k.scheme.Sign(k.key, data)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return &Signature{r, s}, nil
+	return &Signature{Type: k.scheme.Type(), Data: sig}, nil
 }
 
-// GeneratePrivateKey 函数生成一个新的私钥
-// GeneratePrivateKey function generates a new private key
+// SignRecoverable 方法生成可恢复公钥的签名，只有 secp256k1 支持
+// SignRecoverable method produces a signature the public key can be recovered from, only secp256k1 supports it
+func (k PrivateKey) SignRecoverable(data []byte) (*Signature, error) {
+	scheme, ok := k.scheme.(RecoverableScheme)
+	if !ok {
+		return nil, fmt.Errorf("key type %s does not support recoverable signatures", k.Type())
+	}
+	sig, err := scheme.SignRecoverable(k.key, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{Type: scheme.Type(), Data: sig}, nil
+}
+
+// GeneratePrivateKey 函数生成一个新的 P-256 私钥
+// GeneratePrivateKey function generates a new P-256 private key
 func GeneratePrivateKey() PrivateKey {
-	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key, err := GenerateKey(KeyTypeP256)
 	if err != nil {
 		panic(err)
 	}
-	return PrivateKey{key}
+	return key
+}
+
+// GenerateKey 函数使用指定的签名算法生成一个新的私钥
+// GenerateKey function generates a new private key of the given signature scheme
+func GenerateKey(t KeyType) (PrivateKey, error) {
+	scheme, err := SchemeOf(t)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	data, err := scheme.GenerateKey()
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	return NewPrivateKey(t, data)
+}
+
+// PrivateKeyLength 是私钥编码的固定长度，所有算法均为 32 字节
+// PrivateKeyLength is the fixed length of the private key encoding, 32 bytes for every scheme
+const PrivateKeyLength = 32
+
+// Type 方法返回私钥的签名算法
+// Type method returns the signature scheme of the private key
+func (k PrivateKey) Type() KeyType {
+	if k.scheme == nil {
+		return 0
+	}
+	return k.scheme.Type()
+}
+
+// Bytes 方法返回固定 32 字节的原始私钥，不包含算法标签
+// Bytes method returns the fixed 32 byte raw private key, without the scheme tag
+func (k PrivateKey) Bytes() []byte {
+	return append([]byte(nil), k.key...)
+}
+
+// PrivateKeyFromBytes 函数从 32 字节的标量解析 P-256 私钥
+// PrivateKeyFromBytes function parses a P-256 private key from its 32 byte scalar
+func PrivateKeyFromBytes(data []byte) (PrivateKey, error) {
+	return NewPrivateKey(KeyTypeP256, data)
+}
+
+// NewPrivateKey 函数解析指定签名算法的 32 字节原始私钥
+// NewPrivateKey function parses the 32 byte raw private key of the given signature scheme
+func NewPrivateKey(t KeyType, data []byte) (PrivateKey, error) {
+	scheme, err := SchemeOf(t)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	if len(data) != PrivateKeyLength {
+		return PrivateKey{}, fmt.Errorf("invalid private key length %d, expected %d", len(data), PrivateKeyLength)
+	}
+	publicKey, err := scheme.PublicKey(data)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	return PrivateKey{
+		scheme:    scheme,
+		key:       append([]byte(nil), data...),
+		publicKey: publicKey,
+	}, nil
 }
 
 // PublicKey 方法返回与私钥对应的公钥
 // PublicKey method returns the public key corresponding to the private key
 func (k PrivateKey) PublicKey() PublicKey {
-	return PublicKey{Key: &k.key.PublicKey}
+	if k.scheme == nil {
+		return PublicKey{}
+	}
+	return PublicKey{Type: k.scheme.Type(), Key: k.publicKey}
 }
 
-// PublicKey 结构体表示一个公钥
-// PublicKey struct represents a public key
+// PublicKey 结构体表示一个带算法标签的公钥
+// PublicKey struct represents a public key tagged with its signature scheme
 type PublicKey struct {
-	Key *ecdsa.PublicKey
+	Type KeyType // 签名算法 // Signature scheme
+	Key  []byte  // 原始公钥 // Raw public key
 }
 
-// ToSlice 方法将公钥转换为字节切片
-// ToSlice method converts the public key to a byte slice
+// IsZero 方法检查公钥是否为空
+// IsZero method checks whether the public key is empty
+func (k PublicKey) IsZero() bool {
+	return len(k.Key) == 0
+}
+
+// ToSlice 方法将公钥编码为算法标签加原始公钥
+// ToSlice method encodes the public key as the scheme tag followed by the raw public key
 func (k PublicKey) ToSlice() []byte {
-	return elliptic.MarshalCompressed(k.Key, k.Key.X, k.Key.Y)
+	// 空公钥编码为空字节 // An empty public key encodes to empty bytes
+	if k.IsZero() {
+		return nil
+	}
+	return append([]byte{byte(k.Type)}, k.Key...)
 }
 
-// PublicKeyFromBytes 方法从字节数组生成公钥
-func PublicKeyFromBytes(data []byte) PublicKey {
-	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), data)
-	return PublicKey{Key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}
+// PublicKeyFromBytes 函数从带标签的编码解析公钥，空字节解析为空公钥
+// PublicKeyFromBytes function parses a public key from its tagged encoding, empty bytes parse to an empty public key
+func PublicKeyFromBytes(data []byte) (PublicKey, error) {
+	if len(data) == 0 {
+		return PublicKey{}, nil
+	}
+	scheme, err := SchemeOf(KeyType(data[0]))
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if err := scheme.ValidatePublicKey(data[1:]); err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{Type: scheme.Type(), Key: append([]byte(nil), data[1:]...)}, nil
 }
 
-// Address 方法生成与公钥对应的地址
-// Address method generates the address corresponding to the public key
+// Address 方法生成与公钥对应的地址，地址覆盖算法标签，不同算法的相同字节得到不同地址
+// Address method generates the address corresponding to the public key, it covers the scheme tag so equal bytes of different schemes give different addresses
 func (k PublicKey) Address() types.Address {
 	h := sha256.Sum256(k.ToSlice())
 	return types.NewAddressFromBytes(h[len(h)-20:])
 }
 
-// Signature 结构体表示一个签名
-// Signature struct represents a signature
+// Signature 结构体表示一个带算法标签的签名
+// Signature struct represents a signature tagged with its signature scheme
 type Signature struct {
-	R, S *big.Int
+	Type KeyType // 签名算法 // Signature scheme
+	Data []byte  // 原始签名 // Raw signature
 }
 
-// Verify 方法验证签名是否有效
-// Verify method verifies if the signature is valid
+// Verify 方法按算法标签验证签名，签名与公钥的算法不一致或签名不规范时视为无效
+// Verify method verifies the signature according to its scheme tag, it is invalid when its scheme differs from the public key's or when it is not canonical
 func (sig Signature) Verify(publicKey PublicKey, data []byte) bool {
-	return ecdsa.Verify(publicKey.Key, data, sig.R, sig.S)
+	if publicKey.IsZero() || sig.Type != publicKey.Type {
+		return false
+	}
+	// 可恢复签名的恢复 ID 也必须正确 // The recovery id of a recoverable signature has to be correct as well
+	if sig.Recoverable() {
+		recovered, err := sig.RecoverPublicKey(data)
+		return err == nil && bytes.Equal(recovered.Key, publicKey.Key)
+	}
+	scheme, err := SchemeOf(sig.Type)
+	if err != nil {
+		return false
+	}
+	return scheme.Verify(publicKey.Key, data, sig.Data)
 }
 
-// SignatureFromBytes 方法从字节数组生成签名
-func SignatureFromBytes(data []byte) *Signature {
-	r := new(big.Int).SetBytes(data[:32])
-	s := new(big.Int).SetBytes(data[32:])
-	return &Signature{R: r, S: s}
+// Recoverable 方法检查签名是否带有恢复 ID
+// Recoverable method checks whether the signature carries a recovery id
+func (sig Signature) Recoverable() bool {
+	return len(sig.Data) == recoverableSignatureSize
+}
+
+// RecoverPublicKey 方法从可恢复签名和数据恢复签名者的公钥，签名无效时返回错误
+// RecoverPublicKey method recovers the signer's public key from a recoverable signature and the data, returning an error when the signature is invalid
+func (sig Signature) RecoverPublicKey(data []byte) (PublicKey, error) {
+	if !sig.Recoverable() {
+		return PublicKey{}, fmt.Errorf("signature is not recoverable")
+	}
+	scheme, err := SchemeOf(sig.Type)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	recoverable, ok := scheme.(RecoverableScheme)
+	if !ok {
+		return PublicKey{}, fmt.Errorf("key type %s does not support recoverable signatures", sig.Type)
+	}
+	key, err := recoverable.RecoverPublicKey(data, sig.Data)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{Type: sig.Type, Key: key}, nil
+}
+
+// SignatureFromBytes 方法从 65 字节的规范编码或 66 字节的可恢复编码解析签名，拒绝长度错误、未知算法和不规范的签名
+// SignatureFromBytes method parses a signature from its 65 byte canonical encoding or 66 byte recoverable encoding, rejecting wrong lengths, unknown schemes and non-canonical signatures
+func SignatureFromBytes(data []byte) (*Signature, error) {
+	if len(data) != SignatureLength && len(data) != RecoverableSignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d, expected %d or %d", len(data), SignatureLength, RecoverableSignatureLength)
+	}
+	scheme, err := SchemeOf(KeyType(data[0]))
+	if err != nil {
+		return nil, err
+	}
+	if err := scheme.ValidateSignature(data[1:]); err != nil {
+		return nil, err
+	}
+	return &Signature{Type: scheme.Type(), Data: append([]byte(nil), data[1:]...)}, nil
 }
 
-// ToBytes 方法将签名转换为字节数组
+// ToBytes 方法将签名编码为算法标签加原始签名
+// ToBytes method encodes the signature as the scheme tag followed by the raw signature
 func (sig Signature) ToBytes() []byte {
-	rBytes := sig.R.Bytes()
-	sBytes := sig.S.Bytes()
-	return append(rBytes, sBytes...)
+	return append([]byte{byte(sig.Type)}, sig.Data...)
 }
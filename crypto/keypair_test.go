@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"testing"
 )
 
@@ -31,3 +32,183 @@ func TestKeypairSignVerifyFail(t *testing.T) {
 	assert.False(t, signature.Verify(otherPublicKey, msg))
 	assert.False(t, signature.Verify(PublicKey, []byte("xxxxxx")))
 }
+
+// TestSignatureBytesRoundTrip 测试签名固定长度编码的往返，包括 R 或 S 有前导零的情况
+// TestSignatureBytesRoundTrip tests the round trip of the fixed length signature encoding, including R or S with leading zeros
+func TestSignatureBytesRoundTrip(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	msg := []byte("hello world")
+
+	for i := 0; i < 256; i++ {
+		signature, err := privateKey.Sign(msg)
+		assert.Nil(t, err)
+
+		b := signature.ToBytes()
+		assert.Len(t, b, SignatureLength)
+
+		decoded, err := SignatureFromBytes(b)
+		assert.Nil(t, err)
+		assert.Equal(t, signature, decoded)
+		assert.True(t, decoded.Verify(privateKey.PublicKey(), msg))
+	}
+
+	// 短的 R 会被左补零 // A short R is left padded with zeros
+	short := p256Signature(big.NewInt(1), big.NewInt(2))
+	b := short.ToBytes()
+	assert.Len(t, b, SignatureLength)
+	decoded, err := SignatureFromBytes(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), new(big.Int).SetBytes(decoded.Data[:32]).Int64())
+	assert.Equal(t, int64(2), new(big.Int).SetBytes(decoded.Data[32:]).Int64())
+}
+
+// TestSignatureFromBytesInvalid 测试无效的签名编码返回错误而不是 panic
+// TestSignatureFromBytesInvalid tests that invalid signature encodings return an error instead of panicking
+func TestSignatureFromBytesInvalid(t *testing.T) {
+	for _, data := range [][]byte{nil, make([]byte, 10), make([]byte, SignatureLength-1), make([]byte, SignatureLength+1)} {
+		_, err := SignatureFromBytes(data)
+		assert.NotNil(t, err)
+	}
+
+	// R 和 S 不能为零 // R and S must not be zero
+	_, err := SignatureFromBytes(p256Signature(big.NewInt(0), big.NewInt(0)).ToBytes())
+	assert.NotNil(t, err)
+
+	// 未知的算法标签 // Unknown scheme tag
+	_, err = SignatureFromBytes(make([]byte, SignatureLength))
+	assert.NotNil(t, err)
+}
+
+// TestSignatureLowS 测试签名总是低 S，高 S 的变体会被拒绝
+// TestSignatureLowS tests that signatures are always low-S and that their high-S variant is rejected
+func TestSignatureLowS(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	msg := []byte("hello world")
+
+	for i := 0; i < 64; i++ {
+		signature, err := privateKey.Sign(msg)
+		assert.Nil(t, err)
+		r := new(big.Int).SetBytes(signature.Data[:32])
+		s := new(big.Int).SetBytes(signature.Data[32:])
+		assert.True(t, s.Cmp(halfCurveOrder) <= 0)
+
+		// (r, n-s) 在 ECDSA 中同样有效，但不是规范签名 // (r, n-s) is valid ECDSA as well, but not canonical
+		malleated := p256Signature(r, new(big.Int).Sub(curveOrder, s))
+		assert.False(t, malleated.Verify(privateKey.PublicKey(), msg))
+		_, err = SignatureFromBytes(malleated.ToBytes())
+		assert.NotNil(t, err)
+	}
+}
+
+// TestKeyTypes 测试每种签名算法的签名、验证和编码往返
+// TestKeyTypes tests signing, verification and the encoding round trip of every signature scheme
+func TestKeyTypes(t *testing.T) {
+	msg := []byte("hello world")
+
+	for _, keyType := range []KeyType{KeyTypeP256, KeyTypeEd25519, KeyTypeSecp256k1} {
+		privateKey, err := GenerateKey(keyType)
+		assert.Nil(t, err)
+		assert.Equal(t, keyType, privateKey.Type())
+
+		publicKey := privateKey.PublicKey()
+		decodedKey, err := PublicKeyFromBytes(publicKey.ToSlice())
+		assert.Nil(t, err)
+		assert.Equal(t, publicKey, decodedKey)
+
+		signature, err := privateKey.Sign(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, keyType, signature.Type)
+		decoded, err := SignatureFromBytes(signature.ToBytes())
+		assert.Nil(t, err)
+		assert.True(t, decoded.Verify(decodedKey, msg))
+		assert.False(t, decoded.Verify(decodedKey, []byte("xxxxxx")))
+
+		restored, err := NewPrivateKey(keyType, privateKey.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, publicKey, restored.PublicKey())
+
+		name, err := ParseKeyType(keyType.String())
+		assert.Nil(t, err)
+		assert.Equal(t, keyType, name)
+	}
+}
+
+// TestKeyTypeMismatch 测试签名只能用同一算法的公钥验证，且相同字节的不同算法得到不同地址
+// TestKeyTypeMismatch tests that a signature only verifies against a public key of the same scheme, and that equal bytes of different schemes give different addresses
+func TestKeyTypeMismatch(t *testing.T) {
+	msg := []byte("hello world")
+	privateKey, err := GenerateKey(KeyTypeSecp256k1)
+	assert.Nil(t, err)
+	signature, err := privateKey.Sign(msg)
+	assert.Nil(t, err)
+
+	// secp256k1 和 P-256 的压缩公钥长度相同 // Compressed secp256k1 and P-256 keys have the same length
+	relabeled := PublicKey{Type: KeyTypeP256, Key: privateKey.PublicKey().Key}
+	assert.False(t, signature.Verify(relabeled, msg))
+	assert.NotEqual(t, privateKey.PublicKey().Address(), relabeled.Address())
+
+	other := Signature{Type: KeyTypeP256, Data: signature.Data}
+	assert.False(t, other.Verify(privateKey.PublicKey(), msg))
+
+	_, err = PublicKeyFromBytes([]byte{0xff, 0x01})
+	assert.NotNil(t, err)
+	_, err = GenerateKey(KeyType(0xff))
+	assert.NotNil(t, err)
+}
+
+// TestRecoverableSignature 测试从可恢复签名恢复公钥，并拒绝错误的恢复 ID
+// TestRecoverableSignature tests recovering the public key from a recoverable signature and rejecting a wrong recovery id
+func TestRecoverableSignature(t *testing.T) {
+	msg := []byte("hello world")
+	privateKey, err := GenerateKey(KeyTypeSecp256k1)
+	assert.Nil(t, err)
+
+	for i := 0; i < 16; i++ {
+		signature, err := privateKey.SignRecoverable(msg)
+		assert.Nil(t, err)
+		assert.True(t, signature.Recoverable())
+
+		b := signature.ToBytes()
+		assert.Len(t, b, RecoverableSignatureLength)
+		decoded, err := SignatureFromBytes(b)
+		assert.Nil(t, err)
+
+		recovered, err := decoded.RecoverPublicKey(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, privateKey.PublicKey(), recovered)
+		assert.True(t, decoded.Verify(privateKey.PublicKey(), msg))
+
+		// 其他消息恢复出不同的公钥 // Another message recovers a different key
+		other, err := decoded.RecoverPublicKey([]byte("xxxxxx"))
+		if err == nil {
+			assert.NotEqual(t, privateKey.PublicKey(), other)
+		}
+		assert.False(t, decoded.Verify(privateKey.PublicKey(), []byte("xxxxxx")))
+
+		// 翻转恢复 ID 后签名对原公钥无效 // With the recovery id flipped the signature no longer verifies against the key
+		decoded.Data[signatureSize] ^= 1
+		assert.False(t, decoded.Verify(privateKey.PublicKey(), msg))
+	}
+
+	// 其他算法不支持可恢复签名 // Other schemes do not support recoverable signatures
+	for _, keyType := range []KeyType{KeyTypeP256, KeyTypeEd25519} {
+		key, err := GenerateKey(keyType)
+		assert.Nil(t, err)
+		_, err = key.SignRecoverable(msg)
+		assert.NotNil(t, err)
+
+		signature, err := key.Sign(msg)
+		assert.Nil(t, err)
+		_, err = SignatureFromBytes(append(signature.ToBytes(), 0))
+		assert.NotNil(t, err)
+	}
+}
+
+// p256Signature 函数用 R 和 S 构造 P-256 签名
+// p256Signature function builds a P-256 signature from R and S
+func p256Signature(r, s *big.Int) Signature {
+	data := make([]byte, signatureSize)
+	r.FillBytes(data[:32])
+	s.FillBytes(data[32:])
+	return Signature{Type: KeyTypeP256, Data: data}
+}
@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"os"
+)
+
+const (
+	// KeystoreVersion 是当前的密钥文件格式版本
+	// KeystoreVersion is the current keystore file format version
+	KeystoreVersion = 1
+
+	keystoreCipher = "aes-256-gcm" // 认证加密算法 // Authenticated encryption algorithm
+	keystoreKDF    = "scrypt"      // 口令派生算法 // Password key derivation function
+	keystoreKeyLen = 32            // 派生的 AES-256 密钥长度 // Length of the derived AES-256 key
+	keystoreSalt   = 32            // 盐的长度 // Length of the salt
+	keystoreMaxN   = 1 << 20       // 解密时接受的最大 scrypt 成本，防止恶意文件耗尽内存 // Largest scrypt cost accepted on decryption, so a malicious file cannot exhaust memory
+)
+
+// ScryptParams 结构体表示 scrypt 的成本参数
+// ScryptParams struct represents the scrypt cost parameters
+type ScryptParams struct {
+	N int `json:"n"` // CPU 和内存成本 // CPU and memory cost
+	R int `json:"r"` // 块大小 // Block size
+	P int `json:"p"` // 并行度 // Parallelization
+}
+
+var (
+	// StandardScryptParams 是写入密钥文件的默认参数，解密约需 256MB 内存
+	// StandardScryptParams are the default parameters for keystore files, decrypting takes about 256MB of memory
+	StandardScryptParams = ScryptParams{N: 1 << 18, R: 8, P: 1}
+	// LightScryptParams 是用于测试和低配设备的参数，安全性较低
+	// LightScryptParams are parameters for tests and constrained devices, at lower security
+	LightScryptParams = ScryptParams{N: 1 << 12, R: 8, P: 6}
+)
+
+// Keystore 结构体表示口令加密的私钥文件，地址以明文保存在元数据中
+// Keystore struct represents a password encrypted private key file, the address is kept in clear text in the metadata
+type Keystore struct {
+	Version int            `json:"version"`           // 文件格式版本 // File format version
+	Address string         `json:"address"`           // 私钥对应的地址 // Address of the private key
+	KeyType string         `json:"keyType,omitempty"` // 签名算法，为空时是 P-256 // Signature scheme, P-256 when empty
+	Crypto  KeystoreCrypto `json:"crypto"`            // 加密参数和密文 // Encryption parameters and ciphertext
+}
+
+// KeystoreCrypto 结构体表示密钥文件的加密部分，二进制字段均为十六进制
+// KeystoreCrypto struct represents the encrypted part of a keystore file, binary fields are hex encoded
+type KeystoreCrypto struct {
+	Cipher     string       `json:"cipher"`
+	Ciphertext string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+	Salt       string       `json:"salt"`
+}
+
+// EncryptKey 函数使用口令加密私钥，地址作为附加认证数据，修改地址会导致解密失败
+// EncryptKey function encrypts the private key with the password, the address is authenticated as additional data so changing it makes decryption fail
+func EncryptKey(key PrivateKey, password string, params ScryptParams) (*Keystore, error) {
+	salt := make([]byte, keystoreSalt)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := keystoreAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	address := key.PublicKey().Address().String()
+	ciphertext := aead.Seal(nil, nonce, key.Bytes(), []byte(address))
+
+	return &Keystore{
+		Version: KeystoreVersion,
+		Address: address,
+		KeyType: key.Type().String(),
+		Crypto: KeystoreCrypto{
+			Cipher:     keystoreCipher,
+			Ciphertext: hex.EncodeToString(ciphertext),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        keystoreKDF,
+			KDFParams:  params,
+			Salt:       hex.EncodeToString(salt),
+		},
+	}, nil
+}
+
+// Decrypt 方法使用口令解密私钥，并检查私钥与元数据中的地址一致
+// Decrypt method decrypts the private key with the password and checks that it matches the address in the metadata
+func (ks *Keystore) Decrypt(password string) (PrivateKey, error) {
+	if ks.Version != KeystoreVersion {
+		return PrivateKey{}, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != keystoreCipher || ks.Crypto.KDF != keystoreKDF {
+		return PrivateKey{}, fmt.Errorf("unsupported keystore cipher %q or kdf %q", ks.Crypto.Cipher, ks.Crypto.KDF)
+	}
+	if ks.Crypto.KDFParams.N > keystoreMaxN {
+		return PrivateKey{}, fmt.Errorf("keystore scrypt cost %d exceeds %d", ks.Crypto.KDFParams.N, keystoreMaxN)
+	}
+	salt, err := hex.DecodeString(ks.Crypto.Salt)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("invalid keystore salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("invalid keystore nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.Ciphertext)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("invalid keystore ciphertext: %w", err)
+	}
+
+	aead, err := keystoreAEAD(password, salt, ks.Crypto.KDFParams)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return PrivateKey{}, fmt.Errorf("invalid keystore nonce length %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(ks.Address))
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("could not decrypt keystore: wrong password or corrupted file")
+	}
+
+	keyType := KeyTypeP256
+	if ks.KeyType != "" {
+		if keyType, err = ParseKeyType(ks.KeyType); err != nil {
+			return PrivateKey{}, err
+		}
+	}
+	// 地址覆盖算法标签，篡改算法会导致地址不一致 // The address covers the scheme tag, tampering with the type makes the addresses differ
+	key, err := NewPrivateKey(keyType, plaintext)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	if address := key.PublicKey().Address().String(); address != ks.Address {
+		return PrivateKey{}, fmt.Errorf("keystore address (%s) does not match key address (%s)", ks.Address, address)
+	}
+	return key, nil
+}
+
+// WriteKeystore 函数将口令加密的私钥写入新文件，文件仅对所有者可读写，已存在的文件不会被覆盖
+// WriteKeystore function writes the password encrypted private key to a new file that only the owner can read and write, existing files are never overwritten
+func WriteKeystore(path string, key PrivateKey, password string, params ScryptParams) error {
+	ks, err := EncryptKey(key, password, params)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadKeystore 函数从文件读取并解密私钥
+// LoadKeystore function reads and decrypts a private key from a file
+func LoadKeystore(path string, password string) (PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PrivateKey{}, err
+	}
+	ks := new(Keystore)
+	if err := json.Unmarshal(data, ks); err != nil {
+		return PrivateKey{}, fmt.Errorf("invalid keystore file %s: %w", path, err)
+	}
+	return ks.Decrypt(password)
+}
+
+// keystoreAEAD 函数从口令派生密钥并创建 AES-GCM 加密器
+// keystoreAEAD function derives the key from the password and creates the AES-GCM cipher
+func keystoreAEAD(password string, salt []byte, params ScryptParams) (cipher.AEAD, error) {
+	derived, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, keystoreKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrypt parameters: %w", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
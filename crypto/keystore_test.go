@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPrivateKeyBytes 测试私钥编码的往返
+// TestPrivateKeyBytes tests the round trip of the private key encoding
+func TestPrivateKeyBytes(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	b := privateKey.Bytes()
+	assert.Len(t, b, PrivateKeyLength)
+
+	decoded, err := PrivateKeyFromBytes(b)
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey.PublicKey().Address(), decoded.PublicKey().Address())
+
+	msg := []byte("hello world")
+	signature, err := decoded.Sign(msg)
+	assert.Nil(t, err)
+	assert.True(t, signature.Verify(privateKey.PublicKey(), msg))
+
+	_, err = PrivateKeyFromBytes(b[:31])
+	assert.NotNil(t, err)
+	_, err = PrivateKeyFromBytes(make([]byte, PrivateKeyLength))
+	assert.NotNil(t, err)
+}
+
+// TestKeystoreEncryptDecrypt 测试密钥文件的加密和解密
+// TestKeystoreEncryptDecrypt tests encrypting and decrypting a keystore
+func TestKeystoreEncryptDecrypt(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	ks, err := EncryptKey(privateKey, "secret", LightScryptParams)
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey.PublicKey().Address().String(), ks.Address)
+
+	decrypted, err := ks.Decrypt("secret")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey.Bytes(), decrypted.Bytes())
+
+	_, err = ks.Decrypt("wrong")
+	assert.NotNil(t, err)
+
+	// 地址是认证数据，不能被替换 // The address is authenticated and cannot be swapped
+	ks.Address = GeneratePrivateKey().PublicKey().Address().String()
+	_, err = ks.Decrypt("secret")
+	assert.NotNil(t, err)
+}
+
+// TestKeystoreFile 测试写入和读取密钥文件
+// TestKeystoreFile tests writing and loading a keystore file
+func TestKeystoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	privateKey := GeneratePrivateKey()
+	assert.Nil(t, WriteKeystore(path, privateKey, "secret", LightScryptParams))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	ks := map[string]any{}
+	assert.Nil(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, privateKey.PublicKey().Address().String(), ks["address"])
+
+	// 不会覆盖已有的密钥文件 // Existing keystore files are not overwritten
+	assert.NotNil(t, WriteKeystore(path, GeneratePrivateKey(), "secret", LightScryptParams))
+
+	loaded, err := LoadKeystore(path, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey.PublicKey().Address(), loaded.PublicKey().Address())
+}
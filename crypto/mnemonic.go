@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"golang.org/x/crypto/pbkdf2"
+	"strings"
+)
+
+// mnemonicEnglish 是 BIP-39 英文词表，共 2048 个单词
+// mnemonicEnglish is the BIP-39 English wordlist of 2048 words
+//
+//go:embed mnemonic_english.txt
+var mnemonicEnglish string
+
+var (
+	// mnemonicWords 是按索引排列的单词 // mnemonicWords are the words by index
+	mnemonicWords = strings.Fields(mnemonicEnglish)
+	// mnemonicIndex 是单词到索引的映射 // mnemonicIndex maps words to their index
+	mnemonicIndex = func() map[string]int {
+		index := make(map[string]int, len(mnemonicWords))
+		for i, word := range mnemonicWords {
+			index[word] = i
+		}
+		return index
+	}()
+)
+
+const (
+	mnemonicSeedSalt   = "mnemonic" // 种子派生的盐前缀 // Salt prefix of the seed derivation
+	mnemonicIterations = 2048       // PBKDF2 迭代次数 // PBKDF2 iteration count
+	// MnemonicSeedLength 是助记词派生的种子长度
+	// MnemonicSeedLength is the length of the seed derived from a mnemonic
+	MnemonicSeedLength = 64
+)
+
+// NewMnemonic 函数生成一个包含指定位数熵的助记词，位数为 128 到 256 之间 32 的倍数
+// NewMnemonic function generates a mnemonic with the given bits of entropy, a multiple of 32 between 128 and 256
+func NewMnemonic(bits int) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("invalid mnemonic entropy size %d", bits)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy 函数将熵编码为助记词，每 11 位对应一个单词，末尾附加 SHA-256 校验位
+// MnemonicFromEntropy function encodes the entropy as a mnemonic, one word per 11 bits, with SHA-256 checksum bits appended
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("invalid mnemonic entropy size %d", bits)
+	}
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), checksum[0])
+
+	words := make([]string, (bits+bits/32)/11)
+	for i := range words {
+		words[i] = mnemonicWords[readBits(data, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy 函数解码助记词并校验单词和校验位
+// MnemonicToEntropy function decodes the mnemonic and checks its words and checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("invalid mnemonic length %d", len(words))
+	}
+
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := mnemonicIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word %q", word)
+		}
+		writeBits(data, i*11, 11, index)
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	checksum := sha256.Sum256(entropy)
+	if readBits(data, len(entropy)*8, checksumBits) != int(checksum[0]>>(8-checksumBits)) {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic 函数检查助记词是否有效
+// ValidateMnemonic function checks whether the mnemonic is valid
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed 函数使用 PBKDF2-HMAC-SHA512 从助记词和口令派生 64 字节种子，助记词无效时返回错误
+// MnemonicToSeed function derives the 64 byte seed from the mnemonic and passphrase with PBKDF2-HMAC-SHA512, returning an error when the mnemonic is invalid
+//
+// 英文助记词总是 NFKD 规范形式，非 ASCII 的口令需要调用方先做 NFKD 规范化
+// English mnemonics are always in NFKD form, non-ASCII passphrases must be NFKD normalized by the caller
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte(mnemonicSeedSalt+passphrase), mnemonicIterations, MnemonicSeedLength, sha512.New), nil
+}
+
+// readBits 函数从大端位串的指定位置读取 n 位
+// readBits function reads n bits at the given offset of a big-endian bit string
+func readBits(data []byte, offset, n int) int {
+	value := 0
+	for i := offset; i < offset+n; i++ {
+		value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+	}
+	return value
+}
+
+// writeBits 函数将 value 的低 n 位写入大端位串的指定位置
+// writeBits function writes the low n bits of value at the given offset of a big-endian bit string
+func writeBits(data []byte, offset, n, value int) {
+	for i := 0; i < n; i++ {
+		if value>>(n-1-i)&1 == 1 {
+			pos := offset + i
+			data[pos/8] |= 1 << (7 - pos%8)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// TestMnemonicVectors 测试 BIP-39 官方向量，口令为 TREZOR
+// TestMnemonicVectors tests the official BIP-39 vectors with the passphrase TREZOR
+func TestMnemonicVectors(t *testing.T) {
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		},
+	}
+
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := MnemonicFromEntropy(entropy)
+		assert.Nil(t, err)
+		assert.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := MnemonicToEntropy(mnemonic)
+		assert.Nil(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+		assert.Nil(t, err)
+		assert.Equal(t, v.seed, hex.EncodeToString(seed))
+	}
+}
+
+// TestMnemonicInvalid 测试拒绝无效的助记词
+// TestMnemonicInvalid tests rejecting invalid mnemonics
+func TestMnemonicInvalid(t *testing.T) {
+	for _, mnemonic := range []string{
+		"",
+		"abandon abandon abandon",
+		// 校验位错误 // Wrong checksum
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		// 不在词表中 // Not in the wordlist
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon bitcoin",
+	} {
+		assert.NotNil(t, ValidateMnemonic(mnemonic), mnemonic)
+	}
+
+	mnemonic, err := NewMnemonic(256)
+	assert.Nil(t, err)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+	assert.Nil(t, ValidateMnemonic(mnemonic))
+
+	_, err = NewMnemonic(100)
+	assert.NotNil(t, err)
+}
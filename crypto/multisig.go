@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/lonySp/go-blockchain/types"
+	"sort"
+)
+
+// multisigDomain 是多签账户地址派生的域前缀，避免与单签地址冲突
+// multisigDomain is the domain prefix of the multisig address derivation, keeping it apart from single key addresses
+const multisigDomain = "go-blockchain/multisig/v1"
+
+// MultisigAccount 结构体表示一个 M-of-N 多签账户
+// MultisigAccount struct represents an M-of-N multisignature account
+type MultisigAccount struct {
+	Threshold  uint32      // 需要的最少签名数 // Minimum number of signatures required
+	PublicKeys []PublicKey // 按字节序排序的成员公钥 // Member public keys sorted by bytes
+}
+
+// NewMultisigAccount 函数创建一个多签账户，公钥会被排序使地址与顺序无关
+// NewMultisigAccount function creates a multisig account, the public keys are sorted so the address does not depend on their order
+func NewMultisigAccount(threshold uint32, publicKeys []PublicKey) (*MultisigAccount, error) {
+	if threshold == 0 || int(threshold) > len(publicKeys) {
+		return nil, fmt.Errorf("invalid multisig threshold %d for %d keys", threshold, len(publicKeys))
+	}
+
+	keys := make([]PublicKey, len(publicKeys))
+	copy(keys, publicKeys)
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].ToSlice(), keys[j].ToSlice()) < 0
+	})
+	for i := 1; i < len(keys); i++ {
+		if bytes.Equal(keys[i-1].ToSlice(), keys[i].ToSlice()) {
+			return nil, fmt.Errorf("duplicate multisig key %x", keys[i].ToSlice())
+		}
+	}
+
+	return &MultisigAccount{
+		Threshold:  threshold,
+		PublicKeys: keys,
+	}, nil
+}
+
+// Address 方法根据门限和成员公钥派生多签账户地址
+// Address method derives the multisig account address from the threshold and the member public keys
+func (m *MultisigAccount) Address() types.Address {
+	buf := []byte(multisigDomain)
+	buf = binary.BigEndian.AppendUint32(buf, m.Threshold)
+	for _, key := range m.PublicKeys {
+		buf = append(buf, key.ToSlice()...)
+	}
+	h := sha256.Sum256(buf)
+	return types.NewAddressFromBytes(h[len(h)-20:])
+}
+
+// IndexOf 方法返回公钥在成员中的索引，不存在时返回 -1
+// IndexOf method returns the index of the public key among the members, or -1 if it is not a member
+func (m *MultisigAccount) IndexOf(publicKey PublicKey) int {
+	for i, key := range m.PublicKeys {
+		if bytes.Equal(key.ToSlice(), publicKey.ToSlice()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// MultiSignature 结构体表示多签账户中某个成员的签名
+// MultiSignature struct represents the signature of one member of a multisig account
+type MultiSignature struct {
+	Index     uint32     // 签名成员的索引 // Index of the signing member
+	Signature *Signature // 成员签名 // Member signature
+}
+
+// Verify 方法验证签名是否满足门限，签名必须按成员索引严格递增
+// Verify method verifies that the signatures meet the threshold, the signatures must be strictly increasing by member index
+func (m *MultisigAccount) Verify(sigs []MultiSignature, data []byte) error {
+	if m.Threshold == 0 || int(m.Threshold) > len(m.PublicKeys) {
+		return fmt.Errorf("invalid multisig threshold %d for %d keys", m.Threshold, len(m.PublicKeys))
+	}
+
+	for i, sig := range sigs {
+		if i > 0 && sig.Index <= sigs[i-1].Index {
+			return fmt.Errorf("multisig signatures must be ordered by unique member index")
+		}
+		if int(sig.Index) >= len(m.PublicKeys) {
+			return fmt.Errorf("multisig signature index %d out of range", sig.Index)
+		}
+		if sig.Signature == nil || !sig.Signature.Verify(m.PublicKeys[sig.Index], data) {
+			return fmt.Errorf("invalid multisig signature of member %d", sig.Index)
+		}
+	}
+
+	if len(sigs) < int(m.Threshold) {
+		return fmt.Errorf("multisig threshold not met: have %d signatures, want %d", len(sigs), m.Threshold)
+	}
+	return nil
+}
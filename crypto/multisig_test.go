@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestMultisigAddressIgnoresKeyOrder 测试多签地址与公钥顺序无关
+// TestMultisigAddressIgnoresKeyOrder tests that the multisig address does not depend on the key order
+func TestMultisigAddressIgnoresKeyOrder(t *testing.T) {
+	a := GeneratePrivateKey().PublicKey()
+	b := GeneratePrivateKey().PublicKey()
+
+	m1, err := NewMultisigAccount(1, []PublicKey{a, b})
+	assert.Nil(t, err)
+	m2, err := NewMultisigAccount(1, []PublicKey{b, a})
+	assert.Nil(t, err)
+	m3, err := NewMultisigAccount(2, []PublicKey{a, b})
+	assert.Nil(t, err)
+
+	assert.Equal(t, m1.Address(), m2.Address())
+	assert.NotEqual(t, m1.Address(), m3.Address())
+	assert.NotEqual(t, a.Address(), m1.Address())
+
+	_, err = NewMultisigAccount(3, []PublicKey{a, b})
+	assert.NotNil(t, err)
+	_, err = NewMultisigAccount(1, []PublicKey{a, a})
+	assert.NotNil(t, err)
+}
+
+// TestMultisigVerify 测试多签门限验证
+// TestMultisigVerify tests the multisig threshold verification
+func TestMultisigVerify(t *testing.T) {
+	keys := []PrivateKey{GeneratePrivateKey(), GeneratePrivateKey(), GeneratePrivateKey()}
+	m, err := NewMultisigAccount(2, []PublicKey{keys[0].PublicKey(), keys[1].PublicKey(), keys[2].PublicKey()})
+	assert.Nil(t, err)
+
+	msg := []byte("hello world")
+	sign := func(privateKey PrivateKey) MultiSignature {
+		sig, err := privateKey.Sign(msg)
+		assert.Nil(t, err)
+		return MultiSignature{Index: uint32(m.IndexOf(privateKey.PublicKey())), Signature: sig}
+	}
+	sigs := []MultiSignature{sign(keys[0]), sign(keys[2])}
+	if sigs[0].Index > sigs[1].Index {
+		sigs[0], sigs[1] = sigs[1], sigs[0]
+	}
+
+	assert.Nil(t, m.Verify(sigs, msg))
+	assert.NotNil(t, m.Verify(sigs[:1], msg))
+	assert.NotNil(t, m.Verify([]MultiSignature{sigs[0], sigs[0]}, msg))
+	assert.NotNil(t, m.Verify(sigs, []byte("xxxxxx")))
+}
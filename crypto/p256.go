@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// curveOrder 是 P-256 曲线的阶 // curveOrder is the order of the P-256 curve
+	curveOrder = elliptic.P256().Params().N
+	// halfCurveOrder 是曲线阶的一半，低 S 签名的 S 不超过该值 // halfCurveOrder is half the curve order, the S of a low-S signature does not exceed it
+	halfCurveOrder = new(big.Int).Rsh(curveOrder, 1)
+)
+
+// p256Scheme 实现 P-256 上的 ECDSA，公钥为 33 字节压缩点，签名为 R 和 S 各 32 字节
+// p256Scheme implements ECDSA over P-256, public keys are 33 byte compressed points and signatures are 32 bytes each for R and S
+type p256Scheme struct{}
+
+func (p256Scheme) Type() KeyType { return KeyTypeP256 }
+
+func (p256Scheme) GenerateKey() ([]byte, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return key.Bytes(), nil
+}
+
+func (s p256Scheme) PublicKey(privateKey []byte) ([]byte, error) {
+	key, err := s.privateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return elliptic.MarshalCompressed(key.Curve, key.X, key.Y), nil
+}
+
+// Sign 方法返回低 S 签名 // Sign method returns a low-S signature
+func (s p256Scheme) Sign(privateKey, data []byte) ([]byte, error) {
+	key, err := s.privateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	r, sv, err := ecdsa.Sign(rand.Reader, key, data)
+	if err != nil {
+		return nil, err
+	}
+	// (r, n-s) 同样是有效签名，统一使用较小的 S 防止签名被篡改 // (r, n-s) is valid as well, always use the lower S so signatures cannot be malleated
+	if sv.Cmp(halfCurveOrder) > 0 {
+		sv.Sub(curveOrder, sv)
+	}
+	sig := make([]byte, signatureSize)
+	r.FillBytes(sig[:32])
+	sv.FillBytes(sig[32:])
+	return sig, nil
+}
+
+func (s p256Scheme) Verify(publicKey, data, sig []byte) bool {
+	if s.ValidateSignature(sig) != nil {
+		return false
+	}
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey)
+	if x == nil {
+		return false
+	}
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	return ecdsa.Verify(key, data, new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+}
+
+func (p256Scheme) ValidatePublicKey(publicKey []byte) error {
+	if x, _ := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey); x == nil {
+		return fmt.Errorf("invalid p256 public key")
+	}
+	return nil
+}
+
+// ValidateSignature 方法检查 R 和 S 是否在 [1, n) 范围内且 S 为低 S
+// ValidateSignature method checks that R and S are within [1, n) and that S is low
+func (p256Scheme) ValidateSignature(sig []byte) error {
+	if len(sig) != signatureSize {
+		return fmt.Errorf("invalid p256 signature length %d", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(curveOrder) >= 0 {
+		return fmt.Errorf("signature values out of range")
+	}
+	if s.Cmp(halfCurveOrder) > 0 {
+		return fmt.Errorf("signature is not canonical: high S value")
+	}
+	return nil
+}
+
+// privateKey 方法从 32 字节的标量构造 ECDSA 私钥
+// privateKey method builds the ECDSA private key from its 32 byte scalar
+func (p256Scheme) privateKey(data []byte) (*ecdsa.PrivateKey, error) {
+	if len(data) != PrivateKeyLength {
+		return nil, fmt.Errorf("invalid private key length %d, expected %d", len(data), PrivateKeyLength)
+	}
+	// ecdh 校验标量范围并计算公钥点 // ecdh checks the scalar range and computes the public point
+	ecdhKey, err := ecdh.P256().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	point := ecdhKey.PublicKey().Bytes() // 0x04 | X | Y
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(data)}
+	key.Curve = elliptic.P256()
+	key.X = new(big.Int).SetBytes(point[1:33])
+	key.Y = new(big.Int).SetBytes(point[33:])
+	return key, nil
+}
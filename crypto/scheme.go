@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyType 表示公钥和签名所属的签名算法，编码时作为第一个字节
+// KeyType represents the signature scheme of a public key or signature, it is the first byte of their encoding
+type KeyType uint8
+
+const (
+	KeyTypeP256      KeyType = iota + 1 // NIST P-256 上的 ECDSA // ECDSA over NIST P-256
+	KeyTypeEd25519                      // Ed25519
+	KeyTypeSecp256k1                    // secp256k1 上的 ECDSA，与常见钱包兼容 // ECDSA over secp256k1, compatible with common wallets
+)
+
+// keyTypeNames 是签名算法的名称 // keyTypeNames are the names of the signature schemes
+var keyTypeNames = map[KeyType]string{
+	KeyTypeP256:      "p256",
+	KeyTypeEd25519:   "ed25519",
+	KeyTypeSecp256k1: "secp256k1",
+}
+
+// String 方法返回签名算法的名称
+// String method returns the name of the signature scheme
+func (t KeyType) String() string {
+	if name, ok := keyTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(t))
+}
+
+// ParseKeyType 函数按名称解析签名算法，不区分大小写
+// ParseKeyType function parses a signature scheme by name, case insensitive
+func ParseKeyType(name string) (KeyType, error) {
+	for t, n := range keyTypeNames {
+		if strings.EqualFold(n, name) {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key type %q", name)
+}
+
+// Scheme 接口定义了一种签名算法，私钥、公钥和签名均以不带标签的原始字节表示
+// Scheme interface defines a signature scheme, private keys, public keys and signatures are raw bytes without the type tag
+type Scheme interface {
+	// Type 返回算法标签 // Type returns the scheme tag
+	Type() KeyType
+	// GenerateKey 生成新的私钥 // GenerateKey generates a new private key
+	GenerateKey() ([]byte, error)
+	// PublicKey 校验私钥并返回对应的公钥 // PublicKey validates the private key and returns its public key
+	PublicKey(privateKey []byte) ([]byte, error)
+	// Sign 对数据签名，返回规范签名 // Sign signs the data and returns a canonical signature
+	Sign(privateKey, data []byte) ([]byte, error)
+	// Verify 验证签名，非规范签名被视为无效 // Verify verifies the signature, non-canonical signatures are invalid
+	Verify(publicKey, data, sig []byte) bool
+	// ValidatePublicKey 检查公钥编码是否有效 // ValidatePublicKey checks that the public key encoding is valid
+	ValidatePublicKey(publicKey []byte) error
+	// ValidateSignature 检查签名编码是否有效且规范 // ValidateSignature checks that the signature encoding is valid and canonical
+	ValidateSignature(sig []byte) error
+}
+
+// RecoverableScheme 接口定义了可以从签名和消息恢复公钥的签名算法，可恢复签名在原始签名后附加 1 字节恢复 ID
+// RecoverableScheme interface defines a signature scheme that can recover the public key from a signature and message, recoverable signatures append a 1 byte recovery id to the raw signature
+type RecoverableScheme interface {
+	Scheme
+	// SignRecoverable 对数据签名，返回带恢复 ID 的规范签名 // SignRecoverable signs the data and returns a canonical signature with a recovery id
+	SignRecoverable(privateKey, data []byte) ([]byte, error)
+	// RecoverPublicKey 从可恢复签名和数据恢复公钥 // RecoverPublicKey recovers the public key from a recoverable signature and the data
+	RecoverPublicKey(data, sig []byte) ([]byte, error)
+}
+
+// schemes 是已支持的签名算法 // schemes are the supported signature schemes
+var schemes = map[KeyType]Scheme{
+	KeyTypeP256:      p256Scheme{},
+	KeyTypeEd25519:   ed25519Scheme{},
+	KeyTypeSecp256k1: secp256k1Scheme{},
+}
+
+// SchemeOf 函数返回标签对应的签名算法
+// SchemeOf function returns the signature scheme of the tag
+func SchemeOf(t KeyType) (Scheme, error) {
+	scheme, ok := schemes[t]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %d", uint8(t))
+	}
+	return scheme, nil
+}
@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// secp256k1Scheme 实现 secp256k1 上的 ECDSA，公钥为 33 字节压缩点，签名为 R 和 S 各 32 字节
+// secp256k1Scheme implements ECDSA over secp256k1, public keys are 33 byte compressed points and signatures are 32 bytes each for R and S
+type secp256k1Scheme struct{}
+
+// compactRecoveryOffset 是紧凑签名中压缩公钥的恢复码偏移 // compactRecoveryOffset is the recovery code offset of compact signatures for compressed keys
+const compactRecoveryOffset = 27 + 4
+
+func (secp256k1Scheme) Type() KeyType { return KeyTypeSecp256k1 }
+
+func (secp256k1Scheme) GenerateKey() ([]byte, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.Serialize(), nil
+}
+
+func (s secp256k1Scheme) PublicKey(privateKey []byte) ([]byte, error) {
+	key, err := s.privateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return key.PubKey().SerializeCompressed(), nil
+}
+
+// Sign 方法返回 RFC6979 确定性的低 S 签名
+// Sign method returns a deterministic RFC6979 low-S signature
+func (s secp256k1Scheme) Sign(privateKey, data []byte) ([]byte, error) {
+	key, err := s.privateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature := ecdsa.Sign(key, data)
+	r, sv := signature.R(), signature.S()
+	sig := make([]byte, signatureSize)
+	r.PutBytesUnchecked(sig[:32])
+	sv.PutBytesUnchecked(sig[32:])
+	return sig, nil
+}
+
+func (s secp256k1Scheme) Verify(publicKey, data, sig []byte) bool {
+	if len(publicKey) != secp256k1.PubKeyBytesLenCompressed {
+		return false
+	}
+	key, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return false
+	}
+	signature, err := s.signature(sig)
+	if err != nil {
+		return false
+	}
+	return signature.Verify(data, key)
+}
+
+// SignRecoverable 方法返回 R、S 和恢复 ID 共 65 字节的签名
+// SignRecoverable method returns a 65 byte signature of R, S and the recovery id
+func (s secp256k1Scheme) SignRecoverable(privateKey, data []byte) ([]byte, error) {
+	key, err := s.privateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	// 紧凑签名格式为 27 + 4 + 恢复 ID | R | S // The compact signature format is 27 + 4 + recovery id | R | S
+	compact := ecdsa.SignCompact(key, data, true)
+	return append(compact[1:], compact[0]-compactRecoveryOffset), nil
+}
+
+// RecoverPublicKey 方法从签名恢复压缩公钥，恢复过程同时验证了签名
+// RecoverPublicKey method recovers the compressed public key from the signature, recovery verifies the signature as well
+func (s secp256k1Scheme) RecoverPublicKey(data, sig []byte) ([]byte, error) {
+	if len(sig) != recoverableSignatureSize {
+		return nil, fmt.Errorf("invalid secp256k1 recoverable signature length %d", len(sig))
+	}
+	if err := s.ValidateSignature(sig); err != nil {
+		return nil, err
+	}
+	compact := append([]byte{sig[signatureSize] + compactRecoveryOffset}, sig[:signatureSize]...)
+	key, _, err := ecdsa.RecoverCompact(compact, data)
+	if err != nil {
+		return nil, err
+	}
+	return key.SerializeCompressed(), nil
+}
+
+func (secp256k1Scheme) ValidatePublicKey(publicKey []byte) error {
+	if len(publicKey) != secp256k1.PubKeyBytesLenCompressed {
+		return fmt.Errorf("invalid secp256k1 public key length %d", len(publicKey))
+	}
+	if _, err := secp256k1.ParsePubKey(publicKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateSignature 方法同时接受普通签名和带恢复 ID 的签名
+// ValidateSignature method accepts both plain signatures and signatures with a recovery id
+func (s secp256k1Scheme) ValidateSignature(sig []byte) error {
+	if len(sig) == recoverableSignatureSize {
+		if sig[signatureSize] > 3 {
+			return fmt.Errorf("invalid recovery id %d", sig[signatureSize])
+		}
+		sig = sig[:signatureSize]
+	}
+	_, err := s.signature(sig)
+	return err
+}
+
+// signature 方法解析签名并检查 R 和 S 是否在 [1, n) 范围内且 S 为低 S
+// signature method parses the signature and checks that R and S are within [1, n) and that S is low
+func (secp256k1Scheme) signature(sig []byte) (*ecdsa.Signature, error) {
+	if len(sig) != signatureSize {
+		return nil, fmt.Errorf("invalid secp256k1 signature length %d", len(sig))
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) || r.IsZero() || s.IsZero() {
+		return nil, fmt.Errorf("signature values out of range")
+	}
+	if s.IsOverHalfOrder() {
+		return nil, fmt.Errorf("signature is not canonical: high S value")
+	}
+	return ecdsa.NewSignature(&r, &s), nil
+}
+
+// privateKey 方法从 32 字节的标量构造私钥，拒绝零和超出群阶的标量
+// privateKey method builds the private key from its 32 byte scalar, rejecting zero and scalars beyond the group order
+func (secp256k1Scheme) privateKey(data []byte) (*secp256k1.PrivateKey, error) {
+	if len(data) != PrivateKeyLength {
+		return nil, fmt.Errorf("invalid private key length %d, expected %d", len(data), PrivateKeyLength)
+	}
+	var scalar secp256k1.ModNScalar
+	if scalar.SetByteSlice(data) || scalar.IsZero() {
+		return nil, fmt.Errorf("invalid private key: scalar out of range")
+	}
+	return secp256k1.NewPrivateKey(&scalar), nil
+}
@@ -2,41 +2,124 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"github.com/lonySp/go-blockchain/core"
 	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/network"
+	"github.com/lonySp/go-blockchain/signer"
 	"github.com/sirupsen/logrus"
 	"log"
+	"os"
+	"strings"
 	"time"
 )
 
+// passwordEnv 是未指定口令文件时读取密钥文件口令的环境变量
+// passwordEnv is the environment variable holding the keystore password when no password file is given
+const passwordEnv = "KEYSTORE_PASSWORD"
+
+var (
+	genesisFile       = flag.String("genesis", "genesis.json", "genesis configuration file")
+	validatorKeystore = flag.String("validator-keystore", "", "keystore file of the validator key, a throwaway key is generated when empty")
+	accountKeystore   = flag.String("account-keystore", "", "keystore file of the account signing the demo transactions, throwaway keys are used when empty")
+	passwordFile      = flag.String("password-file", "", "file holding the keystore password, defaults to $"+passwordEnv)
+	newKeystore       = flag.String("new-keystore", "", "generate a new key, write it to this keystore file and exit")
+	keyType           = flag.String("key-type", "p256", "signature scheme of the new key: p256, ed25519 or secp256k1")
+	signerListen      = flag.String("signer-listen", "", "run as a signer daemon holding the validator key on this unix:///path or host:port address")
+	remoteSigner      = flag.String("remote-signer", "", "sign blocks with the signer daemon at this unix:///path or host:port address instead of a local key")
+	listenAddr        = flag.String("listen", "", "run a single node over TCP listening on this host:port address instead of the in-process demo network")
+	peers             = flag.String("peers", "", "comma separated host:port addresses of the TCP peers to dial")
+	seeds             = flag.String("seeds", "", "comma separated host:port addresses of the seed nodes used to discover TCP peers")
+	addrBookFile      = flag.String("addr-book", "", "file keeping the known peer addresses across restarts, kept in memory when empty")
+	maxOutbound       = flag.Int("max-outbound", 8, "number of outbound TCP connections peer discovery maintains")
+	signState         = flag.String("sign-state", "", "file recording the last block the validator signed, so it never signs twice at a height across restarts")
+	signMaxGap        = flag.Uint("sign-max-gap", uint(signer.DefaultMaxHeightGap), "refuse to sign heights more than this far above the last signed height, 0 for no limit")
+	signerSecret      = flag.String("signer-secret", "", "file holding the secret shared by the signer daemon and the validator, required when the daemon listens on TCP")
+)
+
 func main() {
-	// 创建本地和远程传输节点
-	// Create local and remote transport nodes
-	trLocal := network.NewLocalTransport("LOCAL")
-	trRemoteA := network.NewLocalTransport("REMOTE_A")
-	trRemoteB := network.NewLocalTransport("REMOTE_B")
-	trRemoteC := network.NewLocalTransport("REMOTE_C")
-
-	// 连接本地和远程传输节点
-	// Connect local and remote transport nodes
-	trLocal.Connect(trRemoteA)
-	trRemoteA.Connect(trRemoteB)
-	trRemoteB.Connect(trRemoteC)
-	trRemoteA.Connect(trLocal)
+	flag.Parse()
+
+	// 生成新的密钥文件并退出
+	// Generate a new keystore file and exit
+	if *newKeystore != "" {
+		t, err := crypto.ParseKeyType(*keyType)
+		if err != nil {
+			log.Fatal(err)
+		}
+		privateKey, err := crypto.GenerateKey(t)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := crypto.WriteKeystore(*newKeystore, privateKey, readPassword(), crypto.StandardScryptParams); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(privateKey.PublicKey().Address())
+		return
+	}
+
+	// 作为签名服务运行，验证者私钥只保存在这个进程中
+	// Run as a signer daemon, the validator key only lives in this process
+	if *signerListen != "" {
+		guard := openGuard(signer.NewLocalSigner(*loadKey(*validatorKeystore)))
+		l, err := signer.Listen(*signerListen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(guard.PublicKey().Address())
+		log.Fatal(signer.NewServer(guard, readSignerSecret(), nil).Serve(l))
+	}
+
+	// 读取创世配置
+	// Load the genesis configuration
+	genesis, err := core.LoadGenesis(*genesisFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 作为独立进程通过 TCP 运行一个节点，指定了验证者私钥或远程签名服务时出块
+	// Run a single node as its own process over TCP, it proposes blocks when a validator key or a remote signer is given
+	if *listenAddr != "" {
+		runTCPNode(genesis)
+		return
+	}
+
+	// 创建验证者签名者并读取账户私钥
+	// Create the validator signer and load the account key
+	validatorSigner := loadSigner()
+	var accountKey *crypto.PrivateKey
+	if *accountKeystore != "" {
+		accountKey = loadKey(*accountKeystore)
+	}
+
+	// 在本地网络中创建本地和远程传输节点，节点之间通过种子节点 REMOTE_A 互相发现
+	// Create local and remote transport nodes in a local network, the nodes discover each other through the seed node REMOTE_A
+	lnet := network.NewLocalNetwork()
+	trLocal := lnet.Transport("LOCAL")
+	trRemoteA := lnet.Transport("REMOTE_A")
+	trRemoteB := lnet.Transport("REMOTE_B")
+	trRemoteC := lnet.Transport("REMOTE_C")
 
 	// 初始化远程服务器
 	// Initialize remote servers
-	initRemoteServers([]network.Transport{trRemoteA, trRemoteB, trRemoteC})
+	initRemoteServers(genesis, []network.Transport{trRemoteA, trRemoteB, trRemoteC}, trRemoteA.Addr())
 
 	// 启动一个 goroutine 每秒发送一笔交易
 	// Start a goroutine to send a transaction every second
 	go func() {
-		for {
-			// 发送交易到本地传输节点
-			// Send transaction to the local transport node
-			if err := sendTransaction(trRemoteA, trLocal.Addr()); err != nil {
+		for nonce := uint64(0); ; nonce++ {
+			// 未指定账户时每笔交易使用新的私钥
+			// Use a fresh key for every transaction when no account is given
+			privateKey := crypto.GeneratePrivateKey()
+			txNonce := uint64(0)
+			if accountKey != nil {
+				privateKey, txNonce = *accountKey, nonce
+			}
+
+			// 发送交易到本地传输节点，节点发现连接两者之前发送会失败
+			// Send transaction to the local transport node, sending fails until peer discovery connected the two
+			if err := sendTransaction(genesis.ChainID, privateKey, txNonce, trRemoteA, trLocal.Addr()); err != nil {
 				logrus.Error(err)
 			}
 			time.Sleep(2 * time.Second)
@@ -48,34 +131,150 @@ func main() {
 	//go func() {
 	//	time.Sleep(7 * time.Second)
 	//
-	//	trLate := network.NewLocalTransport("LATE_REMOTE")
-	//	trRemoteC.Connect(trLate)
-	//	lateServer := makeServer("LATE_REMOTE", trLate, nil)
+	//	trLate := lnet.Transport("LATE_REMOTE")
+	//	lateServer := makeServer("LATE_REMOTE", genesis, trLate, nil, trRemoteA.Addr())
 	//
 	//	go lateServer.Start()
 	//}()
 
 	// 创建服务器选项并启动服务器
 	// Create server options and start the server
-	privateKey := crypto.GeneratePrivateKey()
-	localServer := makeServer("LOCAL", trLocal, &privateKey)
+	localServer := makeServer("LOCAL", genesis, trLocal, validatorSigner, trRemoteA.Addr())
 	localServer.Start()
 }
 
-// initRemoteServers 初始化远程服务器
-// initRemoteServers initializes remote servers
-func initRemoteServers(trs []network.Transport) {
+// runTCPNode 通过 TCP 传输运行一个节点，拨号连接给定的节点，并经由种子节点和地址簿发现更多节点
+// runTCPNode runs a node over the TCP transport, dials the given peers and discovers more peers through the seed nodes and the address book
+func runTCPNode(genesis *core.Genesis) {
+	tr := network.NewTCPTransport(network.NetAddr(*listenAddr))
+	if err := tr.Listen(); err != nil {
+		log.Fatal(err)
+	}
+	for _, addr := range splitAddrs(*peers) {
+		if err := tr.Dial(addr); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var validator signer.Signer
+	if *validatorKeystore != "" || *remoteSigner != "" {
+		validator = loadSigner()
+	}
+	addrBook := network.NewAddrBook()
+	if *addrBookFile != "" {
+		var err error
+		if addrBook, err = network.OpenAddrBook(*addrBookFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	s, err := network.NewServer(network.ServerOpts{
+		ID:          string(tr.Addr()),
+		Transport:   []network.Transport{tr},
+		Genesis:     genesis,
+		Signer:      validator,
+		Seeds:       splitAddrs(*seeds),
+		MaxOutbound: *maxOutbound,
+		AddrBook:    addrBook,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.Start()
+}
+
+// splitAddrs 拆分逗号分隔的地址列表，忽略空项
+// splitAddrs splits a comma separated address list, ignoring empty entries
+func splitAddrs(list string) []network.NetAddr {
+	var addrs []network.NetAddr
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, network.NetAddr(addr))
+		}
+	}
+	return addrs
+}
+
+// initRemoteServers 初始化远程服务器，它们通过给定的种子节点发现其他节点
+// initRemoteServers initializes remote servers, they discover other peers through the given seed node
+func initRemoteServers(genesis *core.Genesis, trs []network.Transport, seed network.NetAddr) {
 	for i := 0; i < len(trs); i++ {
 		id := fmt.Sprintf("REMOTE_%d", i)
-		s := makeServer(id, trs[i], nil)
+		s := makeServer(id, genesis, trs[i], nil, seed)
 		go s.Start()
 	}
 }
 
+// loadKey 从密钥文件读取私钥，路径为空时生成一个临时私钥
+// loadKey loads the private key from a keystore file, a throwaway key is generated when the path is empty
+func loadKey(path string) *crypto.PrivateKey {
+	if path == "" {
+		privateKey := crypto.GeneratePrivateKey()
+		return &privateKey
+	}
+	privateKey, err := crypto.LoadKeystore(path, readPassword())
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &privateKey
+}
+
+// loadSigner 连接远程签名服务，未指定时使用带双签保护的本地验证者私钥
+// loadSigner connects to the remote signer daemon, the local validator key behind a double sign guard is used when none is given
+func loadSigner() signer.Signer {
+	if *remoteSigner == "" {
+		return openGuard(signer.NewLocalSigner(*loadKey(*validatorKeystore)))
+	}
+	s, err := signer.DialRemoteSigner(*remoteSigner, readSignerSecret())
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+// openGuard 用签名记录文件创建双签保护，未指定文件时签名记录只保存在内存中
+// openGuard creates the double sign guard with the sign record file, the sign record is only kept in memory when no file is given
+func openGuard(s signer.Signer) *signer.Guard {
+	g := signer.NewGuard(s)
+	if *signState != "" {
+		var err error
+		if g, err = signer.OpenGuard(s, *signState); err != nil {
+			log.Fatal(err)
+		}
+	}
+	g.SetMaxHeightGap(uint32(*signMaxGap))
+	return g
+}
+
+// readSignerSecret 读取签名服务与验证者共享的密钥，未指定文件时返回空
+// readSignerSecret reads the secret shared by the signer daemon and the validator, nil when no file is given
+func readSignerSecret() []byte {
+	if *signerSecret == "" {
+		return nil
+	}
+	secret, err := signer.ReadSecret(*signerSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return secret
+}
+
+// readPassword 从口令文件或环境变量读取密钥文件口令
+// readPassword reads the keystore password from the password file or the environment
+func readPassword() string {
+	if *passwordFile == "" {
+		return os.Getenv(passwordEnv)
+	}
+	data, err := os.ReadFile(*passwordFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
 // makeServer 创建并返回一个新的服务器实例
 // makeServer creates and returns a new server instance
-func makeServer(id string, tr network.Transport, pk *crypto.PrivateKey) *network.Server {
-	opts := network.ServerOpts{PrivateKey: pk, ID: id, Transport: []network.Transport{tr}}
+func makeServer(id string, genesis *core.Genesis, tr network.Transport, validator signer.Signer, seeds ...network.NetAddr) *network.Server {
+	opts := network.ServerOpts{Signer: validator, ID: id, Transport: []network.Transport{tr}, Genesis: genesis, Seeds: seeds}
 	s, err := network.NewServer(opts)
 	if err != nil {
 		log.Fatal(err)
@@ -85,22 +284,22 @@ func makeServer(id string, tr network.Transport, pk *crypto.PrivateKey) *network
 
 // sendTransaction 函数生成并发送一笔交易
 // sendTransaction function generates and sends a transaction
-func sendTransaction(tr network.Transport, to network.NetAddr) error {
-	// 生成私钥
-	// Generate private key
-	privateKey := crypto.GeneratePrivateKey()
-
+func sendTransaction(chainID uint32, privateKey crypto.PrivateKey, nonce uint64, tr network.Transport, to network.NetAddr) error {
 	// 创建交易数据
 	// Create transaction data
 	data := []byte{0x03, 0x0a, 0x46, 0x0c, 0x4f, 0x0c, 0x4f, 0x0c, 0x0d, 0x05, 0x0a, 0x0f}
 
-	// 创建新交易
-	// Create a new transaction
-	tx := core.NewTransaction(data)
+	// 创建部署合约代码的交易
+	// Create a transaction deploying the contract code
+	tx := core.NewDeployTransaction(data)
+	tx.ChainID = chainID
+	tx.Nonce = nonce
 
 	// 使用私钥签名交易
 	// Sign the transaction with the private key
-	tx.Sign(privateKey)
+	if err := tx.Sign(privateKey); err != nil {
+		return err
+	}
 
 	// 创建缓冲区并编码交易
 	// Create a buffer and encode the transaction
@@ -0,0 +1,344 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	maxAddrBookSize   = 1000 // 地址簿最多保存的地址数量 // Maximum number of addresses kept in the address book
+	maxAddrAttempts   = 10   // 连续失败多少次后删除地址 // Consecutive failures after which an address is removed
+	maxAddrsPerSource = 64   // 每个主机告知且尚未确认的地址最多保存的数量 // Maximum number of unconfirmed addresses kept per host that told us about them
+)
+
+// addrGossipPenalty 定义节点告知的在线时间被推后的时长，使本节点确认过的地址排在节点告知的地址之前
+// addrGossipPenalty defines how much the last seen time told by a peer is set back, so the addresses we confirmed rank before the ones told by peers
+var addrGossipPenalty = 2 * time.Hour
+
+// addrRetryInterval 定义连接失败后再次尝试前的等待时间，按失败次数成倍增加
+// addrRetryInterval defines the wait before an address is tried again after a failure, multiplied by the number of failures
+var addrRetryInterval = 30 * time.Second
+
+// KnownAddr 结构体记录地址簿中的一个节点地址
+// KnownAddr struct records one peer address of the address book
+type KnownAddr struct {
+	Addr        NetAddr   `json:"addr"`             // 节点地址 // Peer address
+	LastSeen    time.Time `json:"lastSeen"`         // 最近确认在线的时间，从未连接时为零 // Last time the peer was seen online, zero when never connected
+	LastAttempt time.Time `json:"lastAttempt"`      // 最近尝试连接的时间 // Last connection attempt
+	Attempts    int       `json:"attempts"`         // 连续失败的连接次数 // Consecutive failed connection attempts
+	Seed        bool      `json:"seed,omitempty"`   // 是否为种子节点，种子节点不会被淘汰或因失败被删除 // Whether the address is a seed, seeds are never evicted or removed for failing
+	Source      string    `json:"source,omitempty"` // 告知该地址的节点的主机，地址被确认后为空 // Host of the peer that told us about the address, empty once the address was confirmed
+}
+
+// AddrBook 结构体保存已知的节点地址及其最近在线时间，指定文件时可以持久化
+// AddrBook struct keeps the known peer addresses and when they were last seen, it is persisted when a file is given
+type AddrBook struct {
+	lock    sync.Mutex             // 保护地址簿的互斥锁 // Mutex guarding the address book
+	path    string                 // 地址簿文件，为空时只保存在内存中 // Address book file, only kept in memory when empty
+	dirty   bool                   // 上次保存后是否有修改 // Whether the book changed since it was saved
+	addrs   map[NetAddr]*KnownAddr // 已知的地址 // Known addresses
+	sources map[string]int         // 每个主机告知且尚未确认的地址数量 // Number of unconfirmed addresses told by each host
+}
+
+// NewAddrBook 创建一个只保存在内存中的地址簿
+// NewAddrBook creates an address book only kept in memory
+func NewAddrBook() *AddrBook {
+	return &AddrBook{addrs: make(map[NetAddr]*KnownAddr), sources: make(map[string]int)}
+}
+
+// OpenAddrBook 创建一个保存在文件中的地址簿，并读取文件中已有的地址，文件不存在时从空地址簿开始
+// OpenAddrBook creates an address book kept in the file and reads the addresses already in it, starting empty when the file does not exist
+func OpenAddrBook(path string) (*AddrBook, error) {
+	book := NewAddrBook()
+	book.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var addrs []*KnownAddr
+	if err := json.Unmarshal(data, &addrs); err != nil {
+		return nil, fmt.Errorf("invalid address book %s: %w", path, err)
+	}
+	for _, ka := range addrs {
+		book.addrs[ka.Addr] = ka
+		if ka.Source != "" {
+			book.sources[ka.Source]++
+		}
+	}
+	return book, nil
+}
+
+// Add 方法加入一个本节点确认的地址，已知的地址只更新为更近的在线时间，地址簿已满时先移除最久未在线的未确认地址，种子地址不会被移除
+// Add method adds an address confirmed by this node, known addresses only take a more recent last seen time, when the book is full the least recently seen unconfirmed address is dropped first and seeds are never dropped
+func (b *AddrBook) Add(addr NetAddr, lastSeen time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.add(addr, lastSeen, "")
+}
+
+// AddSeed 方法将地址作为种子加入，种子地址一直保留在地址簿中
+// AddSeed method adds the address as a seed, seed addresses stay in the address book
+func (b *AddrBook) AddSeed(addr NetAddr) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ka := b.add(addr, time.Time{}, "")
+	if ka == nil {
+		ka = &KnownAddr{Addr: addr}
+		b.addrs[addr] = ka
+	}
+	b.confirm(ka)
+	ka.Seed = true
+	b.dirty = true
+}
+
+// AddFrom 方法加入节点告知的地址，在线时间不晚于当前时间并被推后 addrGossipPenalty，同一主机的节点最多加入 maxAddrsPerSource 个尚未确认的地址，告知的地址只会替换其他未确认的地址
+// AddFrom method adds an address told by a peer, the last seen time is no later than now and set back by addrGossipPenalty, the peers of one host add at most maxAddrsPerSource unconfirmed addresses, told addresses only replace other unconfirmed ones
+func (b *AddrBook) AddFrom(source, addr NetAddr, lastSeen time.Time) {
+	if !lastSeen.IsZero() {
+		if now := time.Now(); lastSeen.After(now) {
+			lastSeen = now
+		}
+		lastSeen = lastSeen.Add(-addrGossipPenalty)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	host := source.Host()
+	if _, ok := b.addrs[addr]; !ok && b.sources[host] >= maxAddrsPerSource {
+		return
+	}
+	b.add(addr, lastSeen, host)
+}
+
+// add 方法在持有锁时加入地址，返回地址簿中的地址，地址簿已满且没有可以移除的地址时返回空
+// add method adds the address while the lock is held and returns the entry in the book, nil when the book is full and nothing may be dropped
+func (b *AddrBook) add(addr NetAddr, lastSeen time.Time, source string) *KnownAddr {
+	if ka, ok := b.addrs[addr]; ok {
+		if lastSeen.After(ka.LastSeen) {
+			ka.LastSeen = lastSeen
+			b.dirty = true
+		}
+		return ka
+	}
+	if len(b.addrs) >= maxAddrBookSize {
+		oldest := b.oldest(true)
+		// 节点告知的地址不能替换本节点确认过的地址 // Addresses told by peers cannot replace the ones we confirmed
+		if oldest == nil && source == "" {
+			oldest = b.oldest(false)
+		}
+		if oldest == nil {
+			return nil
+		}
+		b.remove(oldest)
+	}
+	ka := &KnownAddr{Addr: addr, LastSeen: lastSeen, Source: source}
+	b.addrs[addr] = ka
+	if source != "" {
+		b.sources[source]++
+	}
+	b.dirty = true
+	return ka
+}
+
+// oldest 方法在持有锁时返回最久未在线的未确认或已确认的非种子地址，没有时返回空
+// oldest method returns the least recently seen unconfirmed or confirmed address that is not a seed while the lock is held, nil when there is none
+func (b *AddrBook) oldest(unconfirmed bool) *KnownAddr {
+	var oldest *KnownAddr
+	for _, ka := range b.addrs {
+		if ka.Seed || (ka.Source != "") != unconfirmed {
+			continue
+		}
+		if oldest == nil || ka.LastSeen.Before(oldest.LastSeen) {
+			oldest = ka
+		}
+	}
+	return oldest
+}
+
+// Good 方法记录节点刚刚完成握手，更新在线时间并清除失败次数
+// Good method records that the peer just completed its handshake, updating the last seen time and clearing the failures
+func (b *AddrBook) Good(addr NetAddr) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ka, ok := b.addrs[addr]
+	if !ok {
+		ka = &KnownAddr{Addr: addr}
+		b.addrs[addr] = ka
+	}
+	b.confirm(ka)
+	ka.LastSeen, ka.Attempts = time.Now(), 0
+	b.dirty = true
+}
+
+// Attempt 方法记录一次连接尝试，握手成功前都算作失败，连续失败过多的地址被删除，种子地址只停留在最长的重试等待时间
+// Attempt method records a connection attempt, it counts as a failure until the handshake succeeds, addresses failing too often are removed while seeds stay at the longest retry wait
+func (b *AddrBook) Attempt(addr NetAddr) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ka, ok := b.addrs[addr]
+	if !ok {
+		return
+	}
+	ka.LastAttempt = time.Now()
+	ka.Attempts++
+	if ka.Attempts > maxAddrAttempts {
+		if ka.Seed {
+			ka.Attempts = maxAddrAttempts
+		} else {
+			b.remove(ka)
+		}
+	}
+	b.dirty = true
+}
+
+// Remove 方法删除一个地址
+// Remove method removes an address
+func (b *AddrBook) Remove(addr NetAddr) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if ka, ok := b.addrs[addr]; ok {
+		b.remove(ka)
+		b.dirty = true
+	}
+}
+
+// remove 方法在持有锁时删除地址，并从告知它的主机的计数中减去
+// remove method removes the address while the lock is held and takes it off the count of the host that told us about it
+func (b *AddrBook) remove(ka *KnownAddr) {
+	b.confirm(ka)
+	delete(b.addrs, ka.Addr)
+}
+
+// confirm 方法在持有锁时将地址从告知它的主机的计数中移除，地址不再占用该主机的名额
+// confirm method takes the address off the count of the host that told us about it while the lock is held, the address no longer uses up that host's share
+func (b *AddrBook) confirm(ka *KnownAddr) {
+	if ka.Source == "" {
+		return
+	}
+	if b.sources[ka.Source]--; b.sources[ka.Source] <= 0 {
+		delete(b.sources, ka.Source)
+	}
+	ka.Source = ""
+}
+
+// Len 方法返回地址的数量
+// Len method returns the number of addresses
+func (b *AddrBook) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.addrs)
+}
+
+// Recent 方法返回最多 n 个确认过在线的地址，按在线时间从新到旧排列，跳过 skip 返回 true 的地址
+// Recent method returns at most n addresses that were seen online, from the most to the least recently seen, skipping the ones skip returns true for
+func (b *AddrBook) Recent(n int, skip func(NetAddr) bool) []PeerAddr {
+	addrs := []PeerAddr{}
+	for _, ka := range b.sorted() {
+		if len(addrs) >= n {
+			break
+		}
+		if ka.LastSeen.IsZero() || skip(ka.Addr) {
+			continue
+		}
+		addrs = append(addrs, PeerAddr{Addr: ka.Addr, LastSeen: ka.LastSeen.Unix()})
+	}
+	return addrs
+}
+
+// Candidates 方法返回最多 n 个可以尝试连接的地址，最近在线的优先，跳过仍在等待重试和 skip 返回 true 的地址
+// Candidates method returns at most n addresses worth dialing, the most recently seen first, skipping the ones still waiting for a retry and the ones skip returns true for
+func (b *AddrBook) Candidates(n int, skip func(NetAddr) bool) []NetAddr {
+	addrs := []NetAddr{}
+	for _, ka := range b.sorted() {
+		if len(addrs) >= n {
+			break
+		}
+		if time.Since(ka.LastAttempt) < time.Duration(ka.Attempts)*addrRetryInterval || skip(ka.Addr) {
+			continue
+		}
+		addrs = append(addrs, ka.Addr)
+	}
+	return addrs
+}
+
+// Save 方法在有修改时将地址簿写入文件，先写入临时文件再重命名覆盖原文件
+// Save method writes the address book to its file when it changed, through a temporary file renamed over the original one
+func (b *AddrBook) Save() error {
+	b.lock.Lock()
+	if b.path == "" || !b.dirty {
+		b.lock.Unlock()
+		return nil
+	}
+	b.dirty = false
+	b.lock.Unlock()
+
+	if err := b.write(); err != nil {
+		// 下次再尝试保存 // Try saving again next time
+		b.lock.Lock()
+		b.dirty = true
+		b.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// write 方法将所有地址写入地址簿文件
+// write method writes all addresses to the address book file
+func (b *AddrBook) write() error {
+	data, err := json.MarshalIndent(b.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// sorted 方法返回所有地址的副本，按在线时间从新到旧排列
+// sorted method returns copies of all addresses, from the most to the least recently seen
+func (b *AddrBook) sorted() []KnownAddr {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	addrs := make([]KnownAddr, 0, len(b.addrs))
+	for _, ka := range b.addrs {
+		addrs = append(addrs, *ka)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		if !addrs[i].LastSeen.Equal(addrs[j].LastSeen) {
+			return addrs[i].LastSeen.After(addrs[j].LastSeen)
+		}
+		return addrs[i].Addr < addrs[j].Addr
+	})
+	return addrs
+}
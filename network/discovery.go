@@ -0,0 +1,158 @@
+package network
+
+import (
+	"time"
+)
+
+// defaultMaxOutbound 定义默认维持的出站连接数量
+// defaultMaxOutbound defines the default number of outbound connections maintained
+const defaultMaxOutbound = 8
+
+// discoveryInterval 定义检查出站连接数量并连接新节点的时间间隔
+// discoveryInterval defines the interval of checking the outbound connection count and dialing new peers
+var discoveryInterval = 5 * time.Second
+
+// outboundTimeout 定义出站连接在多长时间内没有建立就放弃该地址
+// outboundTimeout defines how long an outbound connection may take to establish before the address is given up
+var outboundTimeout = 20 * time.Second
+
+// outboundPeer 结构体记录由节点发现拨出的连接
+// outboundPeer struct records a connection dialed by peer discovery
+type outboundPeer struct {
+	connected bool      // 连接是否已建立 // Whether the connection is established
+	since     time.Time // 拨号或断开的时间 // Time of dialing or of the disconnect
+}
+
+// maintainPeers 方法放弃迟迟未建立的出站连接，并从地址簿选择节点补足出站连接数量，最后保存地址簿
+// maintainPeers method gives up outbound connections that never got established, dials peers from the address book until the outbound count is reached and finally saves the address book
+func (s *Server) maintainPeers() error {
+	dialer := s.dialer()
+	if dialer == nil {
+		return s.AddrBook.Save()
+	}
+
+	s.peerLock.Lock()
+	// 清除到期的拒绝 // Clear the expired rejections
+	now := time.Now()
+	for addr, until := range s.rejectedPeers {
+		if !now.Before(until) {
+			delete(s.rejectedPeers, addr)
+		}
+	}
+	var stale []NetAddr
+	for addr, o := range s.outbound {
+		_, rejected := s.rejectedPeers[addr]
+		if rejected || (!o.connected && time.Since(o.since) > outboundTimeout) {
+			delete(s.outbound, addr)
+			stale = append(stale, addr)
+		}
+	}
+	need := s.MaxOutbound - len(s.outbound)
+	known := make(map[NetAddr]struct{}, len(s.peers)+len(s.outbound)+len(s.rejectedPeers))
+	for addr := range s.peers {
+		known[addr] = struct{}{}
+	}
+	for addr := range s.outbound {
+		known[addr] = struct{}{}
+	}
+	for addr := range s.rejectedPeers {
+		known[addr] = struct{}{}
+	}
+	s.peerLock.Unlock()
+
+	// 停止重连放弃的地址 // Stop redialing the addresses given up
+	for _, addr := range stale {
+		dialer.Disconnect(addr)
+	}
+
+	if need > 0 {
+		skip := func(addr NetAddr) bool {
+			_, ok := known[addr]
+			return ok || s.isOwnAddr(addr)
+		}
+		candidates := s.AddrBook.Candidates(need, skip)
+		// 地址不够时向已连接的节点请求更多地址 // Ask the connected peers for more addresses when there are not enough
+		if len(candidates) < need {
+			for addr, p := range s.readyPeers() {
+				s.sendGob(addr, p, MessageTypeGetAddr, &GetAddrMessage{Max: MaxAddrs})
+			}
+		}
+		for _, addr := range candidates {
+			s.AddrBook.Attempt(addr)
+			if err := dialer.Dial(addr); err != nil {
+				s.Logger.Log("msg", "dialing peer failed", "peer", addr, "err", err)
+				continue
+			}
+			s.peerLock.Lock()
+			s.outbound[addr] = &outboundPeer{since: time.Now()}
+			s.peerLock.Unlock()
+		}
+	}
+	return s.AddrBook.Save()
+}
+
+// updateOutbound 方法在出站连接建立或断开时更新它的状态
+// updateOutbound method updates the state of an outbound connection when it is established or drops
+func (s *Server) updateOutbound(ev PeerEvent) {
+	s.peerLock.Lock()
+	defer s.peerLock.Unlock()
+
+	if o, ok := s.outbound[ev.Addr]; ok {
+		o.connected, o.since = ev.Connected, time.Now()
+	}
+}
+
+// OutboundCount 方法返回由节点发现维持的出站连接数量，包括正在建立的连接
+// OutboundCount method returns the number of outbound connections maintained by peer discovery, including the ones being established
+func (s *Server) OutboundCount() int {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	return len(s.outbound)
+}
+
+// processGetAddr 方法回复最近在线的节点地址，不包括请求者本身
+// processGetAddr method replies with the most recently seen peer addresses, not including the requester
+func (s *Server) processGetAddr(from NetAddr, m *GetAddrMessage) error {
+	addrs := s.AddrBook.Recent(int(min(m.Max, MaxAddrs)), func(addr NetAddr) bool {
+		return addr == from || s.isRejected(addr)
+	})
+	return s.reply(from, MessageTypeAddr, &AddrMessage{Addrs: addrs})
+}
+
+// processAddr 方法将节点发来的地址作为该节点告知的地址加入地址簿，跳过本节点和被拒绝节点的地址
+// processAddr method adds the addresses sent by a peer to the address book as told by that peer, skipping our own and rejected addresses
+func (s *Server) processAddr(from NetAddr, m *AddrMessage) error {
+	for i, a := range m.Addrs {
+		if uint32(i) >= MaxAddrs {
+			break
+		}
+		if a.Addr == "" || s.isOwnAddr(a.Addr) || s.isRejected(a.Addr) {
+			continue
+		}
+		s.AddrBook.AddFrom(from, a.Addr, time.Unix(a.LastSeen, 0))
+	}
+	return nil
+}
+
+// dialer 方法返回第一个可以按地址拨号的传输，没有时返回空
+// dialer method returns the first transport that can dial by address, nil when there is none
+func (s *Server) dialer() Dialer {
+	for _, tr := range s.Transport {
+		if d, ok := tr.(Dialer); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// isOwnAddr 方法检查地址是否属于本节点的某个传输
+// isOwnAddr method checks if the address belongs to one of our transports
+func (s *Server) isOwnAddr(addr NetAddr) bool {
+	for _, tr := range s.Transport {
+		if tr.Addr() == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"fmt"
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 缩短节点发现的检查间隔和出站连接超时，在任何测试服务器启动前设置 // Shorten the discovery interval and the outbound timeout, set before any test server starts
+func init() {
+	discoveryInterval = 50 * time.Millisecond
+	outboundTimeout = 500 * time.Millisecond
+}
+
+// TestAddrBook 测试地址簿按在线时间排序，并在连接失败后等待重试
+// TestAddrBook tests that the address book orders by last seen time and waits before retrying failed addresses
+func TestAddrBook(t *testing.T) {
+	book := NewAddrBook()
+	now := time.Now()
+	book.Add("A", now.Add(-time.Hour))
+	book.Add("B", now)
+	book.Add("C", time.Time{})
+	book.Add("A", now.Add(-2*time.Hour))
+	assert.Equal(t, 3, book.Len())
+
+	none := func(NetAddr) bool { return false }
+	assert.Equal(t, []NetAddr{"B", "A", "C"}, book.Candidates(10, none))
+	assert.Equal(t, []NetAddr{"B"}, book.Candidates(1, none))
+	assert.Equal(t, []NetAddr{"A", "C"}, book.Candidates(10, func(addr NetAddr) bool { return addr == "B" }))
+
+	// 从未连接过的地址不会被分享 // Addresses never connected to are not shared
+	assert.Equal(t, []PeerAddr{{Addr: "B", LastSeen: now.Unix()}, {Addr: "A", LastSeen: now.Add(-time.Hour).Unix()}}, book.Recent(10, none))
+
+	book.Attempt("B")
+	assert.Equal(t, []NetAddr{"A", "C"}, book.Candidates(10, none))
+	book.Good("B")
+	assert.Equal(t, []NetAddr{"B", "A", "C"}, book.Candidates(10, none))
+
+	for i := 0; i <= maxAddrAttempts; i++ {
+		book.Attempt("C")
+	}
+	assert.Equal(t, 2, book.Len())
+	book.Remove("A")
+	assert.Equal(t, 1, book.Len())
+}
+
+// TestAddrBookSeeds 测试种子地址不会因连续失败被删除，地址簿已满时也不会被淘汰
+// TestAddrBookSeeds tests that seed addresses are neither removed for failing nor evicted when the book is full
+func TestAddrBookSeeds(t *testing.T) {
+	book := NewAddrBook()
+	book.AddSeed("SEED")
+	for i := 0; i <= 2*maxAddrAttempts; i++ {
+		book.Attempt("SEED")
+	}
+	assert.Equal(t, 1, book.Len())
+	assert.Equal(t, maxAddrAttempts, book.sorted()[0].Attempts)
+
+	now := time.Now()
+	for i := 0; i < 2*maxAddrBookSize; i++ {
+		book.Add(NetAddr(fmt.Sprintf("127.0.0.1:%d", i)), now)
+	}
+	assert.Equal(t, maxAddrBookSize, book.Len())
+	seed := book.sorted()[maxAddrBookSize-1]
+	assert.Equal(t, NetAddr("SEED"), seed.Addr)
+	assert.True(t, seed.Seed)
+}
+
+// TestAddrBookAddFrom 测试节点告知的在线时间被推后，每个节点加入的地址数量有上限，确认后的地址不再占用名额
+// TestAddrBookAddFrom tests that the last seen time told by a peer is set back, the number of addresses each peer adds is capped and confirmed addresses no longer use up the share
+func TestAddrBookAddFrom(t *testing.T) {
+	book := NewAddrBook()
+	now := time.Now()
+	book.Good("A")
+	book.AddFrom("F", "B", now.Add(time.Hour))
+	none := func(NetAddr) bool { return false }
+	assert.Equal(t, []NetAddr{"A", "B"}, book.Candidates(10, none))
+	assert.False(t, book.sorted()[1].LastSeen.After(time.Now().Add(-addrGossipPenalty)))
+
+	for i := 0; i < 2*maxAddrsPerSource; i++ {
+		book.AddFrom("F", NetAddr(fmt.Sprintf("127.0.0.1:%d", i)), now)
+	}
+	assert.Equal(t, 1+maxAddrsPerSource, book.Len())
+	book.AddFrom("G", "C", now)
+	assert.Equal(t, 2+maxAddrsPerSource, book.Len())
+
+	book.Good("B")
+	book.AddFrom("F", "D", now)
+	assert.Equal(t, 3+maxAddrsPerSource, book.Len())
+	book.AddFrom("F", "E", now)
+	assert.Equal(t, 3+maxAddrsPerSource, book.Len())
+}
+
+// TestAddrBookFlood 测试同一主机经由许多端口告知的地址受同一个名额限制，并且不能挤掉本节点确认过的地址
+// TestAddrBookFlood tests that addresses told by one host over many ports share one allowance and cannot push out the addresses we confirmed
+func TestAddrBookFlood(t *testing.T) {
+	book := NewAddrBook()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	for i := 0; i < maxAddrBookSize-maxAddrsPerSource; i++ {
+		book.Add(NetAddr(fmt.Sprintf("10.0.0.%d:3000", i)), old)
+	}
+
+	now := time.Now()
+	for port := 0; port < 100; port++ {
+		source := NetAddr(fmt.Sprintf("192.0.2.1:%d", 40000+port))
+		for i := 0; i < int(MaxAddrs); i++ {
+			book.AddFrom(source, NetAddr(fmt.Sprintf("198.51.100.%d:%d", port, i)), now)
+		}
+	}
+	assert.Equal(t, maxAddrBookSize, book.Len())
+	assert.Equal(t, maxAddrsPerSource, book.sources["192.0.2.1"])
+
+	// 地址簿已满后其他主机告知的地址只替换未确认的地址 // Once the book is full addresses told by other hosts only replace unconfirmed ones
+	for host := 0; host < 100; host++ {
+		source := NetAddr(fmt.Sprintf("203.0.113.%d:3000", host))
+		for i := 0; i < maxAddrsPerSource; i++ {
+			book.AddFrom(source, NetAddr(fmt.Sprintf("198.18.%d.%d:3000", host, i)), now)
+		}
+	}
+	assert.Equal(t, maxAddrBookSize, book.Len())
+	confirmed := 0
+	for _, ka := range book.sorted() {
+		if ka.Source == "" {
+			confirmed++
+		}
+	}
+	assert.Equal(t, maxAddrBookSize-maxAddrsPerSource, confirmed)
+}
+
+// TestAddrBookPersist 测试地址簿保存后可以重新读取
+// TestAddrBookPersist tests that a saved address book can be read again
+func TestAddrBookPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addrbook.json")
+	book, err := OpenAddrBook(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, book.Len())
+
+	lastSeen := time.Now().Add(-time.Minute).Round(time.Second)
+	book.Add("127.0.0.1:3000", lastSeen)
+	book.Add("127.0.0.1:3001", time.Time{})
+	book.Attempt("127.0.0.1:3001")
+	book.AddSeed("127.0.0.1:3002")
+	book.AddFrom("127.0.0.1:3000", "127.0.0.1:3003", time.Time{})
+	assert.Nil(t, book.Save())
+
+	book, err = OpenAddrBook(path)
+	assert.Nil(t, err)
+	addrs := book.sorted()
+	assert.Equal(t, 4, len(addrs))
+	assert.Equal(t, NetAddr("127.0.0.1:3000"), addrs[0].Addr)
+	assert.True(t, lastSeen.Equal(addrs[0].LastSeen))
+	assert.Equal(t, 1, addrs[1].Attempts)
+	assert.True(t, addrs[2].Seed)
+	assert.Equal(t, "127.0.0.1", addrs[3].Source)
+	assert.Equal(t, 1, book.sources["127.0.0.1"])
+}
+
+// TestDiscoveryThroughSeed 测试节点经由种子节点发现彼此并互相连接
+// TestDiscoveryThroughSeed tests that nodes find each other through a seed node and connect
+func TestDiscoveryThroughSeed(t *testing.T) {
+	lnet := NewLocalNetwork()
+	newTestDiscoveryServer(t, "SEED", lnet.Transport("SEED"), 0)
+	b := newTestDiscoveryServer(t, "B", lnet.Transport("B"), 0, "SEED")
+	c := newTestDiscoveryServer(t, "C", lnet.Transport("C"), 0, "SEED")
+
+	assert.Eventually(t, func() bool {
+		return b.isReady("C") && c.isReady("B")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestDiscoveryMaxOutbound 测试节点发现不超过目标出站连接数量
+// TestDiscoveryMaxOutbound tests that peer discovery does not exceed the target outbound count
+func TestDiscoveryMaxOutbound(t *testing.T) {
+	lnet := NewLocalNetwork()
+	newTestDiscoveryServer(t, "A", lnet.Transport("A"), 0)
+	newTestDiscoveryServer(t, "B", lnet.Transport("B"), 0)
+	c := newTestDiscoveryServer(t, "C", lnet.Transport("C"), 1, "A", "B")
+
+	assert.Eventually(t, func() bool {
+		return c.isReady("A") || c.isReady("B")
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(5 * discoveryInterval)
+	assert.Equal(t, 1, c.OutboundCount())
+	assert.Equal(t, 1, len(c.readyPeers()))
+}
+
+// TestDiscoveryGivesUpUnreachable 测试无法建立的出站连接超时后被放弃
+// TestDiscoveryGivesUpUnreachable tests that an outbound connection that cannot be established is given up after the timeout
+func TestDiscoveryGivesUpUnreachable(t *testing.T) {
+	tr := newTestTCPTransport(t)
+	s := newTestDiscoveryServer(t, "A", tr, 0, "127.0.0.1:1")
+
+	assert.Eventually(t, func() bool { return s.OutboundCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return s.OutboundCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, tr.Peers())
+
+	// 失败的地址等待重试，不会立即再次拨号 // The failed address waits for its retry and is not dialed again right away
+	time.Sleep(5 * discoveryInterval)
+	assert.Equal(t, 0, s.OutboundCount())
+}
+
+// newTestDiscoveryServer 创建并启动一个使用给定种子节点的测试服务器
+// newTestDiscoveryServer creates and starts a test server using the given seed nodes
+func newTestDiscoveryServer(t *testing.T, id string, tr Transport, maxOutbound int, seeds ...NetAddr) *Server {
+	s, err := NewServer(ServerOpts{
+		ID:          id,
+		Logger:      log.NewNopLogger(),
+		Transport:   []Transport{tr},
+		Seeds:       seeds,
+		MaxOutbound: maxOutbound,
+	})
+	assert.Nil(t, err)
+	go s.Start()
+	return s
+}
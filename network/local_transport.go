@@ -3,6 +3,7 @@ package network
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -11,17 +12,45 @@ import (
 type LocalTransport struct {
 	addr       NetAddr                     // 传输节点的地址 // Address of the transport node
 	consumerCh chan RPC                    // 用于接收 RPC 消息的通道 // Channel for receiving RPC messages
+	eventCh    chan PeerEvent              // 节点连接和断开事件的通道 // Channel for peer connect and disconnect events
 	lock       sync.RWMutex                // 读写锁，用于并发访问 // Read-write lock for concurrent access
 	peers      map[NetAddr]*LocalTransport // 已连接的传输节点 // Connected transport nodes
+	network    *LocalNetwork               // 可以按地址拨号的本地网络，为空时不能拨号 // Local network dialable by address, dialing is not possible when nil
+}
+
+// LocalNetwork 结构体按地址登记进程内的本地传输，使它们可以像 TCP 传输一样按地址拨号
+// LocalNetwork struct registers in-process local transports by address, so they can be dialed by address like TCP transports
+type LocalNetwork struct {
+	lock       sync.RWMutex                // 保护登记表的读写锁 // Read-write lock guarding the registry
+	transports map[NetAddr]*LocalTransport // 按地址登记的传输 // Transports by address
+}
+
+// NewLocalNetwork 创建一个空的本地网络
+// NewLocalNetwork creates an empty local network
+func NewLocalNetwork() *LocalNetwork {
+	return &LocalNetwork{transports: make(map[NetAddr]*LocalTransport)}
+}
+
+// Transport 方法创建一个登记在本地网络中的本地传输
+// Transport method creates a local transport registered in the local network
+func (n *LocalNetwork) Transport(addr NetAddr) Transport {
+	tr := NewLocalTransport(addr).(*LocalTransport)
+	tr.network = n
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.transports[addr] = tr
+	return tr
 }
 
 // NewLocalTransport 创建并返回一个新的 LocalTransport 实例
 // NewLocalTransport creates and returns a new LocalTransport instance
 func NewLocalTransport(addr NetAddr) Transport {
 	return &LocalTransport{
-		addr:       addr,                              // 设置传输节点的地址 // Set the address of the transport node
-		consumerCh: make(chan RPC, 1024),              // 创建一个带缓冲区的通道 // Create a buffered channel
-		peers:      make(map[NetAddr]*LocalTransport), // 初始化已连接节点的映射 // Initialize the map of connected nodes
+		addr:       addr,                                  // 设置传输节点的地址 // Set the address of the transport node
+		consumerCh: make(chan RPC, 1024),                  // 创建一个带缓冲区的通道 // Create a buffered channel
+		eventCh:    make(chan PeerEvent, peerEventBuffer), // 创建节点事件通道 // Create the peer event channel
+		peers:      make(map[NetAddr]*LocalTransport),     // 初始化已连接节点的映射 // Initialize the map of connected nodes
 	}
 }
 
@@ -31,15 +60,67 @@ func (t *LocalTransport) Consume() <-chan RPC {
 	return t.consumerCh
 }
 
+// PeerEvents 方法返回节点事件通道
+// PeerEvents method returns the peer event channel
+func (t *LocalTransport) PeerEvents() <-chan PeerEvent {
+	return t.eventCh
+}
+
 // Connect 方法连接两个传输节点
 // Connect method connects two transport nodes
 func (t *LocalTransport) Connect(tr Transport) error {
+	// 本地传输只能连接另一个本地传输 // A local transport can only connect to another local transport
+	peer, ok := tr.(*LocalTransport)
+	if !ok {
+		return fmt.Errorf("%s: cannot connect local transport to %T", t.addr, tr)
+	}
+
 	t.lock.Lock() // 加锁以确保线程安全 // Lock to ensure thread safety
 	defer t.lock.Unlock()
 
 	// 将另一个传输节点添加到 peers 映射中
 	// Add the other transport node to the peers map
-	t.peers[tr.Addr()] = tr.(*LocalTransport)
+	t.peers[tr.Addr()] = peer
+	emitPeerEvent(t.eventCh, tr.Addr(), true)
+	return nil
+}
+
+// Dial 方法按地址在本地网络中查找传输节点并双向连接
+// Dial method looks the transport node up by address in the local network and connects both ways
+func (t *LocalTransport) Dial(addr NetAddr) error {
+	if t.network == nil {
+		return fmt.Errorf("%s: cannot dial %s outside a local network", t.addr, addr)
+	}
+	t.network.lock.RLock()
+	peer, ok := t.network.transports[addr]
+	t.network.lock.RUnlock()
+	if !ok || peer == t {
+		return fmt.Errorf("%s: cannot dial %s", t.addr, addr)
+	}
+
+	t.lock.RLock()
+	_, connected := t.peers[addr]
+	t.lock.RUnlock()
+	if connected {
+		return nil
+	}
+	if err := t.Connect(peer); err != nil {
+		return err
+	}
+	return peer.Connect(t)
+}
+
+// Disconnect 方法断开与指定地址的传输节点的连接
+// Disconnect method disconnects the transport node with the given address
+func (t *LocalTransport) Disconnect(addr NetAddr) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.peers[addr]; !ok {
+		return fmt.Errorf("%s: not connected to peer %s", t.addr, addr)
+	}
+	delete(t.peers, addr)
+	emitPeerEvent(t.eventCh, addr, false)
 	return nil
 }
 
@@ -77,6 +158,20 @@ func (t *LocalTransport) Broadcast(payload []byte) error {
 	return nil
 }
 
+// Peers 方法按地址顺序返回已连接的传输节点
+// Peers method returns the connected transport nodes in address order
+func (t *LocalTransport) Peers() []NetAddr {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	addrs := make([]NetAddr, 0, len(t.peers))
+	for addr := range t.peers {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
 // Addr 方法返回传输节点的地址
 // Addr method returns the address of the transport node
 func (t *LocalTransport) Addr() NetAddr {
@@ -0,0 +1,196 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/types"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 孤块池的默认限制 // Default limits of the orphan pool
+const (
+	defaultMaxOrphans     = 128              // 最多保存的孤块数量 // Maximum number of orphans kept
+	defaultMaxOrphanBytes = 16 << 20         // 孤块编码后的最大总大小 // Maximum total encoded size of the orphans
+	defaultOrphanTTL      = 10 * time.Minute // 孤块保存的最长时间 // Maximum time an orphan is kept
+)
+
+// orphan 结构体表示父区块未知的区块
+// orphan struct represents a block whose parent is unknown
+type orphan struct {
+	block *core.Block // 孤块 // The orphan block
+	size  int         // 编码后的大小 // Encoded size
+	added time.Time   // 加入孤块池的时间 // Time the block entered the pool
+}
+
+// OrphanPool 结构体按父区块哈希保存提前到达的区块，数量、总大小和保存时间都有上限，超出时先移除最早的孤块
+// OrphanPool struct keeps blocks that arrived early by their parent hash, the count, total size and age are bounded and the oldest orphans are dropped first
+type OrphanPool struct {
+	lock     sync.Mutex                  // 保护孤块池的互斥锁 // Mutex guarding the pool
+	maxCount int                         // 最多保存的孤块数量 // Maximum number of orphans
+	maxBytes int                         // 孤块编码后的最大总大小 // Maximum total encoded size
+	ttl      time.Duration               // 孤块保存的最长时间 // Maximum age of an orphan
+	size     int                         // 当前孤块编码后的总大小 // Current total encoded size
+	orphans  map[types.Hash]*orphan      // 区块哈希到孤块的索引 // Index from block hash to orphan
+	byPrev   map[types.Hash][]types.Hash // 父区块哈希到子区块哈希的索引 // Index from parent hash to child hashes
+	order    []types.Hash                // 按加入顺序排列的孤块哈希 // Orphan hashes in insertion order
+}
+
+// NewOrphanPool 创建并返回一个新的 OrphanPool 实例
+// NewOrphanPool creates and returns a new instance of OrphanPool
+func NewOrphanPool(maxCount, maxBytes int, ttl time.Duration) *OrphanPool {
+	return &OrphanPool{
+		maxCount: maxCount,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		orphans:  make(map[types.Hash]*orphan),
+		byPrev:   make(map[types.Hash][]types.Hash),
+	}
+}
+
+// Add 方法将区块加入孤块池，已包含或超过大小上限的区块不会加入，返回区块是否被加入
+// Add method adds the block to the pool, blocks already pooled or larger than the size limit are not added, it returns whether the block was added
+func (p *OrphanPool) Add(b *core.Block) bool {
+	buf := &bytes.Buffer{}
+	if err := b.Encode(core.NewProtobufBlockEncoder(buf)); err != nil || buf.Len() > p.maxBytes {
+		return false
+	}
+	hash := b.Hash(core.BlockHasher{})
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.orphans[hash]; ok {
+		return false
+	}
+	// 移除最早的孤块直到有足够的空间 // Drop the oldest orphans until there is enough room
+	for len(p.order) > 0 && (len(p.order) >= p.maxCount || p.size+buf.Len() > p.maxBytes) {
+		p.remove(p.order[0])
+	}
+
+	p.orphans[hash] = &orphan{block: b, size: buf.Len(), added: time.Now()}
+	p.byPrev[b.PrevBlockHash] = append(p.byPrev[b.PrevBlockHash], hash)
+	p.order = append(p.order, hash)
+	p.size += buf.Len()
+	return true
+}
+
+// Contains 方法检查孤块池是否包含指定哈希的区块
+// Contains method checks if the pool contains the block with the given hash
+func (p *OrphanPool) Contains(hash types.Hash) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	_, ok := p.orphans[hash]
+	return ok
+}
+
+// TakeChildren 方法移除并返回以指定区块为父区块的孤块
+// TakeChildren method removes and returns the orphans whose parent is the given block
+func (p *OrphanPool) TakeChildren(parent types.Hash) []*core.Block {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	hashes := p.byPrev[parent]
+	children := make([]*core.Block, 0, len(hashes))
+	for _, hash := range slices.Clone(hashes) {
+		children = append(children, p.orphans[hash].block)
+		p.remove(hash)
+	}
+	return children
+}
+
+// Expire 方法移除超过保存时间或高度不高于给定高度的孤块
+// Expire method drops the orphans older than the TTL or not above the given height
+func (p *OrphanPool) Expire(height uint32) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, hash := range slices.Clone(p.order) {
+		o := p.orphans[hash]
+		if time.Since(o.added) > p.ttl || o.block.Height <= height {
+			p.remove(hash)
+		}
+	}
+}
+
+// Len 方法返回孤块的数量
+// Len method returns the number of orphans
+func (p *OrphanPool) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.orphans)
+}
+
+// remove 方法在持有锁时从所有索引中移除孤块
+// remove method drops the orphan from every index while the lock is held
+func (p *OrphanPool) remove(hash types.Hash) {
+	o, ok := p.orphans[hash]
+	if !ok {
+		return
+	}
+	delete(p.orphans, hash)
+	p.size -= o.size
+
+	prev := o.block.PrevBlockHash
+	p.byPrev[prev] = slices.DeleteFunc(p.byPrev[prev], func(h types.Hash) bool { return h == hash })
+	if len(p.byPrev[prev]) == 0 {
+		delete(p.byPrev, prev)
+	}
+	p.order = slices.DeleteFunc(p.order, func(h types.Hash) bool { return h == hash })
+}
+
+// addOrphan 方法将父区块未知的区块放入孤块池，并向发送节点请求本节点与它之间缺少的区块
+// addOrphan method puts a block with an unknown parent into the orphan pool and requests the blocks missing between our head and it from the sending peer
+func (s *Server) addOrphan(from NetAddr, b *core.Block) error {
+	if err := s.verifyOrphan(b); err != nil {
+		return err
+	}
+	s.updatePeerHead(from, b.Height, b.Hash(core.BlockHasher{}))
+	if !s.orphans.Add(b) {
+		return nil
+	}
+	s.Logger.Log("msg", "orphan block", "hash", b.Hash(core.BlockHasher{}), "height", b.Height, "orphans", s.orphans.Len())
+
+	// 父区块也是孤块时缺少的区块已经请求过 // The missing blocks were requested already when the parent is an orphan too
+	if s.orphans.Contains(b.PrevBlockHash) {
+		return nil
+	}
+	return s.reply(from, MessageTypeGetBlocks, &GetBlocksMessage{From: s.chain.Height() + 1, To: b.Height - 1})
+}
+
+// verifyOrphan 方法在区块进入孤块池前检查链 ID、签名和出块者，出块者按当前的验证者集合检查，缺少的区块改变了集合时孤块会经由同步再次到达
+// verifyOrphan method checks the chain ID, signature and proposer of a block before it enters the orphan pool, the proposer is checked against the current validator set and the orphan arrives again through sync when the missing blocks changed the set
+func (s *Server) verifyOrphan(b *core.Block) error {
+	if chainID := s.chain.ChainID(); b.ChainID != chainID {
+		return fmt.Errorf("orphan block (%s) has chain id (%d) => expected (%d)", b.Hash(core.BlockHasher{}), b.ChainID, chainID)
+	}
+	if err := b.VerifyCached(s.chain.SigCache()); err != nil {
+		return err
+	}
+	if s.chain.ValidatorCount() > 0 && !s.chain.IsValidator(b.Validator.Address()) {
+		return fmt.Errorf("orphan block (%s) proposed by (%s) which is not a validator", b.Hash(core.BlockHasher{}), b.Validator.Address())
+	}
+	return nil
+}
+
+// connectOrphans 方法将等待给定区块的孤块及其后代依次添加到区块链
+// connectOrphans method adds the orphans waiting for the given block and their descendants to the chain in turn
+func (s *Server) connectOrphans(parent *core.Block) {
+	queue := []*core.Block{parent}
+	for len(queue) > 0 {
+		hash := queue[0].Hash(core.BlockHasher{})
+		queue = queue[1:]
+		for _, b := range s.orphans.TakeChildren(hash) {
+			if err := s.chain.AddBlock(b); err != nil {
+				s.Logger.Log("msg", "dropping orphan block", "hash", b.Hash(core.BlockHasher{}), "err", err)
+				continue
+			}
+			go s.broadcastBlock(b)
+			queue = append(queue, b)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestOrphanPoolTakeChildren 测试按父区块哈希取出孤块
+// TestOrphanPoolTakeChildren tests taking orphans out by their parent hash
+func TestOrphanPoolTakeChildren(t *testing.T) {
+	blocks := testChainBlocks(t, 3)
+	p := NewOrphanPool(10, defaultMaxOrphanBytes, time.Minute)
+
+	assert.True(t, p.Add(blocks[2]))
+	assert.True(t, p.Add(blocks[3]))
+	assert.False(t, p.Add(blocks[3]))
+	assert.Equal(t, 2, p.Len())
+	assert.True(t, p.Contains(blocks[2].Hash(core.BlockHasher{})))
+
+	assert.Empty(t, p.TakeChildren(blocks[0].Hash(core.BlockHasher{})))
+	children := p.TakeChildren(blocks[1].Hash(core.BlockHasher{}))
+	assert.Equal(t, []*core.Block{blocks[2]}, children)
+	assert.Equal(t, 1, p.Len())
+	assert.False(t, p.Contains(blocks[2].Hash(core.BlockHasher{})))
+}
+
+// TestOrphanPoolLimits 测试超过数量或大小上限时先移除最早的孤块
+// TestOrphanPoolLimits tests that the oldest orphans are dropped first once the count or size limit is reached
+func TestOrphanPoolLimits(t *testing.T) {
+	blocks := testChainBlocks(t, 4)
+
+	p := NewOrphanPool(2, defaultMaxOrphanBytes, time.Minute)
+	assert.True(t, p.Add(blocks[2]))
+	assert.True(t, p.Add(blocks[3]))
+	assert.True(t, p.Add(blocks[4]))
+	assert.Equal(t, 2, p.Len())
+	assert.False(t, p.Contains(blocks[2].Hash(core.BlockHasher{})))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, blocks[2].Encode(core.NewProtobufBlockEncoder(buf)))
+	p = NewOrphanPool(10, buf.Len()+1, time.Minute)
+	assert.True(t, p.Add(blocks[2]))
+	assert.True(t, p.Add(blocks[3]))
+	assert.Equal(t, 1, p.Len())
+	assert.True(t, p.Contains(blocks[3].Hash(core.BlockHasher{})))
+
+	p = NewOrphanPool(10, buf.Len()-1, time.Minute)
+	assert.False(t, p.Add(blocks[2]))
+}
+
+// TestOrphanPoolExpire 测试移除过期和已不高于链头的孤块
+// TestOrphanPoolExpire tests dropping orphans that expired or are no longer above the head
+func TestOrphanPoolExpire(t *testing.T) {
+	blocks := testChainBlocks(t, 3)
+
+	p := NewOrphanPool(10, defaultMaxOrphanBytes, time.Minute)
+	p.Add(blocks[2])
+	p.Add(blocks[3])
+	p.Expire(2)
+	assert.Equal(t, 1, p.Len())
+	assert.True(t, p.Contains(blocks[3].Hash(core.BlockHasher{})))
+
+	p = NewOrphanPool(10, defaultMaxOrphanBytes, time.Millisecond)
+	p.Add(blocks[2])
+	time.Sleep(5 * time.Millisecond)
+	p.Expire(0)
+	assert.Equal(t, 0, p.Len())
+}
+
+// TestConnectOrphans 测试父区块到达后依次连接缓存的后代区块
+// TestConnectOrphans tests that the buffered descendants are connected in turn once the parent arrives
+func TestConnectOrphans(t *testing.T) {
+	blocks := testChainBlocks(t, 3)
+	s := newTestChainServer(t, "B", nil, 0)
+
+	// 无法向未知节点请求缺少的区块，但区块仍然被缓存 // The missing blocks cannot be requested from an unknown peer but the blocks are still buffered
+	assert.NotNil(t, s.processBlock("A", blocks[2]))
+	assert.Nil(t, s.processBlock("A", blocks[3]))
+	assert.Equal(t, 2, s.orphans.Len())
+	assert.Equal(t, uint32(0), s.chain.Height())
+
+	assert.Nil(t, s.processBlock("A", blocks[1]))
+	assert.Equal(t, uint32(3), s.chain.Height())
+	assert.Equal(t, 0, s.orphans.Len())
+}
+
+// TestOrphanVerified 测试签名无效或出块者不是验证者的区块不会进入孤块池
+// TestOrphanVerified tests that blocks with an invalid signature or proposed by a non validator do not enter the orphan pool
+func TestOrphanVerified(t *testing.T) {
+	blocks := testChainBlocks(t, 2)
+	s := newTestChainServer(t, "B", nil, 0)
+
+	forged := *blocks[2]
+	header := *forged.Header
+	header.Timestamp++
+	forged.Header = &header
+	assert.NotNil(t, s.processBlock("A", &forged))
+	assert.Equal(t, 0, s.orphans.Len())
+
+	genesis := core.DefaultGenesis(defaultChainID)
+	genesis.Validators = []crypto.PublicKey{crypto.GeneratePrivateKey().PublicKey()}
+	v, err := NewServer(ServerOpts{ID: "V", Logger: log.NewNopLogger(), Genesis: genesis})
+	assert.Nil(t, err)
+	assert.NotNil(t, v.processBlock("A", blocks[2]))
+	assert.Equal(t, 0, v.orphans.Len())
+}
+
+// TestOrphanRequestsParent 测试收到孤块后向发送节点请求缺少的父区块
+// TestOrphanRequestsParent tests that the missing parent is requested from the sending peer when an orphan arrives
+func TestOrphanRequestsParent(t *testing.T) {
+	blocks := testChainBlocks(t, 2)
+	trb, trf := connectedLocalTransports("B", "F")
+	b := newTestChainServer(t, "B", trb, 0)
+	go b.Start()
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, gob.NewEncoder(buf).Encode(&HandshakeMessage{
+		Version:     ProtocolVersion,
+		ChainID:     b.chain.ChainID(),
+		GenesisHash: b.chain.GenesisHash(),
+	}))
+	assert.Nil(t, trf.SendMessage(trb.Addr(), NewMessage(MessageTypeHandshake, buf.Bytes()).Bytes()))
+	assert.Eventually(t, func() bool { return b.isReady(trf.Addr()) }, 5*time.Second, 10*time.Millisecond)
+
+	buf = &bytes.Buffer{}
+	assert.Nil(t, blocks[2].Encode(core.NewProtobufBlockEncoder(buf)))
+	assert.Nil(t, trf.SendMessage(trb.Addr(), NewMessage(MessageTypeBlock, buf.Bytes()).Bytes()))
+
+	// 跳过握手和同步请求，等待缺少区块的请求 // Skip the handshake and sync requests and wait for the missing block request
+	var req *GetBlocksMessage
+	for req == nil {
+		select {
+		case rpc := <-trf.Consume():
+			msg, err := DefaultRPCDecodeFunc(rpc)
+			assert.Nil(t, err)
+			req, _ = msg.Data.(*GetBlocksMessage)
+		case <-time.After(5 * time.Second):
+			t.Fatal("missing block was not requested")
+		}
+	}
+	assert.Equal(t, &GetBlocksMessage{From: 1, To: 1}, req)
+
+	data, err := (&BlocksMessage{Blocks: blocks[1:2]}).Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, trf.SendMessage(trb.Addr(), NewMessage(MessageTypeBlocks, data).Bytes()))
+	assert.Eventually(t, func() bool { return b.chain.Height() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// testChainBlocks 创建一条包含给定数量区块的链，并按高度返回包括创世区块在内的所有区块
+// testChainBlocks creates a chain with the given number of blocks and returns all blocks including the genesis block by height
+func testChainBlocks(t *testing.T, n int) []*core.Block {
+	s := newTestChainServer(t, "A", nil, n)
+	blocks := make([]*core.Block, n+1)
+	for height := range blocks {
+		b, err := s.chain.GetBlock(uint32(height))
+		assert.Nil(t, err)
+		blocks[height] = b
+	}
+	return blocks
+}
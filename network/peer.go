@@ -0,0 +1,300 @@
+package network
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/types"
+	"time"
+)
+
+// rejectDuration 定义握手被拒绝的节点在多长时间内不再被接受，之后节点可能已经升级或换了链
+// rejectDuration defines how long a peer whose handshake was rejected is not accepted, after that the peer may have upgraded or switched chains
+var rejectDuration = 10 * time.Minute
+
+// peer 结构体记录已连接节点的握手状态和它报告的链头
+// peer struct records the handshake state of a connected peer and the chain head it reported
+type peer struct {
+	tr       Transport  // 可以到达节点的传输，未知时为空 // Transport the peer is reachable through, nil when unknown
+	sent     bool       // 是否已向节点发送握手消息 // Whether our handshake was sent to the peer
+	ready    bool       // 节点的握手消息是否已被接受 // Whether the handshake of the peer was accepted
+	version  uint32     // 节点的协议版本 // Protocol version of the peer
+	height   uint32     // 节点报告的高度 // Height reported by the peer
+	proven   uint32     // 节点发来的已验证区块的最高高度 // Highest height of a validated block sent by the peer
+	headHash types.Hash // 节点报告的最高区块哈希 // Head block hash reported by the peer
+}
+
+// PeerHeight 方法返回节点最近报告的高度，节点未完成握手时返回 false
+// PeerHeight method returns the height last reported by the peer, false is returned when the peer has not completed its handshake
+func (s *Server) PeerHeight(addr NetAddr) (uint32, bool) {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	p, ok := s.peers[addr]
+	if !ok || !p.ready {
+		return 0, false
+	}
+	return p.height, true
+}
+
+// processPeerEvent 方法在节点连接时发送握手消息，在节点断开时删除其状态
+// processPeerEvent method sends the handshake when a peer connects and drops its state when it disconnects
+func (s *Server) processPeerEvent(tr Transport, ev PeerEvent) error {
+	s.updateOutbound(ev)
+	if !ev.Connected {
+		s.peerLock.Lock()
+		delete(s.peers, ev.Addr)
+		s.peerLock.Unlock()
+		return nil
+	}
+
+	// 不再与被拒绝的节点握手 // Do not handshake with rejected peers again
+	if s.isRejected(ev.Addr) {
+		return nil
+	}
+
+	// 节点的握手消息可能先于连接事件到达，此时保留已有的状态
+	// The handshake of the peer may arrive before the connect event, the existing state is kept in that case
+	s.peerLock.Lock()
+	p, ok := s.peers[ev.Addr]
+	if !ok {
+		p = &peer{}
+		s.peers[ev.Addr] = p
+	}
+	p.tr = tr
+	s.peerLock.Unlock()
+	return s.sendHandshake(ev.Addr, p)
+}
+
+// syncPeers 方法按传输当前的连接核对节点状态，补上因事件通道已满而丢失的连接和断开事件
+// syncPeers method checks the peer state against the current connections of the transports, making up for connect and disconnect events lost to a full event channel
+func (s *Server) syncPeers() {
+	for _, tr := range s.Transport {
+		lister, ok := tr.(PeerLister)
+		if !ok {
+			continue
+		}
+		live := make(map[NetAddr]struct{})
+		for _, addr := range lister.Peers() {
+			live[addr] = struct{}{}
+		}
+
+		var gone, missing []NetAddr
+		s.peerLock.RLock()
+		for addr, p := range s.peers {
+			if _, ok := live[addr]; !ok && p.tr == tr {
+				gone = append(gone, addr)
+			}
+		}
+		for addr := range live {
+			if _, ok := s.peers[addr]; !ok {
+				missing = append(missing, addr)
+			}
+		}
+		s.peerLock.RUnlock()
+
+		for _, addr := range gone {
+			s.Logger.Log("msg", "peer disconnect event lost", "peer", addr)
+			s.processPeerEvent(tr, PeerEvent{Addr: addr, Connected: false})
+		}
+		for _, addr := range missing {
+			if s.isRejected(addr) {
+				continue
+			}
+			s.Logger.Log("msg", "peer connect event lost", "peer", addr)
+			if err := s.processPeerEvent(tr, PeerEvent{Addr: addr, Connected: true}); err != nil {
+				s.Logger.Log("msg", "handshake failed", "peer", addr, "err", err)
+			}
+		}
+	}
+}
+
+// processHandshake 方法检查节点的握手消息，断开协议版本、链 ID 或创世区块不同的节点
+// processHandshake method checks the handshake of a peer and disconnects peers with a different protocol version, chain ID or genesis block
+func (s *Server) processHandshake(from NetAddr, h *HandshakeMessage) error {
+	var err error
+	if h.Version != ProtocolVersion {
+		err = fmt.Errorf("rejecting peer %s with protocol version (%d) => expected (%d)", from, h.Version, ProtocolVersion)
+	} else if chainID := s.chain.ChainID(); h.ChainID != chainID {
+		err = fmt.Errorf("rejecting peer %s with chain id (%d) => expected (%d)", from, h.ChainID, chainID)
+	} else if genesisHash := s.chain.GenesisHash(); h.GenesisHash != genesisHash {
+		err = fmt.Errorf("rejecting peer %s with genesis hash (%s) => expected (%s)", from, h.GenesisHash, genesisHash)
+	}
+
+	s.peerLock.Lock()
+	p, ok := s.peers[from]
+	if err != nil {
+		var tr Transport
+		if ok {
+			tr = p.tr
+		}
+		s.rejectedPeers[from] = time.Now().Add(rejectDuration)
+		delete(s.peers, from)
+		s.peerLock.Unlock()
+		s.disconnect(from, tr)
+		return err
+	}
+	// 只经由单向连接认识的节点没有连接事件 // Peers only known through a one way connection have no connect event
+	if !ok {
+		p = &peer{}
+		s.peers[from] = p
+	}
+	// 已就绪的节点再次握手说明它开始了新的会话，需要重新回复 // A ready peer handshaking again started a new session and needs a new reply
+	if p.ready {
+		p.sent = false
+	}
+	s.peerLock.Unlock()
+
+	// 先回复握手再标记为就绪，保证对方先收到握手再收到区块和交易，无法回复的节点的消息仍然被接受
+	// Reply with our handshake before marking the peer ready, so the peer receives the handshake before any block or transaction, messages of a peer we cannot reply to are still accepted
+	err = s.sendHandshake(from, p)
+
+	s.peerLock.Lock()
+	p.version, p.height, p.headHash, p.ready = h.Version, h.Height, h.HeadHash, true
+	s.peerLock.Unlock()
+
+	s.Logger.Log("msg", "peer handshake completed", "peer", from, "height", h.Height)
+	return err
+}
+
+// updatePeerHead 方法在节点发来的区块通过验证后更新它的高度，该高度由区块证明
+// updatePeerHead method updates the height of a peer once a block it sent passed validation, the height is proven by the block
+func (s *Server) updatePeerHead(from NetAddr, height uint32, hash types.Hash) {
+	s.peerLock.Lock()
+	defer s.peerLock.Unlock()
+
+	p, ok := s.peers[from]
+	if !ok || !p.ready {
+		return
+	}
+	if height > p.height {
+		p.height, p.headHash = height, hash
+	}
+	p.proven = max(p.proven, height)
+}
+
+// behindProvenPeer 方法检查是否有节点用已验证的区块证明了比本节点更高的高度，握手中声称的高度不算
+// behindProvenPeer method checks if a peer proved a height above ours with a validated block, heights only claimed in the handshake do not count
+func (s *Server) behindProvenPeer() bool {
+	height := s.chain.Height()
+	for _, p := range s.readyPeers() {
+		s.peerLock.RLock()
+		proven := p.proven
+		s.peerLock.RUnlock()
+		if proven > height {
+			return true
+		}
+	}
+	return false
+}
+
+// isReady 方法检查节点是否已完成握手
+// isReady method checks if the peer has completed its handshake
+func (s *Server) isReady(from NetAddr) bool {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	p, ok := s.peers[from]
+	return ok && p.ready
+}
+
+// readyPeers 方法返回已完成握手的节点
+// readyPeers method returns the peers that completed their handshake
+func (s *Server) readyPeers() map[NetAddr]*peer {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	peers := make(map[NetAddr]*peer, len(s.peers))
+	for addr, p := range s.peers {
+		if p.ready {
+			peers[addr] = p
+		}
+	}
+	return peers
+}
+
+// sendHandshake 方法向节点发送本节点的握手消息，每个连接只发送一次
+// sendHandshake method sends our handshake to the peer, once per connection
+func (s *Server) sendHandshake(to NetAddr, p *peer) error {
+	s.peerLock.RLock()
+	sent := p.sent
+	s.peerLock.RUnlock()
+	if sent {
+		return nil
+	}
+
+	height := s.chain.Height()
+	head, err := s.chain.GetHeader(height)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(&HandshakeMessage{
+		Version:     ProtocolVersion,
+		ChainID:     s.chain.ChainID(),
+		GenesisHash: s.chain.GenesisHash(),
+		Height:      height,
+		HeadHash:    core.BlockHasher{}.Hash(head),
+		ListenAddr:  s.listenAddr(p),
+	}); err != nil {
+		return err
+	}
+	if err := s.send(to, p, NewMessage(MessageTypeHandshake, buf.Bytes()).Bytes()); err != nil {
+		return err
+	}
+
+	s.peerLock.Lock()
+	p.sent = true
+	s.peerLock.Unlock()
+	return nil
+}
+
+// listenAddr 方法返回可以到达节点的传输的监听地址，传输未知且有多个传输时返回空
+// listenAddr method returns the listen address of the transport reaching the peer, empty when the transport is unknown and there are several transports
+func (s *Server) listenAddr(p *peer) NetAddr {
+	s.peerLock.RLock()
+	tr := p.tr
+	s.peerLock.RUnlock()
+	if tr != nil {
+		return tr.Addr()
+	}
+	if len(s.Transport) == 1 {
+		return s.Transport[0].Addr()
+	}
+	return ""
+}
+
+// send 方法经由节点的传输发送消息，传输未知时依次尝试所有传输并记住成功的那个
+// send method sends the message through the peer's transport, when it is unknown every transport is tried in turn and the one that worked is remembered
+func (s *Server) send(to NetAddr, p *peer, payload []byte) error {
+	s.peerLock.RLock()
+	tr := p.tr
+	s.peerLock.RUnlock()
+	if tr != nil {
+		return tr.SendMessage(to, payload)
+	}
+
+	err := fmt.Errorf("no transport reaches peer %s", to)
+	for _, tr := range s.Transport {
+		if err = tr.SendMessage(to, payload); err == nil {
+			s.peerLock.Lock()
+			p.tr = tr
+			s.peerLock.Unlock()
+			return nil
+		}
+	}
+	return err
+}
+
+// disconnect 方法经由给定的传输断开与节点的连接，传输为空时在所有传输上断开
+// disconnect method disconnects the peer through the given transport, or on every transport when it is nil
+func (s *Server) disconnect(addr NetAddr, tr Transport) {
+	if tr != nil {
+		tr.Disconnect(addr)
+		return
+	}
+	for _, tr := range s.Transport {
+		tr.Disconnect(addr)
+	}
+}
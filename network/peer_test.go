@@ -0,0 +1,170 @@
+package network
+
+import (
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/signer"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestHandshake 测试相同链上的节点完成握手并记录对方的高度
+// TestHandshake tests that peers on the same chain complete the handshake and record each other's height
+func TestHandshake(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	a := newTestServer(t, "A", tra, core.DefaultGenesis(1), nil)
+	b := newTestServer(t, "B", trb, core.DefaultGenesis(1), nil)
+
+	assert.Eventually(t, func() bool {
+		_, okA := a.PeerHeight(trb.Addr())
+		_, okB := b.PeerHeight(tra.Addr())
+		return okA && okB
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestHandshakeRejectsOtherChain 测试链 ID 不同的节点被拒绝并断开
+// TestHandshakeRejectsOtherChain tests that a peer with a different chain ID is rejected and disconnected
+func TestHandshakeRejectsOtherChain(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	a := newTestServer(t, "A", tra, core.DefaultGenesis(1), nil)
+	b := newTestServer(t, "B", trb, core.DefaultGenesis(2), nil)
+
+	// 先收到对方握手的一方拒绝并断开对方 // Whichever side receives the other's handshake first rejects and disconnects it
+	assert.Eventually(t, func() bool {
+		return a.isRejected(trb.Addr()) || b.isRejected(tra.Addr())
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok := a.PeerHeight(trb.Addr())
+	assert.False(t, ok)
+	_, ok = b.PeerHeight(tra.Addr())
+	assert.False(t, ok)
+}
+
+// TestHandshakeListenAddr 测试只有以自己监听地址标识的节点被记录为在线，其他节点声称的监听地址作为从未连接过的地址加入地址簿
+// TestHandshakeListenAddr tests that only peers identified by their own listen address are recorded as online, the listen address claimed by other peers enters the address book as never connected
+func TestHandshakeListenAddr(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	trc := NewLocalTransport("C")
+	tra.Connect(trc)
+	trc.Connect(tra)
+	s := newTestChainServer(t, "A", tra, 0)
+
+	handshake := func(listenAddr NetAddr) *HandshakeMessage {
+		return &HandshakeMessage{
+			Version:     ProtocolVersion,
+			ChainID:     s.chain.ChainID(),
+			GenesisHash: s.chain.GenesisHash(),
+			ListenAddr:  listenAddr,
+		}
+	}
+	assert.Nil(t, s.ProcessMessage(&DecodedMessage{From: trb.Addr(), Data: handshake(trb.Addr())}))
+	assert.Nil(t, s.ProcessMessage(&DecodedMessage{From: trc.Addr(), Data: handshake("D")}))
+
+	addrs := s.AddrBook.sorted()
+	assert.Equal(t, 2, len(addrs))
+	assert.Equal(t, trb.Addr(), addrs[0].Addr)
+	assert.False(t, addrs[0].LastSeen.IsZero())
+	assert.Equal(t, NetAddr("D"), addrs[1].Addr)
+	assert.True(t, addrs[1].LastSeen.IsZero())
+}
+
+// TestHandshakeRejectionExpires 测试拒绝只针对当前连接，到期后节点可以再次握手，地址也留在地址簿中
+// TestHandshakeRejectionExpires tests that the rejection only applies to the current connection, the peer may handshake again once it expired and its address stays in the address book
+func TestHandshakeRejectionExpires(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	s := newTestChainServer(t, "A", tra, 0)
+	s.AddrBook.Good(trb.Addr())
+
+	handshake := &HandshakeMessage{Version: ProtocolVersion, ChainID: s.chain.ChainID() + 1, ListenAddr: trb.Addr()}
+	assert.NotNil(t, s.ProcessMessage(&DecodedMessage{From: trb.Addr(), Data: handshake}))
+	assert.True(t, s.isRejected(trb.Addr()))
+	assert.Equal(t, 1, s.AddrBook.Len())
+
+	// 拒绝到期 // The rejection expires
+	s.peerLock.Lock()
+	s.rejectedPeers[trb.Addr()] = time.Now().Add(-time.Second)
+	s.peerLock.Unlock()
+	assert.False(t, s.isRejected(trb.Addr()))
+
+	tra.Connect(trb)
+	handshake.ChainID, handshake.GenesisHash = s.chain.ChainID(), s.chain.GenesisHash()
+	assert.Nil(t, s.ProcessMessage(&DecodedMessage{From: trb.Addr(), Data: handshake}))
+	assert.True(t, s.isReady(trb.Addr()))
+}
+
+// TestSyncPeersLostEvents 测试节点事件丢失时按传输的连接补上连接和断开
+// TestSyncPeersLostEvents tests that connects and disconnects are made up for from the transport connections when peer events were lost
+func TestSyncPeersLostEvents(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	// 服务器没有启动，节点事件无人处理 // The server is not started, nobody handles the peer events
+	s := newTestChainServer(t, "A", tra, 0)
+
+	s.syncPeers()
+	s.peerLock.RLock()
+	p, ok := s.peers[trb.Addr()]
+	assert.True(t, ok)
+	assert.True(t, p.sent)
+	s.peerLock.RUnlock()
+
+	assert.Nil(t, tra.Disconnect(trb.Addr()))
+	s.syncPeers()
+	s.peerLock.RLock()
+	_, ok = s.peers[trb.Addr()]
+	s.peerLock.RUnlock()
+	assert.False(t, ok)
+}
+
+// TestIgnoreBeforeHandshake 测试握手完成前节点的区块被忽略
+// TestIgnoreBeforeHandshake tests that blocks of a peer are ignored until its handshake completed
+func TestIgnoreBeforeHandshake(t *testing.T) {
+	s, err := NewServer(ServerOpts{ID: "A", Logger: log.NewNopLogger()})
+	assert.Nil(t, err)
+
+	header, err := s.chain.GetHeader(0)
+	assert.Nil(t, err)
+	b, err := core.NewBlockFromPrevHeader(header, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, b.Sign(crypto.GeneratePrivateKey()))
+
+	assert.NotNil(t, s.ProcessMessage(&DecodedMessage{From: "X", Data: b}))
+	assert.Equal(t, uint32(0), s.chain.Height())
+}
+
+// TestPeerHeightFollowsBlocks 测试收到节点的新区块后更新它的高度
+// TestPeerHeightFollowsBlocks tests that the height of a peer is updated when its new blocks arrive
+func TestPeerHeightFollowsBlocks(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	newTestServer(t, "A", tra, core.DefaultGenesis(1), signer.NewLocalSigner(crypto.GeneratePrivateKey()))
+	b := newTestServer(t, "B", trb, core.DefaultGenesis(1), nil)
+
+	assert.Eventually(t, func() bool {
+		height, ok := b.PeerHeight(tra.Addr())
+		return ok && height >= 2 && b.chain.Height() >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// connectedLocalTransports 创建两个双向连接的本地传输
+// connectedLocalTransports creates two local transports connected both ways
+func connectedLocalTransports(a, b NetAddr) (Transport, Transport) {
+	tra, trb := NewLocalTransport(a), NewLocalTransport(b)
+	tra.Connect(trb)
+	trb.Connect(tra)
+	return tra, trb
+}
+
+// newTestServer 创建并启动一个测试服务器，给定签名者时以 100 毫秒的间隔出块
+// newTestServer creates and starts a test server, it proposes a block every 100 milliseconds when a signer is given
+func newTestServer(t *testing.T, id string, tr Transport, genesis *core.Genesis, validator signer.Signer) *Server {
+	s, err := NewServer(ServerOpts{
+		ID:        id,
+		Logger:    log.NewNopLogger(),
+		Transport: []Transport{tr},
+		Genesis:   genesis,
+		BlockTime: 100 * time.Millisecond,
+		Signer:    validator,
+	})
+	assert.Nil(t, err)
+	go s.Start()
+	return s
+}
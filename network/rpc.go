@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/types"
 	"github.com/sirupsen/logrus"
 	"io"
 )
@@ -14,8 +15,16 @@ import (
 type MessageType byte
 
 const (
-	MessageTypeTx    MessageType = 0x1 // 交易消息类型 // Transaction message type
-	MessageTypeBlock MessageType = 0x2 // 区块消息类型 // Block message type
+	MessageTypeTx         MessageType = 0x1 // 交易消息类型 // Transaction message type
+	MessageTypeBlock      MessageType = 0x2 // 区块消息类型 // Block message type
+	MessageTypeHandshake  MessageType = 0x3 // 握手消息类型 // Handshake message type
+	MessageTypeBundle     MessageType = 0x4 // 交易包消息类型 // Bundle message type
+	MessageTypeGetBlocks  MessageType = 0x5 // 请求区块的消息类型 // Block request message type
+	MessageTypeBlocks     MessageType = 0x6 // 区块响应的消息类型 // Block response message type
+	MessageTypeGetHeaders MessageType = 0x7 // 请求区块头的消息类型 // Header request message type
+	MessageTypeHeaders    MessageType = 0x8 // 区块头响应的消息类型 // Header response message type
+	MessageTypeGetAddr    MessageType = 0x9 // 请求节点地址的消息类型 // Peer address request message type
+	MessageTypeAddr       MessageType = 0xa // 节点地址响应的消息类型 // Peer address response message type
 )
 
 // RPC 结构体表示一个远程过程调用
@@ -49,6 +58,109 @@ func (msg *Message) Bytes() []byte {
 	return buf.Bytes()
 }
 
+// ProtocolVersion 是当前的网络协议版本，版本不同的节点会被断开
+// ProtocolVersion is the current network protocol version, peers with a different version are disconnected
+const ProtocolVersion uint32 = 1
+
+// MaxSyncBatch 是一次同步请求最多返回的区块或区块头数量
+// MaxSyncBatch is the maximum number of blocks or headers returned for one sync request
+const MaxSyncBatch uint32 = 128
+
+// MaxAddrs 是一条地址消息最多包含的节点地址数量
+// MaxAddrs is the maximum number of peer addresses in one address message
+const MaxAddrs uint32 = 256
+
+// HandshakeMessage 结构体表示节点连接时交换的握手消息，在握手完成前节点的其他消息会被忽略
+// HandshakeMessage struct represents the handshake message exchanged when nodes connect, other messages of a peer are ignored until its handshake completed
+type HandshakeMessage struct {
+	Version     uint32     // 网络协议版本 // Network protocol version
+	ChainID     uint32     // 节点所在链的 ID // ID of the chain the node runs on
+	GenesisHash types.Hash // 节点的创世区块哈希 // Hash of the genesis block of the node
+	Height      uint32     // 节点当前的高度 // Current height of the node
+	HeadHash    types.Hash // 节点当前最高区块的哈希 // Hash of the node's current head block
+	ListenAddr  NetAddr    // 节点声称的监听地址，未经验证 // Listen address claimed by the node, unverified
+}
+
+// GetBlocksMessage 结构体请求 From 到 To（含）高度的区块，响应最多包含 MaxSyncBatch 个区块
+// GetBlocksMessage struct requests the blocks from height From to To inclusive, the response holds at most MaxSyncBatch blocks
+type GetBlocksMessage struct {
+	From uint32 // 起始高度 // First height
+	To   uint32 // 结束高度 // Last height
+}
+
+// BlocksMessage 结构体是按高度顺序排列的区块响应
+// BlocksMessage struct is a block response in height order
+type BlocksMessage struct {
+	Blocks []*core.Block // 区块列表 // Blocks
+}
+
+// GetHeadersMessage 结构体请求 From 到 To（含）高度的区块头，响应最多包含 MaxSyncBatch 个区块头
+// GetHeadersMessage struct requests the headers from height From to To inclusive, the response holds at most MaxSyncBatch headers
+type GetHeadersMessage struct {
+	From uint32 // 起始高度 // First height
+	To   uint32 // 结束高度 // Last height
+}
+
+// HeadersMessage 结构体是按高度顺序排列的区块头响应
+// HeadersMessage struct is a header response in height order
+type HeadersMessage struct {
+	Headers []*core.Header // 区块头列表 // Headers
+}
+
+// GetAddrMessage 结构体向节点请求最多 Max 个它知道的节点地址
+// GetAddrMessage struct asks a peer for at most Max of the peer addresses it knows
+type GetAddrMessage struct {
+	Max uint32 // 最多返回的地址数量 // Maximum number of addresses returned
+}
+
+// PeerAddr 结构体表示一个节点地址以及最近一次确认它在线的时间
+// PeerAddr struct represents a peer address and the last time it was seen online
+type PeerAddr struct {
+	Addr     NetAddr // 节点地址 // Peer address
+	LastSeen int64   // 最近在线的 Unix 时间（秒） // Unix time in seconds the peer was last seen
+}
+
+// AddrMessage 结构体是节点地址的响应，按最近在线时间从新到旧排列
+// AddrMessage struct is a peer address response, ordered from the most to the least recently seen
+type AddrMessage struct {
+	Addrs []PeerAddr // 节点地址列表 // Peer addresses
+}
+
+// Bytes 方法将区块响应编码为 gob 格式的 protobuf 区块列表
+// Bytes method encodes the block response as a gob encoded list of protobuf blocks
+func (m *BlocksMessage) Bytes() ([]byte, error) {
+	blocks := make([][]byte, len(m.Blocks))
+	for i, b := range m.Blocks {
+		buf := &bytes.Buffer{}
+		if err := b.Encode(core.NewProtobufBlockEncoder(buf)); err != nil {
+			return nil, err
+		}
+		blocks[i] = buf.Bytes()
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(blocks); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBlocksMessage 函数解码区块响应
+// decodeBlocksMessage function decodes a block response
+func decodeBlocksMessage(data []byte) (*BlocksMessage, error) {
+	var blocks [][]byte
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&blocks); err != nil {
+		return nil, err
+	}
+	m := &BlocksMessage{Blocks: make([]*core.Block, len(blocks))}
+	for i, data := range blocks {
+		m.Blocks[i] = new(core.Block)
+		if err := m.Blocks[i].Decode(core.NewProtobufBlockDecoder(bytes.NewReader(data))); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
 // DecodedMessage 结构体表示一个解码后的消息
 // DecodedMessage struct represents a decoded message
 type DecodedMessage struct {
@@ -92,6 +204,54 @@ func DefaultRPCDecodeFunc(rpc RPC) (*DecodedMessage, error) {
 			From: rpc.From,
 			Data: block,
 		}, nil
+	case MessageTypeBundle:
+		bundle := new(core.Bundle)
+		if err := bundle.Decode(core.NewProtobufBundleDecoder(bytes.NewReader(msg.Data))); err != nil {
+			return nil, err
+		}
+		return &DecodedMessage{
+			From: rpc.From,
+			Data: bundle,
+		}, nil
+	case MessageTypeHandshake:
+		handshake := new(HandshakeMessage)
+		if err := gob.NewDecoder(bytes.NewReader(msg.Data)).Decode(handshake); err != nil {
+			return nil, err
+		}
+		return &DecodedMessage{
+			From: rpc.From,
+			Data: handshake,
+		}, nil
+	case MessageTypeBlocks:
+		blocks, err := decodeBlocksMessage(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedMessage{
+			From: rpc.From,
+			Data: blocks,
+		}, nil
+	case MessageTypeGetBlocks, MessageTypeGetHeaders, MessageTypeHeaders, MessageTypeGetAddr, MessageTypeAddr:
+		var data any
+		switch msg.Header {
+		case MessageTypeGetBlocks:
+			data = new(GetBlocksMessage)
+		case MessageTypeGetHeaders:
+			data = new(GetHeadersMessage)
+		case MessageTypeHeaders:
+			data = new(HeadersMessage)
+		case MessageTypeGetAddr:
+			data = new(GetAddrMessage)
+		default:
+			data = new(AddrMessage)
+		}
+		if err := gob.NewDecoder(bytes.NewReader(msg.Data)).Decode(data); err != nil {
+			return nil, err
+		}
+		return &DecodedMessage{
+			From: rpc.From,
+			Data: data,
+		}, nil
 	default:
 		return nil, fmt.Errorf("invalid message type %x", msg.Header)
 	}
@@ -2,12 +2,13 @@ package network
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/go-kit/log"
 	"github.com/lonySp/go-blockchain/core"
-	"github.com/lonySp/go-blockchain/crypto"
-	"github.com/lonySp/go-blockchain/types"
+	"github.com/lonySp/go-blockchain/signer"
 	"github.com/sirupsen/logrus"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -15,16 +16,24 @@ import (
 // defaultBlockTime defines the default interval for block creation
 var defaultBlockTime = 5 * time.Second
 
+// defaultChainID 定义未提供创世配置时使用的链 ID
+// defaultChainID defines the chain ID used when no genesis configuration is given
+var defaultChainID uint32 = 1
+
 // ServerOpts 结构体包含服务器的传输选项
 // ServerOpts struct contains server transport options
 type ServerOpts struct {
-	ID            string             // 服务器的唯一标识符 // Unique identifier for the server
-	Logger        log.Logger         // 日志记录器 // Logger
-	RPCDecodeFunc RPCDecodeFunc      // RPC 解码函数 // RPC decode function
-	RPCProcessor  RPCProcessor       // RPC 处理器 // RPC processor
-	Transport     []Transport        // 传输选项 // Transport options
-	BlockTime     time.Duration      // 区块生成时间间隔 // Block creation time interval
-	PrivateKey    *crypto.PrivateKey // 私钥，用于签名 // Private key for signing
+	ID            string        // 服务器的唯一标识符 // Unique identifier for the server
+	Logger        log.Logger    // 日志记录器 // Logger
+	RPCDecodeFunc RPCDecodeFunc // RPC 解码函数 // RPC decode function
+	RPCProcessor  RPCProcessor  // RPC 处理器 // RPC processor
+	Transport     []Transport   // 传输选项 // Transport options
+	BlockTime     time.Duration // 区块生成时间间隔 // Block creation time interval
+	Signer        signer.Signer // 验证者签名者，为空时不出块 // Validator signer, no blocks are produced when nil
+	Genesis       *core.Genesis // 创世配置 // Genesis configuration
+	Seeds         []NetAddr     // 启动时加入地址簿的种子节点 // Seed nodes added to the address book on startup
+	MaxOutbound   int           // 节点发现维持的出站连接数量 // Number of outbound connections maintained by peer discovery
+	AddrBook      *AddrBook     // 已知节点的地址簿，为空时使用内存中的地址簿 // Address book of known peers, an in-memory book is used when nil
 }
 
 // Server 结构体表示服务器
@@ -33,14 +42,44 @@ type Server struct {
 	ServerOpts                   // 嵌入 ServerOpts 结构体 // Embedding ServerOpts struct
 	chain       *core.Blockchain // 区块链实例 // Blockchain instance
 	memPool     *TxPool          // 交易池 // Transaction pool
+	orphans     *OrphanPool      // 父区块未知的区块 // Blocks whose parent is unknown
 	isValidator bool             // 是否是验证者 // Whether the server is a validator
+	proposal    *core.Block      // 已签名但还未加入链的提议区块，只由验证者循环访问 // Proposal signed but not yet added to the chain, only accessed by the validator loop
 	rpcCh       chan RPC         // 接收 RPC 消息的通道 // Channel for receiving RPC messages
+	peerCh      chan peerEvent   // 接收节点事件的通道 // Channel for receiving peer events
 	quitCh      chan struct{}    // 关闭服务器的通道 // Channel for shutting down the server
+	sync        *syncState       // 从其他节点下载区块的状态 // State of downloading blocks from other peers
+
+	peerLock      sync.RWMutex              // 保护节点状态的读写锁 // Read-write lock guarding the peer state
+	peers         map[NetAddr]*peer         // 已连接的节点 // Connected peers
+	outbound      map[NetAddr]*outboundPeer // 节点发现拨出的连接 // Connections dialed by peer discovery
+	rejectedPeers map[NetAddr]time.Time     // 协议版本、链 ID 或创世区块不同而被拒绝的节点及拒绝到期的时间 // Peers rejected because of a different protocol version, chain ID or genesis block and when the rejection expires
+}
+
+// proposalRecorder 接口由记录了最近签名的提议区块的签名者实现，例如带签名记录文件的双签保护
+// proposalRecorder interface is implemented by signers recording the proposal they signed last, e.g. the double sign guard with a sign record file
+type proposalRecorder interface {
+	Proposal() *core.Block
+}
+
+// peerEvent 结构体表示某个传输上的节点事件
+// peerEvent struct represents a peer event on one of the transports
+type peerEvent struct {
+	tr Transport // 产生事件的传输 // Transport the event came from
+	PeerEvent
 }
 
 // NewServer 创建并返回一个新的 Server 实例
 // NewServer creates and returns a new Server instance
 func NewServer(opts ServerOpts) (*Server, error) {
+	// 设置默认的创世配置 // Set default genesis configuration
+	if opts.Genesis == nil {
+		opts.Genesis = core.DefaultGenesis(defaultChainID)
+	}
+	// 区块生成时间间隔默认使用共识参数 // The block creation time interval defaults to the consensus parameter
+	if opts.BlockTime == time.Duration(0) {
+		opts.BlockTime = opts.Genesis.Consensus.BlockTime
+	}
 	// 设置区块生成时间间隔的默认值 // Set default block creation time interval
 	if opts.BlockTime == time.Duration(0) {
 		opts.BlockTime = defaultBlockTime
@@ -49,6 +88,18 @@ func NewServer(opts ServerOpts) (*Server, error) {
 	if opts.RPCDecodeFunc == nil {
 		opts.RPCDecodeFunc = DefaultRPCDecodeFunc
 	}
+	// 设置默认的出站连接数量 // Set default outbound connection count
+	if opts.MaxOutbound == 0 {
+		opts.MaxOutbound = defaultMaxOutbound
+	}
+	// 设置默认的地址簿 // Set default address book
+	if opts.AddrBook == nil {
+		opts.AddrBook = NewAddrBook()
+	}
+	// 种子节点作为从未连接过的地址加入地址簿，并一直保留 // Seed nodes enter the address book as addresses never connected to and are kept there
+	for _, addr := range opts.Seeds {
+		opts.AddrBook.AddSeed(addr)
+	}
 	// 设置默认的日志记录器 // Set default logger
 	if opts.Logger == nil {
 		opts.Logger = log.NewLogfmtLogger(os.Stderr)
@@ -56,17 +107,24 @@ func NewServer(opts ServerOpts) (*Server, error) {
 	}
 
 	// 创建区块链实例 // Create blockchain instance
-	chain, err := core.NewBlockchain(opts.Logger, genesisBlock())
+	chain, err := core.NewBlockchainFromGenesis(opts.Logger, opts.Genesis)
 	if err != nil {
 		return nil, err
 	}
+
 	s := &Server{
-		ServerOpts:  opts,
-		chain:       chain,
-		memPool:     NewTxPool(1000),
-		isValidator: opts.PrivateKey != nil,
-		quitCh:      make(chan struct{}, 1),
-		rpcCh:       make(chan RPC),
+		ServerOpts:    opts,
+		chain:         chain,
+		memPool:       NewTxPool(1000),
+		orphans:       NewOrphanPool(defaultMaxOrphans, defaultMaxOrphanBytes, defaultOrphanTTL),
+		isValidator:   opts.Signer != nil,
+		quitCh:        make(chan struct{}, 1),
+		rpcCh:         make(chan RPC),
+		peerCh:        make(chan peerEvent),
+		peers:         make(map[NetAddr]*peer),
+		outbound:      make(map[NetAddr]*outboundPeer),
+		rejectedPeers: make(map[NetAddr]time.Time),
+		sync:          newSyncState(),
 	}
 	// 如果未提供 RPC 处理器，使用服务器本身作为默认处理器
 	// If no RPC processor is provided, use the server itself as the default processor
@@ -74,6 +132,12 @@ func NewServer(opts ServerOpts) (*Server, error) {
 		s.RPCProcessor = s
 	}
 
+	// 重启后从签名记录恢复最近签名的提议区块，它可能还没有加入链
+	// After a restart recover the proposal signed last from the sign record, it may never have made it into the chain
+	if r, ok := s.Signer.(proposalRecorder); ok {
+		s.proposal = r.Proposal()
+	}
+
 	// 如果服务器是验证者，启动验证者循环
 	// If the server is a validator, start the validator loop
 	if s.isValidator {
@@ -85,16 +149,44 @@ func NewServer(opts ServerOpts) (*Server, error) {
 // Start 方法启动服务器
 // Start method starts the server
 func (s *Server) Start() {
-	// 初始化传输选项 // Initialize transport options
+	// 初始化传输选项，已连接的节点会收到握手消息 // Initialize transport options, connected peers receive the handshake
 	s.initTransport()
+
+	// 定期检查同步请求是否超时，并在落后时开始同步 // Periodically check sync requests for timeouts and start syncing when behind
+	syncTicker := time.NewTicker(syncInterval)
+	defer syncTicker.Stop()
+
+	// 定期从地址簿补足出站连接 // Periodically dial peers from the address book until the outbound count is reached
+	if err := s.maintainPeers(); err != nil {
+		logrus.Error("Error", err)
+	}
+	discoveryTicker := time.NewTicker(discoveryInterval)
+	defer discoveryTicker.Stop()
+
 free:
 	for {
 		select {
+		case <-syncTicker.C:
+			s.orphans.Expire(s.chain.Height())
+			if err := s.checkSync(); err != nil {
+				logrus.Error("Error", err)
+			}
+		case <-discoveryTicker.C:
+			s.syncPeers()
+			if err := s.maintainPeers(); err != nil {
+				logrus.Error("Error", err)
+			}
+		case ev := <-s.peerCh:
+			// 处理节点连接和断开 // Handle peers connecting and disconnecting
+			if err := s.processPeerEvent(ev.tr, ev.PeerEvent); err != nil {
+				logrus.Error("Error", err)
+			}
 		case rpc := <-s.rpcCh:
 			// 解码 RPC 消息 // Decode the RPC message
 			msg, err := s.RPCDecodeFunc(rpc)
 			if err != nil {
 				logrus.Error("Error", err)
+				continue
 			}
 
 			// 处理解码后的消息 // Process the decoded message
@@ -106,6 +198,9 @@ free:
 			break free
 		}
 	}
+	if err := s.AddrBook.Save(); err != nil {
+		logrus.Error("Error", err)
+	}
 	// 记录服务器关闭日志 // Log server shutdown
 	s.Logger.Log("msg", "Server is shutting down")
 }
@@ -117,40 +212,102 @@ func (s *Server) validatorLoop() {
 	s.Logger.Log("msg", "Starting validator loop", "blockTime", s.BlockTime)
 	for {
 		<-ticker.C
-		s.createNewBlock()
+		// 有节点用区块证明本节点落后时不出块，避免在落后的链头上分叉，未经验证的高度不会阻止出块
+		// Do not propose while a peer proved with a block that we are behind to avoid forking off a stale head, unverified heights do not stop proposing
+		if s.behindProvenPeer() {
+			continue
+		}
+		if err := s.createNewBlock(); err != nil {
+			s.Logger.Log("msg", "proposing block failed", "height", s.chain.Height()+1, "err", err)
+		}
 	}
 }
 
 // ProcessMessage 方法处理解码后的消息
 // ProcessMessage method processes the decoded message
 func (s *Server) ProcessMessage(msg *DecodedMessage) error {
+	// 忽略来自被拒绝节点的消息 // Ignore messages from rejected peers
+	if s.isRejected(msg.From) {
+		return fmt.Errorf("ignoring message from rejected peer %s", msg.From)
+	}
+
+	// 握手完成前忽略节点的其他消息 // Ignore other messages of a peer until its handshake completed
+	if h, ok := msg.Data.(*HandshakeMessage); ok {
+		if err := s.processHandshake(msg.From, h); err != nil {
+			return err
+		}
+		// 节点以自己的监听地址标识时记录它在线，否则声称的监听地址只作为从未连接过的地址加入地址簿，由节点发现拨号确认
+		// Record the peer as online when it is identified by its own listen address, otherwise the claimed listen address only enters the address book as never connected, for peer discovery to confirm by dialing it
+		if h.ListenAddr == msg.From {
+			s.AddrBook.Good(msg.From)
+		} else if h.ListenAddr != "" && !s.isOwnAddr(h.ListenAddr) && !s.isRejected(h.ListenAddr) {
+			s.AddrBook.AddFrom(msg.From, h.ListenAddr, time.Time{})
+		}
+		// 请求节点知道的地址 // Ask for the addresses the peer knows
+		if err := s.reply(msg.From, MessageTypeGetAddr, &GetAddrMessage{Max: MaxAddrs}); err != nil {
+			return err
+		}
+		// 节点比本节点高时开始同步 // Start syncing when the peer is ahead of us
+		return s.startSync()
+	}
+	if !s.isReady(msg.From) {
+		return fmt.Errorf("ignoring message from peer %s before its handshake", msg.From)
+	}
+
 	switch t := msg.Data.(type) {
 	case *core.Transaction:
 		return s.processTransaction(t)
 	case *core.Block:
-		return s.processBlock(t)
+		return s.processBlock(msg.From, t)
+	case *core.Bundle:
+		return s.processBundle(t)
+	case *GetHeadersMessage:
+		return s.processGetHeaders(msg.From, t)
+	case *HeadersMessage:
+		return s.processHeaders(msg.From, t)
+	case *GetBlocksMessage:
+		return s.processGetBlocks(msg.From, t)
+	case *BlocksMessage:
+		return s.processBlocks(msg.From, t)
+	case *GetAddrMessage:
+		return s.processGetAddr(msg.From, t)
+	case *AddrMessage:
+		return s.processAddr(msg.From, t)
 	}
 	return nil
 }
 
-// broadcast 方法将消息广播到所有已连接的传输节点
-// broadcast method broadcasts a message to all connected transport nodes
+// broadcast 方法将消息发送给所有已完成握手的节点，单个节点失败不影响其他节点
+// broadcast method sends a message to every peer that completed its handshake, a failing peer does not affect the others
 func (s *Server) broadcast(payload []byte) error {
-	for _, tr := range s.Transport {
-		if err := tr.Broadcast(payload); err != nil {
-			return err
+	var firstErr error
+	for addr, p := range s.readyPeers() {
+		if err := s.send(addr, p, payload); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
-	return nil
+	return firstErr
 }
 
-// processBlock 方法处理区块
-// processBlock method processes a block
-func (s *Server) processBlock(b *core.Block) error {
+// processBlock 方法处理区块，区块通过验证后记录发送节点的新高度
+// processBlock method processes a block and records the new height of the sending peer once the block passed validation
+func (s *Server) processBlock(from NetAddr, b *core.Block) error {
+	// 父区块未知的区块先放入孤块池，并向节点请求缺少的区块 // Blocks with an unknown parent wait in the orphan pool while the missing blocks are requested from the peer
+	if b.Height > s.chain.Height()+1 {
+		return s.addOrphan(from, b)
+	}
 	if err := s.chain.AddBlock(b); err != nil {
 		return err
 	}
+	s.updatePeerHead(from, b.Height, b.Hash(core.BlockHasher{}))
 	go s.broadcastBlock(b)
+
+	// 连接等待该区块的孤块 // Connect the orphans waiting for this block
+	s.connectOrphans(b)
+
+	// 移除已上链或已失效的交易 // Remove the transactions that were included or became stale
+	s.memPool.Prune(s.chain.GetNonce)
+	s.memPool.Expire(s.chain.Height() + 1)
 	return nil
 }
 
@@ -165,8 +322,13 @@ func (s *Server) processTransaction(tx *core.Transaction) error {
 		return nil
 	}
 
-	// 验证交易 // Verify the transaction
-	if err := tx.Verify(); err != nil {
+	// 验证交易，结果写入签名缓存供导入区块时复用 // Verify the transaction, the result is cached for reuse on block import
+	if err := tx.VerifyCached(s.chain.SigCache()); err != nil {
+		return err
+	}
+
+	// 检查交易能否进入交易池 // Check whether the transaction may enter the pool
+	if err := s.validateTransaction(tx); err != nil {
 		return err
 	}
 
@@ -176,14 +338,86 @@ func (s *Server) processTransaction(tx *core.Transaction) error {
 	//	"hash", hash,
 	//	"mempoolLength", s.memPool.PendingCount())
 
+	if err := s.memPool.Add(tx); err != nil {
+		return err
+	}
+
 	// 广播交易 // Broadcast the transaction
 	go s.broadcastTx(tx)
 
-	s.memPool.Add(tx)
+	return nil
+}
+
+// processBundle 方法处理交易包，交易包作为一个整体校验、加入交易池和广播
+// processBundle method processes a bundle, which is validated, pooled and broadcast as one unit
+func (s *Server) processBundle(b *core.Bundle) error {
+	// 验证交易包结构和成员签名 // Verify the bundle structure and the member signatures
+	if err := b.VerifyCached(s.chain.SigCache()); err != nil {
+		return err
+	}
+	for _, tx := range b.Transactions {
+		if err := s.validateTransaction(tx); err != nil {
+			return fmt.Errorf("bundle (%s): %w", b.ID(), err)
+		}
+	}
+
+	if err := s.memPool.AddBundle(b); err != nil {
+		return err
+	}
+
+	// 广播交易包 // Broadcast the bundle
+	go s.broadcastBundle(b)
+
+	return nil
+}
+
+// validateTransaction 方法在交易进入交易池前检查其格式以及链、高度、序号和余额
+// validateTransaction method checks the format as well as the chain, height, nonce and balance of a transaction before it enters the pool
+func (s *Server) validateTransaction(tx *core.Transaction) error {
+	hash := tx.Hash(core.TxHasher{})
+
+	// 校验交易信封和对应类型的数据格式 // Validate the envelope and the type specific data format
+	if err := tx.ValidatePayload(); err != nil {
+		return err
+	}
+
+	// 拒绝属于其他链的交易 // Reject transactions belonging to another chain
+	if chainID := s.chain.ChainID(); tx.ChainID != chainID {
+		return fmt.Errorf("transaction (%s) has chain id (%d) => expected (%d)", hash, tx.ChainID, chainID)
+	}
+
+	// 拒绝无法再被打包到下一个区块之后的过期交易 // Reject expired transactions that can no longer be included from the next block on
+	if next := s.chain.Height() + 1; tx.Expired(next) {
+		return fmt.Errorf("transaction (%s) expired at height %d, next height is %d", hash, tx.ValidUntil, next)
+	}
 
+	// 拒绝序号已被使用的交易，防止重放 // Reject transactions whose nonce was already used to prevent replays
+	sender := tx.Sender()
+	if nonce := s.chain.GetNonce(sender); tx.Nonce < nonce {
+		return fmt.Errorf("transaction (%s) nonce %d too low, account nonce is %d", hash, tx.Nonce, nonce)
+	}
+
+	// 拒绝无法支付转账金额和手续费的交易 // Reject transactions that cannot pay for their value and fee
+	cost, err := tx.Cost()
+	if err != nil {
+		return err
+	}
+	if balance := s.chain.GetBalance(sender); balance < cost {
+		return fmt.Errorf("transaction (%s) costs %d, sender balance is %d", hash, cost, balance)
+	}
 	return nil
 }
 
+// isRejected 方法检查节点是否被拒绝且拒绝尚未到期
+// isRejected method checks if the peer is rejected and the rejection has not expired yet
+func (s *Server) isRejected(from NetAddr) bool {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	until, ok := s.rejectedPeers[from]
+	return ok && time.Now().Before(until)
+}
+
 // broadcastBlock 方法广播区块
 // broadcastBlock method broadcasts a block
 func (s *Server) broadcastBlock(b *core.Block) error {
@@ -208,6 +442,19 @@ func (s *Server) broadcastTx(tx *core.Transaction) error {
 	return s.broadcast(msg.Bytes())
 }
 
+// broadcastBundle 方法广播交易包
+// broadcastBundle method broadcasts a bundle
+func (s *Server) broadcastBundle(b *core.Bundle) error {
+	buf := &bytes.Buffer{}
+	if err := b.Encode(core.NewProtobufBundleEncoder(buf)); err != nil {
+		return err
+	}
+
+	// 创建消息并广播 // Create a message and broadcast it
+	msg := NewMessage(MessageTypeBundle, buf.Bytes())
+	return s.broadcast(msg.Bytes())
+}
+
 // createNewBlock 方法创建一个新块
 // createNewBlock method creates a new block
 func (s *Server) createNewBlock() error {
@@ -218,42 +465,79 @@ func (s *Server) createNewBlock() error {
 		return err
 	}
 
+	// 已签名的提议区块建立在当前链头上时重新提议它，签名者会拒绝在同一高度签署另一个区块
+	// Propose the signed proposal again when it builds on the current head, the signer refuses to sign another block at the same height
+	block := s.pendingProposal(currentHeader)
+	if block == nil {
+		if block, err = s.newProposal(currentHeader); err != nil {
+			return err
+		}
+	}
+
+	// 将区块添加到区块链，失败时保留提议区块下次重试
+	// Add the block to the blockchain, the proposal is kept for the next attempt when it fails
+	if err := s.chain.AddBlock(block); err != nil {
+		return err
+	}
+	s.proposal = nil
+
+	// 移除已包含在区块中的交易以及序号已失效的交易
+	// Remove the transactions included in the block and the ones whose nonce became stale
+	s.memPool.Prune(s.chain.GetNonce)
+	// 移除无法再被打包的过期交易
+	// Remove the expired transactions that can no longer be included
+	s.memPool.Expire(s.chain.Height() + 1)
+
+	// 异步广播新创建的区块
+	// Asynchronously broadcast the newly created block
+	go s.broadcastBlock(block)
+
+	return nil
+}
+
+// pendingProposal 方法返回建立在给定链头上的已签名提议区块，链已经越过它的高度时丢弃它
+// pendingProposal method returns the signed proposal building on the given head, dropping it once the chain moved past its height
+func (s *Server) pendingProposal(head *core.Header) *core.Block {
+	if s.proposal == nil {
+		return nil
+	}
+	if s.proposal.Height <= head.Height {
+		s.proposal = nil
+		return nil
+	}
+	if s.proposal.Height != head.Height+1 || s.proposal.PrevBlockHash != (core.BlockHasher{}).Hash(head) {
+		return nil
+	}
+	return s.proposal
+}
+
+// newProposal 方法在给定链头上创建一个新区块并签名
+// newProposal method creates a new block on the given head and signs it
+func (s *Server) newProposal(currentHeader *core.Header) (*core.Block, error) {
 	// 目前我们将使用所有在交易池中的交易
 	// For now, we are going to use all transactions that are in the mempool
 	// 后续我们了解交易的内部结构后
 	// Later on, when we know the internal structure of our transaction
 	// 我们将实现某种复杂度函数来确定一个区块中可以包含多少交易
 	// We will implement some kind of complexity function to determine how many transactions can be included in a block.
-	txx := s.memPool.Pending()
+	// 跳过在当前账户状态上无法执行的交易，例如会透支账户的转账
+	// Skip transactions that cannot be executed on the current account state, e.g. transfers that would overdraw an account
+	txx := s.chain.ExecutableTransactions(s.Signer.PublicKey().Address(), s.memPool.Pending())
 
 	// 创建新的区块
 	// Create a new block
 	block, err := core.NewBlockFromPrevHeader(currentHeader, txx)
 	if err != nil {
-		return err
-	}
-
-	// 使用私钥签名区块
-	// Sign the block with the private key
-	if err := block.Sign(*s.PrivateKey); err != nil {
-		return err
+		return nil, err
 	}
 
-	// 将区块添加到区块链
-	// Add the block to the blockchain
-	if err := s.chain.AddBlock(block); err != nil {
-		return err
+	// 使用签名者签名区块，签名者可以拒绝签名
+	// Sign the block with the signer, the signer may refuse to sign
+	if err := signer.SignBlock(s.Signer, block); err != nil {
+		return nil, err
 	}
-
-	// 清除已包含在区块中的待处理交易
-	// Clear pending transactions that have been included in the block
-	s.memPool.ClearPending()
-
-	// 异步广播新创建的区块
-	// Asynchronously broadcast the newly created block
-	go s.broadcastBlock(block)
-
-	return nil
+	s.proposal = block
+	return block, nil
 }
 
 // initTransport 方法初始化所有的传输选项
@@ -266,20 +550,11 @@ func (s *Server) initTransport() {
 				s.rpcCh <- rpc
 			}
 		}(tr)
+		go func(tr Transport) {
+			for ev := range tr.PeerEvents() {
+				// 处理节点事件 // Handle peer events
+				s.peerCh <- peerEvent{tr: tr, PeerEvent: ev}
+			}
+		}(tr)
 	}
 }
-
-// genesisBlock 创建并返回创世区块
-// genesisBlock creates and returns the genesis block
-func genesisBlock() *core.Block {
-	header := &core.Header{
-		Version:   1,            // 区块版本号 // Block version number
-		Height:    0,            // 区块高度 // Block height
-		Timestamp: 000000,       // 区块生成的时间戳 // Timestamp when the block was created
-		DataHash:  types.Hash{}, // 区块数据哈希值 // Hash of the block data
-	}
-
-	// 创建并返回创世区块 // Create and return the genesis block
-	b, _ := core.NewBlock(header, nil)
-	return b
-}
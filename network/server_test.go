@@ -0,0 +1,69 @@
+package network
+
+import (
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/signer"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestValidatorRestart 测试验证者签名后重启仍然继续出块，并重新提议签名后没有加入链的区块
+// TestValidatorRestart tests that a validator keeps producing blocks after signing and restarting, proposing again the signed block that never made it into the chain
+func TestValidatorRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sign_state.json")
+	privKey := crypto.GeneratePrivateKey()
+	a := newTestValidator(t, "A", privKey, path)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, a.createNewBlock())
+	}
+
+	// 签名下一个区块后、加入链之前崩溃 // Crash after signing the next block but before adding it to the chain
+	head, err := a.chain.GetHeader(a.chain.Height())
+	assert.Nil(t, err)
+	signed, err := a.newProposal(head)
+	assert.Nil(t, err)
+	signedHash := signed.Hash(core.BlockHasher{})
+
+	// 重启后链从创世区块开始，已签名的高度不会再签署其他区块 // After the restart the chain starts from genesis, no other block is signed at the signed heights
+	b := newTestValidator(t, "B", privKey, path)
+	assert.NotNil(t, b.createNewBlock())
+	assert.Equal(t, uint32(0), b.chain.Height())
+
+	// 从其他节点同步已有的区块后重新提议签名过的区块，然后继续出块 // After syncing the existing blocks from other peers the signed block is proposed again and production goes on
+	for height := uint32(1); height <= a.chain.Height(); height++ {
+		blk, err := a.chain.GetBlock(height)
+		assert.Nil(t, err)
+		assert.Nil(t, b.chain.AddBlock(blk))
+	}
+	assert.Nil(t, b.createNewBlock())
+	header, err := b.chain.GetHeader(4)
+	assert.Nil(t, err)
+	assert.Equal(t, signedHash, core.BlockHasher{}.Hash(header))
+	assert.Nil(t, b.createNewBlock())
+	assert.Equal(t, uint32(5), b.chain.Height())
+
+	// 没有重启的节点同样重新提议加入链失败的区块 // A node that did not restart proposes the block that failed to make it into the chain again as well
+	assert.Nil(t, a.createNewBlock())
+	header, err = a.chain.GetHeader(4)
+	assert.Nil(t, err)
+	assert.Equal(t, signedHash, core.BlockHasher{}.Hash(header))
+}
+
+// newTestValidator 创建一个使用带签名记录文件的双签保护的验证者，出块间隔足够长，由测试直接调用出块
+// newTestValidator creates a validator behind a double sign guard with a sign record file, the block time is long enough that the test proposes directly
+func newTestValidator(t *testing.T, id string, privKey crypto.PrivateKey, signState string) *Server {
+	guard, err := signer.OpenGuard(signer.NewLocalSigner(privKey), signState)
+	assert.Nil(t, err)
+	s, err := NewServer(ServerOpts{
+		ID:        id,
+		Logger:    log.NewNopLogger(),
+		BlockTime: time.Hour,
+		Signer:    guard,
+	})
+	assert.Nil(t, err)
+	return s
+}
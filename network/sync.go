@@ -0,0 +1,313 @@
+package network
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/lonySp/go-blockchain/core"
+	"sync"
+	"time"
+)
+
+// syncTimeout 定义等待同步响应的时间，超时后换一个节点重试
+// syncTimeout defines how long a sync response is waited for, another peer is tried once it passed
+var syncTimeout = 5 * time.Second
+
+// syncInterval 定义检查同步超时和是否落后的时间间隔
+// syncInterval defines the interval of checking for sync timeouts and whether we fell behind
+var syncInterval = time.Second
+
+// syncRetryAfter 定义同步失败的节点在多长时间内不会再被选中
+// syncRetryAfter defines how long a peer that failed a sync request is not picked again
+var syncRetryAfter = 30 * time.Second
+
+// syncState 结构体记录正在进行的同步请求，同一时间只向一个节点请求一批区块
+// syncState struct records the sync request in flight, one batch is requested from one peer at a time
+type syncState struct {
+	lock     sync.Mutex           // 保护同步状态的互斥锁 // Mutex guarding the sync state
+	peer     NetAddr              // 正在同步的节点，空闲时为空 // Peer being synced from, empty when idle
+	from     uint32               // 请求的起始高度 // First requested height
+	to       uint32               // 请求的结束高度 // Last requested height
+	headers  []*core.Header       // 已校验、等待区块的区块头 // Validated headers waiting for their blocks
+	deadline time.Time            // 等待响应的截止时间 // Deadline for the response
+	failed   map[string]time.Time // 同步失败的节点主机及失败时间 // Hosts of the peers that failed a sync request and when
+}
+
+// newSyncState 创建一个空闲的同步状态
+// newSyncState creates an idle sync state
+func newSyncState() *syncState {
+	return &syncState{failed: make(map[string]time.Time)}
+}
+
+// IsSyncing 方法检查节点是否正在从其他节点下载区块
+// IsSyncing method checks if the node is downloading blocks from another peer
+func (s *Server) IsSyncing() bool {
+	s.sync.lock.Lock()
+	defer s.sync.lock.Unlock()
+
+	return s.sync.peer != ""
+}
+
+// startSync 方法在空闲时选择高度最高的就绪节点，并请求本节点之后的下一批区块头
+// startSync method picks the ready peer with the highest height when idle and requests the next batch of headers after ours
+func (s *Server) startSync() error {
+	s.sync.lock.Lock()
+	defer s.sync.lock.Unlock()
+
+	if s.sync.peer != "" {
+		return nil
+	}
+
+	height := s.chain.Height()
+	var (
+		target     NetAddr
+		targetPeer *peer
+		best       = height
+	)
+	for addr, p := range s.readyPeers() {
+		if failedAt, ok := s.sync.failed[addr.Host()]; ok && time.Since(failedAt) < syncRetryAfter {
+			continue
+		}
+		s.peerLock.RLock()
+		peerHeight := p.height
+		s.peerLock.RUnlock()
+		if peerHeight > best {
+			target, targetPeer, best = addr, p, peerHeight
+		}
+	}
+	if targetPeer == nil {
+		return nil
+	}
+
+	from := height + 1
+	to := min(best, from+MaxSyncBatch-1)
+	s.Logger.Log("msg", "syncing headers", "peer", target, "from", from, "to", to)
+	s.sync.peer, s.sync.from, s.sync.to, s.sync.headers = target, from, to, nil
+	s.sync.deadline = time.Now().Add(syncTimeout)
+
+	if err := s.sendGob(target, targetPeer, MessageTypeGetHeaders, &GetHeadersMessage{From: from, To: to}); err != nil {
+		s.failSyncLocked(err)
+		return err
+	}
+	return nil
+}
+
+// checkSync 方法放弃超时的同步请求，删除到期的失败记录，并在空闲时开始新的同步
+// checkSync method abandons a sync request that timed out, drops the expired failures and starts a new sync when idle
+func (s *Server) checkSync() error {
+	s.sync.lock.Lock()
+	if s.sync.peer != "" && time.Now().After(s.sync.deadline) {
+		s.failSyncLocked(fmt.Errorf("sync request to peer %s timed out", s.sync.peer))
+	}
+	for host, failedAt := range s.sync.failed {
+		if time.Since(failedAt) >= syncRetryAfter {
+			delete(s.sync.failed, host)
+		}
+	}
+	s.sync.lock.Unlock()
+
+	return s.startSync()
+}
+
+// failSync 方法记录当前同步节点失败并回到空闲状态，之后会换一个节点重试
+// failSync method records that the current sync peer failed and goes back to idle, another peer is tried next
+func (s *Server) failSync(err error) error {
+	s.sync.lock.Lock()
+	s.failSyncLocked(err)
+	s.sync.lock.Unlock()
+
+	s.startSync()
+	return err
+}
+
+// failSyncLocked 方法在持有同步锁时标记当前同步节点的主机失败，节点换一个端口重新连接也不会清除失败记录
+// failSyncLocked method marks the host of the current sync peer failed while the sync lock is held, the peer reconnecting from another port does not clear the failure
+func (s *Server) failSyncLocked(err error) {
+	s.Logger.Log("msg", "sync failed", "peer", s.sync.peer, "err", err)
+	s.sync.failed[s.sync.peer.Host()] = time.Now()
+	s.sync.peer, s.sync.headers = "", nil
+}
+
+// processGetHeaders 方法回复请求范围内本节点拥有的区块头
+// processGetHeaders method replies with the headers of the requested range that we have
+func (s *Server) processGetHeaders(from NetAddr, m *GetHeadersMessage) error {
+	headers := []*core.Header{}
+	for i := uint32(0); i < s.syncRangeLen(m.From, m.To); i++ {
+		header, err := s.chain.GetHeader(m.From + i)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, header)
+	}
+	return s.reply(from, MessageTypeHeaders, &HeadersMessage{Headers: headers})
+}
+
+// processGetBlocks 方法回复请求范围内本节点拥有的区块
+// processGetBlocks method replies with the blocks of the requested range that we have
+func (s *Server) processGetBlocks(from NetAddr, m *GetBlocksMessage) error {
+	msg := &BlocksMessage{}
+	for i := uint32(0); i < s.syncRangeLen(m.From, m.To); i++ {
+		b, err := s.chain.GetBlock(m.From + i)
+		if err != nil {
+			return err
+		}
+		msg.Blocks = append(msg.Blocks, b)
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	return s.replyBytes(from, NewMessage(MessageTypeBlocks, data).Bytes())
+}
+
+// syncRangeLen 方法返回请求范围中可以回复的高度数量，创世区块不会被回复，数量不超过 MaxSyncBatch
+// syncRangeLen method returns the number of heights of the requested range that can be served, the genesis block is never served and the count is capped at MaxSyncBatch
+func (s *Server) syncRangeLen(from, to uint32) uint32 {
+	end := min(to, s.chain.Height())
+	if from == 0 || from > end {
+		return 0
+	}
+	return min(end-from+1, MaxSyncBatch)
+}
+
+// processHeaders 方法校验同步节点返回的区块头是否连续并接在本节点的链上，然后请求对应的区块
+// processHeaders method checks that the headers returned by the sync peer are consecutive and extend our chain, then requests their blocks
+func (s *Server) processHeaders(from NetAddr, m *HeadersMessage) error {
+	s.sync.lock.Lock()
+	if from != s.sync.peer || s.sync.headers != nil {
+		s.sync.lock.Unlock()
+		return nil
+	}
+	err := s.validateHeaders(m.Headers)
+	if err != nil {
+		s.sync.lock.Unlock()
+		return s.failSync(err)
+	}
+
+	s.sync.headers = m.Headers
+	s.sync.to = s.sync.from + uint32(len(m.Headers)) - 1
+	s.sync.deadline = time.Now().Add(syncTimeout)
+	req := &GetBlocksMessage{From: s.sync.from, To: s.sync.to}
+	s.sync.lock.Unlock()
+
+	s.Logger.Log("msg", "syncing blocks", "peer", from, "from", req.From, "to", req.To)
+	if err := s.reply(from, MessageTypeGetBlocks, req); err != nil {
+		return s.failSync(err)
+	}
+	return nil
+}
+
+// validateHeaders 方法在持有同步锁时校验区块头从请求的起始高度开始连续，并且第一个区块头指向本节点的区块
+// validateHeaders method checks while the sync lock is held that the headers are consecutive from the requested height and the first one points to our block
+func (s *Server) validateHeaders(headers []*core.Header) error {
+	if len(headers) == 0 || uint32(len(headers)) > s.sync.to-s.sync.from+1 {
+		return fmt.Errorf("peer %s returned %d headers for heights %d to %d", s.sync.peer, len(headers), s.sync.from, s.sync.to)
+	}
+	prev, err := s.chain.GetHeader(s.sync.from - 1)
+	if err != nil {
+		return err
+	}
+	for i, h := range headers {
+		if h.Height != s.sync.from+uint32(i) {
+			return fmt.Errorf("peer %s returned header at height (%d) => expected (%d)", s.sync.peer, h.Height, s.sync.from+uint32(i))
+		}
+		if prevHash := (core.BlockHasher{}).Hash(prev); h.PrevBlockHash != prevHash {
+			return fmt.Errorf("peer %s returned header at height (%d) with previous hash (%s) => expected (%s)", s.sync.peer, h.Height, h.PrevBlockHash, prevHash)
+		}
+		prev = h
+	}
+	return nil
+}
+
+// processBlocks 方法检查同步节点返回的区块与区块头一致，并按顺序添加到区块链，然后请求下一批
+// processBlocks method checks that the blocks returned by the sync peer match the headers, adds them to the chain in order and then requests the next batch
+func (s *Server) processBlocks(from NetAddr, m *BlocksMessage) error {
+	s.sync.lock.Lock()
+	if from != s.sync.peer || s.sync.headers == nil {
+		s.sync.lock.Unlock()
+		// 同步请求之外的区块是为孤块请求的缺失区块 // Blocks outside a sync request are the missing blocks requested for orphans
+		return s.processMissingBlocks(from, m.Blocks)
+	}
+	headers := s.sync.headers
+	s.sync.lock.Unlock()
+
+	if len(m.Blocks) != len(headers) {
+		return s.failSync(fmt.Errorf("peer %s returned %d blocks => expected %d", from, len(m.Blocks), len(headers)))
+	}
+	for i, b := range m.Blocks {
+		// 按收到的区块头重新计算哈希，不使用区块缓存的哈希 // Recompute the hash from the received header instead of using the hash cached in the block
+		hash, blockHash := (core.BlockHasher{}).Hash(headers[i]), (core.BlockHasher{}).Hash(b.Header)
+		if blockHash != hash {
+			return s.failSync(fmt.Errorf("peer %s returned block (%s) at height (%d) => expected (%s)", from, blockHash, b.Height, hash))
+		}
+		// 跳过同步期间经由广播收到的区块 // Skip the blocks that arrived through broadcasts during the sync
+		if s.chain.HasBlock(b.Height) {
+			continue
+		}
+		if err := s.chain.AddBlock(b); err != nil {
+			return s.failSync(err)
+		}
+		s.connectOrphans(b)
+	}
+	// 整批区块证明了节点的高度 // The whole batch proves the height of the peer
+	last := m.Blocks[len(m.Blocks)-1]
+	s.updatePeerHead(from, last.Height, last.Hash(core.BlockHasher{}))
+	// 移除已上链或已失效的交易 // Remove the transactions that were included or became stale
+	s.memPool.Prune(s.chain.GetNonce)
+	s.memPool.Expire(s.chain.Height() + 1)
+
+	s.sync.lock.Lock()
+	s.sync.peer, s.sync.headers = "", nil
+	s.sync.lock.Unlock()
+
+	// 继续下一批 // Continue with the next batch
+	return s.startSync()
+}
+
+// processMissingBlocks 方法按顺序处理为孤块请求的区块，跳过已有的区块
+// processMissingBlocks method processes the blocks requested for orphans in order, skipping the ones we already have
+func (s *Server) processMissingBlocks(from NetAddr, blocks []*core.Block) error {
+	for _, b := range blocks {
+		if s.chain.HasBlock(b.Height) {
+			continue
+		}
+		if err := s.processBlock(from, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reply 方法向节点发送 gob 编码的消息
+// reply method sends a gob encoded message to the peer
+func (s *Server) reply(to NetAddr, t MessageType, v any) error {
+	s.peerLock.RLock()
+	p, ok := s.peers[to]
+	s.peerLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown peer %s", to)
+	}
+	return s.sendGob(to, p, t, v)
+}
+
+// replyBytes 方法向节点发送已编码的消息
+// replyBytes method sends an encoded message to the peer
+func (s *Server) replyBytes(to NetAddr, payload []byte) error {
+	s.peerLock.RLock()
+	p, ok := s.peers[to]
+	s.peerLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown peer %s", to)
+	}
+	return s.send(to, p, payload)
+}
+
+// sendGob 方法将 gob 编码的消息发送给节点
+// sendGob method sends a gob encoded message to the peer
+func (s *Server) sendGob(to NetAddr, p *peer, t MessageType, v any) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	return s.send(to, p, NewMessage(t, buf.Bytes()).Bytes())
+}
@@ -0,0 +1,205 @@
+package network
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// 缩短同步的超时和检查间隔，在任何测试服务器启动前设置 // Shorten the sync timeout and check interval, set before any test server starts
+func init() {
+	syncTimeout = 500 * time.Millisecond
+	syncInterval = 50 * time.Millisecond
+}
+
+// TestBlocksMessage 测试区块响应的编码往返
+// TestBlocksMessage tests the encoding round trip of the block response
+func TestBlocksMessage(t *testing.T) {
+	s := newTestChainServer(t, "A", nil, 3)
+	msg := &BlocksMessage{}
+	for height := uint32(1); height <= 3; height++ {
+		b, err := s.chain.GetBlock(height)
+		assert.Nil(t, err)
+		msg.Blocks = append(msg.Blocks, b)
+	}
+
+	data, err := msg.Bytes()
+	assert.Nil(t, err)
+	decoded, err := decodeBlocksMessage(data)
+	assert.Nil(t, err)
+	assert.Equal(t, len(msg.Blocks), len(decoded.Blocks))
+	for i, b := range decoded.Blocks {
+		assert.Equal(t, msg.Blocks[i].Hash(core.BlockHasher{}), b.Hash(core.BlockHasher{}))
+	}
+}
+
+// TestSyncRangeLen 测试回复的范围不包含创世区块、不超过本节点的高度和批量上限
+// TestSyncRangeLen tests that the served range excludes the genesis block and is capped at our height and the batch limit
+func TestSyncRangeLen(t *testing.T) {
+	s := newTestChainServer(t, "A", nil, 200)
+
+	assert.Equal(t, uint32(0), s.syncRangeLen(0, 10))
+	assert.Equal(t, uint32(10), s.syncRangeLen(1, 10))
+	assert.Equal(t, uint32(11), s.syncRangeLen(190, 1000))
+	assert.Equal(t, MaxSyncBatch, s.syncRangeLen(1, 1000))
+	assert.Equal(t, uint32(0), s.syncRangeLen(201, 300))
+	assert.Equal(t, uint32(0), s.syncRangeLen(10, 5))
+}
+
+// TestSyncLateJoiner 测试后加入的节点分多批下载缺少的区块
+// TestSyncLateJoiner tests that a node joining late downloads the missing blocks in several batches
+func TestSyncLateJoiner(t *testing.T) {
+	tra, trb := connectedLocalTransports("A", "B")
+	a := newTestChainServer(t, "A", tra, int(2*MaxSyncBatch+10))
+	b := newTestChainServer(t, "B", trb, 0)
+	go a.Start()
+	go b.Start()
+
+	assert.Eventually(t, func() bool {
+		return b.chain.Height() == a.chain.Height() && !b.IsSyncing()
+	}, 10*time.Second, 10*time.Millisecond)
+
+	head, err := a.chain.GetHeader(a.chain.Height())
+	assert.Nil(t, err)
+	synced, err := b.chain.GetHeader(b.chain.Height())
+	assert.Nil(t, err)
+	assert.Equal(t, core.BlockHasher{}.Hash(head), core.BlockHasher{}.Hash(synced))
+}
+
+// TestSyncRetriesOtherPeer 测试同步节点不响应时超时并换另一个节点重试
+// TestSyncRetriesOtherPeer tests that the sync times out when the peer does not answer and is retried with another peer
+func TestSyncRetriesOtherPeer(t *testing.T) {
+	trb, trf := connectedLocalTransports("B", "F")
+	b := newTestChainServer(t, "B", trb, 0)
+	go b.Start()
+
+	// 不响应的节点声称拥有更高的链 // The silent peer claims a much higher chain
+	buf := &bytes.Buffer{}
+	assert.Nil(t, gob.NewEncoder(buf).Encode(&HandshakeMessage{
+		Version:     ProtocolVersion,
+		ChainID:     b.chain.ChainID(),
+		GenesisHash: b.chain.GenesisHash(),
+		Height:      1000,
+	}))
+	assert.Nil(t, trf.SendMessage(trb.Addr(), NewMessage(MessageTypeHandshake, buf.Bytes()).Bytes()))
+	assert.Eventually(t, b.IsSyncing, 5*time.Second, 10*time.Millisecond)
+
+	tra := NewLocalTransport("A")
+	a := newTestChainServer(t, "A", tra, 20)
+	go a.Start()
+	tra.Connect(trb)
+	trb.Connect(tra)
+
+	assert.Eventually(t, func() bool {
+		return b.chain.Height() == 20
+	}, 10*time.Second, 10*time.Millisecond)
+
+	b.sync.lock.Lock()
+	_, failed := b.sync.failed[trf.Addr().Host()]
+	b.sync.lock.Unlock()
+	assert.True(t, failed)
+}
+
+// TestSyncBlocksHeaderMismatch 测试同步节点返回的区块按区块头重新计算哈希，缓存了已校验区块头哈希的其他区块被拒绝
+// TestSyncBlocksHeaderMismatch tests that the hash of the blocks returned by the sync peer is recomputed from the header, another block carrying the cached hash of the validated header is rejected
+func TestSyncBlocksHeaderMismatch(t *testing.T) {
+	s := newTestChainServer(t, "B", nil, 0)
+	genesis, err := s.chain.GetHeader(0)
+	assert.Nil(t, err)
+
+	privKey := crypto.GeneratePrivateKey()
+	expected, err := core.NewBlockFromPrevHeader(genesis, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, expected.Sign(privKey))
+	other, err := core.NewBlockFromPrevHeader(genesis, nil)
+	assert.Nil(t, err)
+	other.Timestamp = expected.Timestamp + 1
+	assert.Nil(t, other.Sign(privKey))
+
+	// 区块缓存了期望的哈希，内容却是另一个有效区块 // The block caches the expected hash but carries another valid block
+	header := expected.Header
+	expected.Hash(core.BlockHasher{})
+	expected.Header, expected.Validator, expected.Signature = other.Header, other.Validator, other.Signature
+
+	s.sync.lock.Lock()
+	s.sync.peer, s.sync.headers = "F", []*core.Header{header}
+	s.sync.lock.Unlock()
+	assert.NotNil(t, s.processBlocks("F", &BlocksMessage{Blocks: []*core.Block{expected}}))
+	assert.Equal(t, uint32(0), s.chain.Height())
+}
+
+// TestUnprovenHeightDoesNotStall 测试握手中声称的高度和无效的区块不会让节点认为自己落后，通过验证的区块才证明节点的高度
+// TestUnprovenHeightDoesNotStall tests that a height claimed in the handshake or an invalid block does not make the node believe it is behind, only a validated block proves the height of the peer
+func TestUnprovenHeightDoesNotStall(t *testing.T) {
+	blocks := testChainBlocks(t, 2)
+	trb, trf := connectedLocalTransports("B", "F")
+	b := newTestChainServer(t, "B", trb, 0)
+
+	assert.Nil(t, b.ProcessMessage(&DecodedMessage{From: trf.Addr(), Data: &HandshakeMessage{
+		Version:     ProtocolVersion,
+		ChainID:     b.chain.ChainID(),
+		GenesisHash: b.chain.GenesisHash(),
+		Height:      1000,
+	}}))
+	assert.True(t, b.IsSyncing())
+	assert.False(t, b.behindProvenPeer())
+
+	forged := *blocks[2]
+	header := *forged.Header
+	header.Height = 500
+	forged.Header = &header
+	assert.NotNil(t, b.processBlock(trf.Addr(), &forged))
+	assert.False(t, b.behindProvenPeer())
+
+	b.processBlock(trf.Addr(), blocks[2])
+	assert.True(t, b.behindProvenPeer())
+}
+
+// TestSyncFailedByHost 测试同步失败按主机记录，换端口的节点仍然在等待重试，到期的记录被删除
+// TestSyncFailedByHost tests that sync failures are recorded by host, a peer on another port still waits for the retry, and expired records are dropped
+func TestSyncFailedByHost(t *testing.T) {
+	s := newTestChainServer(t, "A", nil, 0)
+	s.sync.lock.Lock()
+	s.sync.peer = "127.0.0.1:4000"
+	s.sync.lock.Unlock()
+	s.failSync(fmt.Errorf("sync failed"))
+
+	s.sync.lock.Lock()
+	_, failed := s.sync.failed[NetAddr("127.0.0.1:5000").Host()]
+	assert.True(t, failed)
+	s.sync.failed["127.0.0.1"] = time.Now().Add(-syncRetryAfter)
+	s.sync.lock.Unlock()
+
+	assert.Nil(t, s.checkSync())
+	s.sync.lock.Lock()
+	assert.Empty(t, s.sync.failed)
+	s.sync.lock.Unlock()
+}
+
+// newTestChainServer 创建一个不出块的测试服务器，并在它的链上添加给定数量的区块
+// newTestChainServer creates a test server that does not propose and adds the given number of blocks to its chain
+func newTestChainServer(t *testing.T, id string, tr Transport, blocks int) *Server {
+	opts := ServerOpts{ID: id, Logger: log.NewNopLogger()}
+	if tr != nil {
+		opts.Transport = []Transport{tr}
+	}
+	s, err := NewServer(opts)
+	assert.Nil(t, err)
+
+	privKey := crypto.GeneratePrivateKey()
+	for i := 0; i < blocks; i++ {
+		header, err := s.chain.GetHeader(s.chain.Height())
+		assert.Nil(t, err)
+		b, err := core.NewBlockFromPrevHeader(header, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, b.Sign(privKey))
+		assert.Nil(t, s.chain.AddBlock(b))
+	}
+	return s
+}
@@ -0,0 +1,485 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxFrameSize 是 TCP 传输接受的最大帧长度
+	// MaxFrameSize is the largest frame accepted by the TCP transport
+	MaxFrameSize = 16 << 20
+
+	tcpHandshakeTimeout = 5 * time.Second        // 交换监听地址的超时时间 // Timeout for exchanging listen addresses
+	tcpWriteTimeout     = 10 * time.Second       // 写入一帧的超时时间 // Timeout for writing one frame
+	tcpDialTimeout      = 5 * time.Second        // 拨号超时时间 // Dial timeout
+	tcpMinBackoff       = 250 * time.Millisecond // 重连的初始等待时间 // Initial wait before reconnecting
+	tcpMaxBackoff       = 30 * time.Second       // 重连的最长等待时间 // Longest wait before reconnecting
+	tcpSendQueueSize    = 1024                   // 每个节点的发送队列长度 // Send queue length per peer
+)
+
+// TCPTransport 结构体通过 TCP 连接传输消息，每一帧是 4 字节大端序长度加数据
+// TCPTransport struct transports messages over TCP connections, every frame is a 4 byte big-endian length followed by the data
+//
+// 连接建立后双方先交换一帧自己的监听地址。出站连接的节点以拨号地址标识；入站连接的节点声称的监听地址无法验证，
+// 只有主机与连接的真实来源一致且没有其他连接使用该地址时才用它标识，否则以连接的真实来源地址标识
+// Once connected both sides first exchange a frame with their listen address. Peers of outbound connections are identified by the dialed address; the listen address claimed by
+// the peer of an inbound connection cannot be verified, it only identifies the peer when its host matches the real source of the connection and no other connection uses it, the real source address is used otherwise
+type TCPTransport struct {
+	addr       NetAddr        // 监听地址 // Listen address
+	listener   net.Listener   // TCP 监听器 // TCP listener
+	consumerCh chan RPC       // 用于接收 RPC 消息的通道 // Channel for receiving RPC messages
+	eventCh    chan PeerEvent // 节点连接和断开事件的通道 // Channel for peer connect and disconnect events
+
+	lock    sync.RWMutex         // 保护节点和拨号集合的读写锁 // Read-write lock guarding the peers and the dialed set
+	peers   map[NetAddr]*tcpPeer // 已连接的节点 // Connected peers
+	dialing map[NetAddr]struct{} // 正在维持出站连接的地址 // Addresses with an outbound connection being maintained
+
+	quitCh    chan struct{}  // 关闭传输的通道 // Channel for closing the transport
+	closeOnce sync.Once      // 保证只关闭一次 // Makes sure the transport is closed once
+	wg        sync.WaitGroup // 等待后台 goroutine 退出 // Waits for the background goroutines to exit
+}
+
+// tcpPeer 结构体表示一个 TCP 连接的节点，读和写各由一个 goroutine 负责
+// tcpPeer struct represents a peer over a TCP connection, one goroutine reads and one goroutine writes
+type tcpPeer struct {
+	addr     NetAddr       // 节点地址 // Peer address
+	conn     net.Conn      // TCP 连接 // TCP connection
+	outbound bool          // 是否由本节点拨出 // Whether this node dialed the connection
+	sendCh   chan []byte   // 待发送的帧 // Frames waiting to be sent
+	closedCh chan struct{} // 连接关闭时关闭的通道 // Channel closed when the connection is closed
+	once     sync.Once     // 保证只关闭一次 // Makes sure the peer is closed once
+}
+
+// close 方法关闭节点连接
+// close method closes the peer connection
+func (p *tcpPeer) close() {
+	p.once.Do(func() {
+		close(p.closedCh)
+		p.conn.Close()
+	})
+}
+
+// NewTCPTransport 创建一个在给定地址监听的 TCP 传输，地址必须是其他节点可以拨号的地址
+// NewTCPTransport creates a TCP transport listening on the given address, the address must be one other nodes can dial
+func NewTCPTransport(addr NetAddr) *TCPTransport {
+	return &TCPTransport{
+		addr:       addr,
+		consumerCh: make(chan RPC, 1024),
+		eventCh:    make(chan PeerEvent, peerEventBuffer),
+		peers:      make(map[NetAddr]*tcpPeer),
+		dialing:    make(map[NetAddr]struct{}),
+		quitCh:     make(chan struct{}),
+	}
+}
+
+// Listen 方法开始监听并接受入站连接，端口为 0 时使用系统分配的端口
+// Listen method starts listening and accepting inbound connections, a port of 0 uses a port picked by the system
+func (t *TCPTransport) Listen() error {
+	l, err := net.Listen("tcp", string(t.addr))
+	if err != nil {
+		return err
+	}
+	t.lock.Lock()
+	t.listener = l
+	t.addr = NetAddr(l.Addr().String())
+	t.lock.Unlock()
+
+	t.wg.Add(1)
+	go t.acceptLoop(l)
+	return nil
+}
+
+// Consume 方法返回一个 RPC 消费通道，传输关闭后通道被关闭
+// Consume method returns a RPC consumption channel, the channel is closed once the transport is closed
+func (t *TCPTransport) Consume() <-chan RPC {
+	return t.consumerCh
+}
+
+// PeerEvents 方法返回节点事件通道，传输关闭后通道被关闭
+// PeerEvents method returns the peer event channel, the channel is closed once the transport is closed
+func (t *TCPTransport) PeerEvents() <-chan PeerEvent {
+	return t.eventCh
+}
+
+// Connect 方法拨号连接另一个传输节点的地址
+// Connect method dials the address of another transport node
+func (t *TCPTransport) Connect(tr Transport) error {
+	return t.Dial(tr.Addr())
+}
+
+// Dial 方法在后台维持到给定地址的出站连接，连接失败或断开后按指数退避重连
+// Dial method maintains an outbound connection to the given address in the background, reconnecting with exponential backoff when dialing fails or the connection drops
+func (t *TCPTransport) Dial(addr NetAddr) error {
+	if addr == t.Addr() {
+		return fmt.Errorf("%s: cannot dial own address", addr)
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.dialing[addr]; ok {
+		return nil
+	}
+	select {
+	case <-t.quitCh:
+		return fmt.Errorf("%s: transport is closed", t.addr)
+	default:
+	}
+	t.dialing[addr] = struct{}{}
+	t.wg.Add(1)
+	go t.dialLoop(addr)
+	return nil
+}
+
+// Disconnect 方法关闭与节点的连接并停止向该地址重连
+// Disconnect method closes the connection to the peer and stops redialing its address
+func (t *TCPTransport) Disconnect(addr NetAddr) error {
+	t.lock.Lock()
+	peer, ok := t.peers[addr]
+	_, dialing := t.dialing[addr]
+	delete(t.dialing, addr)
+	t.lock.Unlock()
+
+	if !ok && !dialing {
+		return fmt.Errorf("%s: not connected to peer %s", t.Addr(), addr)
+	}
+	if ok {
+		t.removePeer(peer)
+	}
+	return nil
+}
+
+// SendMessage 方法将消息放入目标节点的发送队列
+// SendMessage method puts the message into the send queue of the target peer
+func (t *TCPTransport) SendMessage(to NetAddr, payload []byte) error {
+	t.lock.RLock()
+	peer, ok := t.peers[to]
+	t.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s: could not message to unknown peer %s", t.Addr(), to)
+	}
+	if len(payload) > MaxFrameSize {
+		return fmt.Errorf("%s: message of %d bytes exceeds the frame limit", t.Addr(), len(payload))
+	}
+
+	select {
+	case <-peer.closedCh:
+		return fmt.Errorf("%s: connection to peer %s is closed", t.Addr(), to)
+	case peer.sendCh <- payload:
+		return nil
+	default:
+		return fmt.Errorf("%s: send queue to peer %s is full", t.Addr(), to)
+	}
+}
+
+// Broadcast 方法将消息发送给所有已连接的节点，单个节点失败不影响其他节点
+// Broadcast method sends the message to all connected peers, a failing peer does not affect the others
+func (t *TCPTransport) Broadcast(payload []byte) error {
+	var firstErr error
+	for _, addr := range t.Peers() {
+		if err := t.SendMessage(addr, payload); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Addr 方法返回传输节点的监听地址
+// Addr method returns the listen address of the transport node
+func (t *TCPTransport) Addr() NetAddr {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.addr
+}
+
+// Peers 方法按地址顺序返回已连接的节点
+// Peers method returns the connected peers in address order
+func (t *TCPTransport) Peers() []NetAddr {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	addrs := make([]NetAddr, 0, len(t.peers))
+	for addr := range t.peers {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// Close 方法关闭监听器和所有连接，等待后台 goroutine 退出后关闭消费通道
+// Close method closes the listener and all connections, the consumption channel is closed once the background goroutines exited
+func (t *TCPTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.lock.Lock()
+		close(t.quitCh)
+		if t.listener != nil {
+			t.listener.Close()
+		}
+		for _, peer := range t.peers {
+			peer.close()
+		}
+		t.lock.Unlock()
+
+		t.wg.Wait()
+		close(t.consumerCh)
+		close(t.eventCh)
+	})
+	return nil
+}
+
+// acceptLoop 方法接受入站连接，直到监听器关闭
+// acceptLoop method accepts inbound connections until the listener is closed
+func (t *TCPTransport) acceptLoop(l net.Listener) {
+	defer t.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			if _, err := t.addConn(conn, ""); err != nil {
+				conn.Close()
+			}
+		}()
+	}
+}
+
+// dialLoop 方法维持到给定地址的出站连接，已有连接（任一方向）时等待其断开
+// dialLoop method maintains the outbound connection to the given address, waiting for it to drop while a connection in either direction exists
+func (t *TCPTransport) dialLoop(addr NetAddr) {
+	defer t.wg.Done()
+
+	backoff := tcpMinBackoff
+	for {
+		t.lock.RLock()
+		peer := t.peers[addr]
+		_, dialing := t.dialing[addr]
+		t.lock.RUnlock()
+
+		// 调用 Disconnect 后停止重连 // Stop redialing once Disconnect was called
+		if !dialing {
+			return
+		}
+
+		if peer == nil {
+			conn, err := net.DialTimeout("tcp", string(addr), tcpDialTimeout)
+			if err == nil {
+				if peer, err = t.addConn(conn, addr); err != nil {
+					conn.Close()
+				}
+			}
+			if err != nil {
+				select {
+				case <-time.After(backoff):
+				case <-t.quitCh:
+					return
+				}
+				backoff = min(2*backoff, tcpMaxBackoff)
+				continue
+			}
+			backoff = tcpMinBackoff
+		}
+
+		select {
+		case <-peer.closedCh:
+		case <-t.quitCh:
+			return
+		}
+	}
+}
+
+// addConn 方法交换监听地址并登记节点，dialed 为空表示入站连接
+// addConn method exchanges listen addresses and registers the peer, an empty dialed address means an inbound connection
+//
+// 入站连接从不替换已有的连接，因此声称别人的地址无法挤掉别人的连接；两个节点同时互相拨号时双方各保留两条连接
+// Inbound connections never replace an existing connection, so claiming somebody else's address cannot push their connection out; when two nodes dial each other at the same time both keep two connections
+func (t *TCPTransport) addConn(conn net.Conn, dialed NetAddr) (*tcpPeer, error) {
+	claimed, err := t.handshake(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	outbound := dialed != ""
+	addr := dialed
+	if !outbound {
+		if claimed == t.Addr() {
+			return nil, fmt.Errorf("%s: connected to itself", t.addr)
+		}
+		addr = inboundAddr(conn.RemoteAddr(), claimed)
+	}
+	peer := &tcpPeer{
+		conn:     conn,
+		outbound: outbound,
+		sendCh:   make(chan []byte, tcpSendQueueSize),
+		closedCh: make(chan struct{}),
+	}
+
+	t.lock.Lock()
+	select {
+	case <-t.quitCh:
+		t.lock.Unlock()
+		return nil, fmt.Errorf("%s: transport is closed", t.addr)
+	default:
+	}
+	if addr == t.addr {
+		t.lock.Unlock()
+		return nil, fmt.Errorf("%s: connected to itself", t.addr)
+	}
+	if !outbound {
+		// 声称的地址已被使用或本节点正在拨号该地址时，以真实来源地址标识 // Identify the peer by its real source address when the claimed address is taken or being dialed by this node
+		_, taken := t.peers[addr]
+		_, dialing := t.dialing[addr]
+		if taken || dialing {
+			addr = NetAddr(conn.RemoteAddr().String())
+		}
+	}
+	if old, ok := t.peers[addr]; ok {
+		switch {
+		case old.outbound && outbound:
+			// 拨号地址上旧的出站连接多半已失效 // The old outbound connection of the dialed address is most likely dead
+			old.close()
+		default:
+			// 入站连接不会被替换 // Inbound connections are never replaced
+			t.lock.Unlock()
+			return nil, fmt.Errorf("%s: already connected to %s", t.addr, addr)
+		}
+	}
+	peer.addr = addr
+	t.peers[addr] = peer
+	t.wg.Add(2)
+	emitPeerEvent(t.eventCh, addr, true)
+	t.lock.Unlock()
+
+	go t.readLoop(peer)
+	go t.writeLoop(peer)
+	return peer, nil
+}
+
+// inboundAddr 函数返回标识入站连接节点的地址：主机与连接来源一致时使用声称的监听地址，未指定主机时补上来源主机，否则使用来源地址
+// inboundAddr function returns the address identifying the peer of an inbound connection: the claimed listen address when its host matches the source of the connection, the source host filled in when the host is unspecified, the source address otherwise
+func inboundAddr(remote net.Addr, claimed NetAddr) NetAddr {
+	tcpAddr, ok := remote.(*net.TCPAddr)
+	if !ok {
+		return NetAddr(remote.String())
+	}
+	host, port, err := net.SplitHostPort(string(claimed))
+	if err != nil {
+		return NetAddr(remote.String())
+	}
+	ip := net.ParseIP(host)
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		return NetAddr(net.JoinHostPort(tcpAddr.IP.String(), port))
+	}
+	if ip == nil || !ip.Equal(tcpAddr.IP) {
+		return NetAddr(remote.String())
+	}
+	return NetAddr(net.JoinHostPort(tcpAddr.IP.String(), port))
+}
+
+// handshake 方法发送本节点的监听地址并读取对方的监听地址
+// handshake method sends the listen address of this node and reads the listen address of the remote
+func (t *TCPTransport) handshake(conn net.Conn) (NetAddr, error) {
+	conn.SetDeadline(time.Now().Add(tcpHandshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if err := writeFrame(conn, []byte(t.Addr())); err != nil {
+		return "", err
+	}
+	data, err := readFrame(conn)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("peer %s sent an empty listen address", conn.RemoteAddr())
+	}
+	return NetAddr(data), nil
+}
+
+// readLoop 方法读取节点发来的帧并送入消费通道，连接出错时移除节点
+// readLoop method reads the frames sent by the peer and feeds them into the consumption channel, the peer is removed when the connection fails
+func (t *TCPTransport) readLoop(peer *tcpPeer) {
+	defer t.wg.Done()
+	defer t.removePeer(peer)
+
+	for {
+		data, err := readFrame(peer.conn)
+		if err != nil {
+			return
+		}
+		select {
+		case t.consumerCh <- RPC{From: peer.addr, Payload: bytes.NewReader(data)}:
+		case <-peer.closedCh:
+			return
+		case <-t.quitCh:
+			return
+		}
+	}
+}
+
+// writeLoop 方法依次写出发送队列中的帧，写入出错时关闭连接
+// writeLoop method writes out the frames of the send queue in order, the connection is closed when a write fails
+func (t *TCPTransport) writeLoop(peer *tcpPeer) {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case data := <-peer.sendCh:
+			peer.conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+			if err := writeFrame(peer.conn, data); err != nil {
+				peer.close()
+				return
+			}
+		case <-peer.closedCh:
+			return
+		}
+	}
+}
+
+// removePeer 方法关闭节点，并在节点未被新连接替换时将其移除
+// removePeer method closes the peer and removes it unless it was replaced by a newer connection
+func (t *TCPTransport) removePeer(peer *tcpPeer) {
+	peer.close()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.peers[peer.addr] == peer {
+		delete(t.peers, peer.addr)
+		emitPeerEvent(t.eventCh, peer.addr, false)
+	}
+}
+
+// writeFrame 函数写入一帧：4 字节大端序长度加数据
+// writeFrame function writes one frame: a 4 byte big-endian length followed by the data
+func writeFrame(w io.Writer, data []byte) error {
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	_, err := w.Write(buf)
+	return err
+}
+
+// readFrame 函数读取一帧，拒绝超过 MaxFrameSize 的长度
+// readFrame function reads one frame, rejecting lengths beyond MaxFrameSize
+func readFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the limit of %d bytes", n, MaxFrameSize)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"bytes"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/signer"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestFrame 测试帧的编码往返，并拒绝超过长度上限的帧
+// TestFrame tests the frame encoding round trip and rejects frames beyond the length limit
+func TestFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, writeFrame(buf, []byte("hello")))
+	assert.Nil(t, writeFrame(buf, nil))
+	assert.Equal(t, 4+5+4, buf.Len())
+
+	data, err := readFrame(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello"), data)
+	data, err = readFrame(buf)
+	assert.Nil(t, err)
+	assert.Empty(t, data)
+	_, err = readFrame(buf)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = readFrame(bytes.NewReader([]byte{0x01, 0x00, 0x00, 0x01}))
+	assert.NotNil(t, err)
+}
+
+// TestTCPTransportSendMessage 测试通过回环地址发送消息，并使用 RPC 的发送者地址回复
+// TestTCPTransportSendMessage tests sending messages over loopback and replying to the sender address of the RPC
+func TestTCPTransportSendMessage(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, tra.Connect(trb))
+	waitConnected(t, tra, trb)
+
+	msg := []byte("hello world")
+	assert.Nil(t, tra.SendMessage(trb.Addr(), msg))
+	rpc := <-trb.Consume()
+	b, err := io.ReadAll(rpc.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, b)
+	assert.Equal(t, tra.Addr(), rpc.From)
+
+	assert.Nil(t, trb.SendMessage(rpc.From, []byte("reply")))
+	rpc = <-tra.Consume()
+	b, err = io.ReadAll(rpc.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("reply"), b)
+	assert.Equal(t, trb.Addr(), rpc.From)
+
+	assert.NotNil(t, tra.SendMessage("127.0.0.1:1", msg))
+}
+
+// TestTCPTransportSimultaneousDial 测试两个节点互相拨号时双方保留各自拨出的连接，对方拨入的连接以真实来源地址标识
+// TestTCPTransportSimultaneousDial tests that both nodes keep the connection they dialed when they dial each other, the connection dialed by the other side is identified by its real source address
+func TestTCPTransportSimultaneousDial(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, tra.Connect(trb))
+	assert.Nil(t, trb.Connect(tra))
+	assert.Eventually(t, func() bool {
+		return len(tra.Peers()) == 2 && len(trb.Peers()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, tra.Peers(), trb.Addr())
+	assert.Contains(t, trb.Peers(), tra.Addr())
+
+	// 两条连接都保持可用，消息可以双向传递 // Both connections stay up and messages flow both ways
+	assert.Nil(t, tra.SendMessage(trb.Addr(), []byte("a")))
+	assert.Nil(t, trb.SendMessage(tra.Addr(), []byte("b")))
+	rpc := <-trb.Consume()
+	assert.NotEqual(t, tra.Addr(), rpc.From)
+	assert.Nil(t, trb.SendMessage(rpc.From, []byte("c")))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-tra.Consume():
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not delivered")
+		}
+	}
+	time.Sleep(2 * tcpMinBackoff)
+	assert.Equal(t, 2, len(tra.Peers()))
+	assert.Equal(t, 2, len(trb.Peers()))
+}
+
+// TestTCPTransportInboundClaim 测试入站连接声称他人的监听地址时不会挤掉已有的连接，也不会冒用该地址
+// TestTCPTransportInboundClaim tests that an inbound connection claiming somebody else's listen address neither pushes out the existing connection nor takes over the address
+func TestTCPTransportInboundClaim(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, tra.Dial(trb.Addr()))
+	waitConnected(t, tra, trb)
+
+	for _, claim := range []NetAddr{tra.Addr(), "10.1.2.3:3000"} {
+		conn, err := net.Dial("tcp", string(trb.Addr()))
+		assert.Nil(t, err)
+		defer conn.Close()
+		assert.Nil(t, writeFrame(conn, []byte(claim)))
+		_, err = readFrame(conn)
+		assert.Nil(t, err)
+		assert.Nil(t, writeFrame(conn, []byte("spoofed")))
+
+		rpc := <-trb.Consume()
+		assert.Equal(t, NetAddr(conn.LocalAddr().String()), rpc.From)
+	}
+
+	// 原来的连接仍然以自己的地址可用 // The original connection is still up under its own address
+	assert.Contains(t, trb.Peers(), tra.Addr())
+	assert.Equal(t, 3, len(trb.Peers()))
+	assert.Nil(t, trb.SendMessage(tra.Addr(), []byte("hello")))
+	rpc := <-tra.Consume()
+	assert.Equal(t, trb.Addr(), rpc.From)
+}
+
+// TestInboundAddr 测试入站连接节点的标识地址
+// TestInboundAddr tests the address identifying the peer of an inbound connection
+func TestInboundAddr(t *testing.T) {
+	remote := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 51234}
+	assert.Equal(t, NetAddr("192.0.2.1:3000"), inboundAddr(remote, "192.0.2.1:3000"))
+	assert.Equal(t, NetAddr("192.0.2.1:3000"), inboundAddr(remote, "0.0.0.0:3000"))
+	assert.Equal(t, NetAddr("192.0.2.1:3000"), inboundAddr(remote, ":3000"))
+	assert.Equal(t, NetAddr("192.0.2.1:51234"), inboundAddr(remote, "198.51.100.7:3000"))
+	assert.Equal(t, NetAddr("192.0.2.1:51234"), inboundAddr(remote, "example.com:3000"))
+	assert.Equal(t, NetAddr("192.0.2.1:51234"), inboundAddr(remote, "garbage"))
+}
+
+// TestTCPTransportReconnect 测试对方重启后出站连接会重新建立
+// TestTCPTransportReconnect tests that the outbound connection is established again after the remote restarts
+func TestTCPTransportReconnect(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, tra.Dial(trb.Addr()))
+	waitConnected(t, tra, trb)
+
+	addr := trb.Addr()
+	assert.Nil(t, trb.Close())
+	assert.Eventually(t, func() bool { return len(tra.Peers()) == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	trb = NewTCPTransport(addr)
+	assert.Nil(t, trb.Listen())
+	defer trb.Close()
+	waitConnected(t, tra, trb)
+}
+
+// TestTCPTransportDisconnect 测试断开连接时发出节点事件并停止重连
+// TestTCPTransportDisconnect tests that disconnecting emits peer events and stops redialing
+func TestTCPTransportDisconnect(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, tra.Dial(trb.Addr()))
+	waitConnected(t, tra, trb)
+	assert.Equal(t, PeerEvent{Addr: trb.Addr(), Connected: true}, <-tra.PeerEvents())
+
+	assert.Nil(t, tra.Disconnect(trb.Addr()))
+	assert.Equal(t, PeerEvent{Addr: trb.Addr(), Connected: false}, <-tra.PeerEvents())
+	assert.Eventually(t, func() bool { return len(trb.Peers()) == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	// 超过重连的初始等待时间后仍然没有连接 // Still not connected after the initial reconnect wait passed
+	time.Sleep(2 * tcpMinBackoff)
+	assert.Empty(t, tra.Peers())
+	assert.NotNil(t, tra.Disconnect(trb.Addr()))
+}
+
+// TestTCPTransportServer 测试服务器握手后通过 TCP 传输同步新区块
+// TestTCPTransportServer tests that servers sync new blocks over the TCP transport after the handshake
+func TestTCPTransportServer(t *testing.T) {
+	tra := newTestTCPTransport(t)
+	trb := newTestTCPTransport(t)
+	assert.Nil(t, trb.Dial(tra.Addr()))
+	waitConnected(t, tra, trb)
+
+	validator, err := NewServer(ServerOpts{
+		ID:        "A",
+		Logger:    log.NewNopLogger(),
+		Transport: []Transport{tra},
+		BlockTime: 100 * time.Millisecond,
+		Signer:    signer.NewLocalSigner(crypto.GeneratePrivateKey()),
+	})
+	assert.Nil(t, err)
+	node, err := NewServer(ServerOpts{ID: "B", Logger: log.NewNopLogger(), Transport: []Transport{trb}})
+	assert.Nil(t, err)
+	go validator.Start()
+	go node.Start()
+
+	assert.Eventually(t, func() bool { return node.chain.Height() >= 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// newTestTCPTransport 创建一个监听回环地址的 TCP 传输，测试结束时关闭
+// newTestTCPTransport creates a TCP transport listening on loopback, closed when the test ends
+func newTestTCPTransport(t *testing.T) *TCPTransport {
+	tr := NewTCPTransport("127.0.0.1:0")
+	assert.Nil(t, tr.Listen())
+	t.Cleanup(func() { tr.Close() })
+	return tr
+}
+
+// waitConnected 等待两个传输互相成为对方的节点
+// waitConnected waits until both transports are peers of each other
+func waitConnected(t *testing.T, a, b *TCPTransport) {
+	assert.Eventually(t, func() bool {
+		return len(a.Peers()) == 1 && a.Peers()[0] == b.Addr() && len(b.Peers()) == 1 && b.Peers()[0] == a.Addr()
+	}, 5*time.Second, 10*time.Millisecond)
+}
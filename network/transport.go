@@ -1,13 +1,61 @@
 package network
 
+import "net"
+
 type NetAddr string
 
+// Host 方法返回地址的主机部分，没有端口的地址原样返回，同一主机经由不同端口重新连接时仍然对应同一个主机
+// Host method returns the host part of the address, addresses without a port are returned as they are, a host reconnecting from another port still maps to the same host
+func (a NetAddr) Host() string {
+	host, _, err := net.SplitHostPort(string(a))
+	if err != nil {
+		return string(a)
+	}
+	return host
+}
+
+// PeerEvent 结构体表示节点连接或断开的事件
+// PeerEvent struct represents a peer connecting or disconnecting
+type PeerEvent struct {
+	Addr      NetAddr // 节点地址 // Peer address
+	Connected bool    // 连接时为 true，断开时为 false // True when connected, false when disconnected
+}
+
 // Transport 是一个网络抽象，允许我们在节点之间发送和接收 RPC
 // Transport is a network abstraction that allows us to send and receive RPCs between nodes
 type Transport interface {
 	Consume() <-chan RPC
+	PeerEvents() <-chan PeerEvent
 	Connect(Transport) error
+	Disconnect(NetAddr) error
 	SendMessage(NetAddr, []byte) error
 	Broadcast([]byte) error
 	Addr() NetAddr
 }
+
+// Dialer 接口由可以按地址建立出站连接的传输实现，节点发现通过它连接地址簿中的节点
+// Dialer interface is implemented by transports that can open outbound connections by address, peer discovery uses it to connect to the address book
+type Dialer interface {
+	Transport
+	Dial(NetAddr) error
+}
+
+// PeerLister 接口由可以列出已连接节点的传输实现，服务器用它补上通道已满时丢失的节点事件
+// PeerLister interface is implemented by transports that can list their connected peers, the server uses it to make up for peer events lost to a full channel
+type PeerLister interface {
+	Transport
+	Peers() []NetAddr
+}
+
+// peerEventBuffer 是节点事件通道的缓冲长度，通道已满时事件被丢弃，服务器定期按已连接的节点重新核对
+// peerEventBuffer is the buffer length of the peer event channel, events are dropped when the channel is full and the server periodically checks again against the connected peers
+const peerEventBuffer = 1024
+
+// emitPeerEvent 函数不阻塞地发送节点事件
+// emitPeerEvent function sends a peer event without blocking
+func emitPeerEvent(ch chan PeerEvent, addr NetAddr, connected bool) {
+	select {
+	case ch <- PeerEvent{Addr: addr, Connected: connected}:
+	default:
+	}
+}
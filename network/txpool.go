@@ -1,6 +1,7 @@
 package network
 
 import (
+	"fmt"
 	"github.com/lonySp/go-blockchain/core"
 	"github.com/lonySp/go-blockchain/types"
 	"sync"
@@ -9,9 +10,19 @@ import (
 // TxPool 结构体表示交易池
 // TxPool struct represents a transaction pool
 type TxPool struct {
-	all       *TxSortedMap // 所有交易的有序映射 // Sorted map of all transactions
-	pending   *TxSortedMap // 待处理交易的有序映射 // Sorted map of pending transactions
-	maxLength int          // 交易池的最大长度 // The maximum length of the transaction pool
+	all       *TxSortedMap                // 所有交易的有序映射 // Sorted map of all transactions
+	pending   *TxSortedMap                // 待处理交易的有序映射 // Sorted map of pending transactions
+	maxLength int                         // 交易池的最大长度 // The maximum length of the transaction pool
+	lock      sync.Mutex                  // 保护交易池整体更新的互斥锁 // Mutex guarding updates of the pool as a whole
+	nonces    map[senderNonce]types.Hash  // 发送方序号到交易哈希的索引 // Index from sender nonce to transaction hash
+	bundles   map[types.Hash]*core.Bundle // 交易包 ID 到交易包的索引 // Index from bundle ID to bundle
+}
+
+// senderNonce 结构体表示发送方和序号的组合
+// senderNonce struct represents a combination of sender and nonce
+type senderNonce struct {
+	sender types.Address
+	nonce  uint64
 }
 
 // NewTxPool 创建并返回一个新的 TxPool 实例
@@ -21,23 +32,137 @@ func NewTxPool(maxLength int) *TxPool {
 		all:       NewTxSortedMap(),
 		pending:   NewTxSortedMap(),
 		maxLength: maxLength,
+		nonces:    make(map[senderNonce]types.Hash),
+		bundles:   make(map[types.Hash]*core.Bundle),
 	}
 }
 
-// Add 方法向交易池中添加交易
-// Add method adds a transaction to the transaction pool
-func (p *TxPool) Add(tx *core.Transaction) {
-	// 如果交易池已满，移除最早的交易 // Prune the oldest transaction when the pool is full
-	if p.all.Count() == p.maxLength {
-		oldest := p.all.First()
-		p.all.Remove(oldest.Hash(core.TxHasher{}))
+// Add 方法向交易池中添加交易，同一发送方的同一序号只允许一笔交易
+// Add method adds a transaction to the transaction pool, only one transaction is allowed per sender nonce
+func (p *TxPool) Add(tx *core.Transaction) error {
+	hash := tx.Hash(core.TxHasher{})
+	if tx.Bundle != nil {
+		return fmt.Errorf("transaction (%s) belongs to bundle (%s) and must be added with it", hash, tx.Bundle.ID)
 	}
 
-	// 如果交易池中不包含该交易，则添加到交易池中 // Add the transaction to the pool if it does not already exist
-	if !p.all.Contains(tx.Hash(core.TxHasher{})) {
-		p.all.Add(tx)
-		p.pending.Add(tx)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// 交易池中已包含该交易 // The pool already contains the transaction
+	if p.all.Contains(hash) {
+		return nil
+	}
+	if err := p.checkNonce(tx); err != nil {
+		return err
+	}
+	p.makeRoom(1)
+	p.insert(tx)
+	return nil
+}
+
+// AddBundle 方法将交易包作为一个整体加入交易池，成员交易同时加入或同时移除
+// AddBundle method adds a bundle to the pool as one unit, its members are added and removed together
+func (p *TxPool) AddBundle(b *core.Bundle) error {
+	if len(b.Transactions) > p.maxLength {
+		return fmt.Errorf("bundle (%s) with %d transactions does not fit into the pool", b.ID(), len(b.Transactions))
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// 交易池中已包含该交易包 // The pool already contains the bundle
+	if _, ok := p.bundles[b.ID()]; ok {
+		return nil
+	}
+	for _, tx := range b.Transactions {
+		if p.all.Contains(tx.Hash(core.TxHasher{})) {
+			return fmt.Errorf("transaction (%s) of bundle (%s) is already pooled", tx.Hash(core.TxHasher{}), b.ID())
+		}
+		if err := p.checkNonce(tx); err != nil {
+			return err
+		}
+	}
+	p.makeRoom(len(b.Transactions))
+	for _, tx := range b.Transactions {
+		p.insert(tx)
+	}
+	p.bundles[b.ID()] = b
+	return nil
+}
+
+// checkNonce 方法拒绝重复使用序号的交易，调用方需持有锁
+// checkNonce method rejects transactions reusing a nonce, the caller must hold the lock
+func (p *TxPool) checkNonce(tx *core.Transaction) error {
+	key := senderNonce{sender: tx.Sender(), nonce: tx.Nonce}
+	if other, ok := p.nonces[key]; ok {
+		return fmt.Errorf("transaction (%s) reuses nonce %d of sender (%s) taken by transaction (%s)", tx.Hash(core.TxHasher{}), tx.Nonce, key.sender, other)
+	}
+	return nil
+}
+
+// makeRoom 方法移除最早的交易直到可以容纳 n 笔新交易，调用方需持有锁
+// makeRoom method removes the oldest transactions until n new ones fit, the caller must hold the lock
+func (p *TxPool) makeRoom(n int) {
+	for p.all.Count() > 0 && p.all.Count()+n > p.maxLength {
+		p.remove(p.all.First())
+	}
+}
+
+// insert 方法将交易加入交易池和序号索引，调用方需持有锁
+// insert method adds a transaction to the pool and the nonce index, the caller must hold the lock
+func (p *TxPool) insert(tx *core.Transaction) {
+	p.all.Add(tx)
+	p.pending.Add(tx)
+	p.nonces[senderNonce{sender: tx.Sender(), nonce: tx.Nonce}] = tx.Hash(core.TxHasher{})
+}
+
+// Prune 方法移除序号低于账户当前序号的交易，即已上链或已失效的交易
+// Prune method removes the transactions whose nonce is below the current account nonce, i.e. included or stale ones
+func (p *TxPool) Prune(nonceOf func(types.Address) uint64) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	removed := 0
+	for key, hash := range p.nonces {
+		if tx := p.all.Get(hash); tx != nil && key.nonce < nonceOf(key.sender) {
+			removed += p.remove(tx)
+		}
+	}
+	return removed
+}
+
+// Expire 方法移除在指定高度已过期的交易，返回移除的数量
+// Expire method removes the transactions that are expired at the given height and returns how many were removed
+func (p *TxPool) Expire(height uint32) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	removed := 0
+	for _, hash := range p.nonces {
+		if tx := p.all.Get(hash); tx != nil && tx.Expired(height) {
+			removed += p.remove(tx)
+		}
+	}
+	return removed
+}
+
+// remove 方法从交易池中移除交易，交易包的成员会连同整个交易包一起移除，返回移除的交易数量，调用方需持有锁
+// remove method removes a transaction from the pool, bundle members are removed together with their whole bundle, it returns the number of removed transactions and the caller must hold the lock
+func (p *TxPool) remove(tx *core.Transaction) int {
+	txx := []*core.Transaction{tx}
+	if tx.Bundle != nil {
+		if b, ok := p.bundles[tx.Bundle.ID]; ok {
+			txx = b.Transactions
+			delete(p.bundles, tx.Bundle.ID)
+		}
+	}
+	for _, tx := range txx {
+		hash := tx.Hash(core.TxHasher{})
+		p.all.Remove(hash)
+		p.pending.Remove(hash)
+		delete(p.nonces, senderNonce{sender: tx.Sender(), nonce: tx.Nonce})
 	}
+	return len(txx)
 }
 
 // Contains 方法检查交易池中是否包含某个交易哈希
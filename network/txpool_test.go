@@ -2,6 +2,8 @@ package network
 
 import (
 	"fmt"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
 	"github.com/lonySp/go-blockchain/types"
 	"testing"
 
@@ -122,3 +124,118 @@ func TestListLast(t *testing.T) {
 
 	assert.Equal(t, 3, l.Last())
 }
+
+// TestTxPoolAdd 测试向交易池添加交易
+// TestTxPoolAdd tests adding transactions to the pool
+func TestTxPoolAdd(t *testing.T) {
+	p := NewTxPool(10)
+	tx := randomSignedTx(t, crypto.GeneratePrivateKey(), 0)
+
+	assert.Nil(t, p.Add(tx))
+	assert.Nil(t, p.Add(tx))
+	assert.True(t, p.Contains(tx.Hash(core.TxHasher{})))
+	assert.Equal(t, 1, p.PendingCount())
+}
+
+// TestTxPoolRejectsNonceReuse 测试交易池拒绝同一发送方重复使用序号
+// TestTxPoolRejectsNonceReuse tests that the pool rejects a sender reusing a nonce
+func TestTxPoolRejectsNonceReuse(t *testing.T) {
+	p := NewTxPool(10)
+	privateKey := crypto.GeneratePrivateKey()
+
+	assert.Nil(t, p.Add(randomSignedTx(t, privateKey, 0)))
+	assert.NotNil(t, p.Add(randomSignedTx(t, privateKey, 0)))
+	assert.Nil(t, p.Add(randomSignedTx(t, privateKey, 1)))
+	assert.Equal(t, 2, p.PendingCount())
+}
+
+// TestTxPoolPrune 测试移除序号已失效的交易
+// TestTxPoolPrune tests removing transactions whose nonce became stale
+func TestTxPoolPrune(t *testing.T) {
+	p := NewTxPool(10)
+	privateKey := crypto.GeneratePrivateKey()
+	sender := privateKey.PublicKey().Address()
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, p.Add(randomSignedTx(t, privateKey, uint64(i))))
+	}
+
+	removed := p.Prune(func(addr types.Address) uint64 {
+		if addr == sender {
+			return 2
+		}
+		return 0
+	})
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 1, p.PendingCount())
+	assert.Equal(t, uint64(2), p.Pending()[0].Nonce)
+}
+
+// TestTxPoolExpire 测试交易池移除过期交易
+// TestTxPoolExpire tests the pool evicting expired transactions
+func TestTxPoolExpire(t *testing.T) {
+	p := NewTxPool(10)
+	privateKey := crypto.GeneratePrivateKey()
+
+	for i, validUntil := range []uint32{0, 5, 10} {
+		tx := core.NewDeployTransaction(types.RandomBytes(8))
+		tx.Nonce = uint64(i)
+		tx.ValidUntil = validUntil
+		assert.Nil(t, tx.Sign(privateKey))
+		assert.Nil(t, p.Add(tx))
+	}
+
+	assert.Equal(t, 0, p.Expire(5))
+	assert.Equal(t, 1, p.Expire(6))
+	assert.Equal(t, 2, p.PendingCount())
+	assert.Equal(t, 1, p.Expire(11))
+	assert.Equal(t, 1, p.PendingCount())
+	assert.Equal(t, uint32(0), p.Pending()[0].ValidUntil)
+}
+
+// TestTxPoolBundle 测试交易包作为一个整体加入和移出交易池
+// TestTxPoolBundle tests that a bundle enters and leaves the pool as one unit
+func TestTxPoolBundle(t *testing.T) {
+	p := NewTxPool(3)
+	privateKey := crypto.GeneratePrivateKey()
+	sender := privateKey.PublicKey().Address()
+
+	txx := []*core.Transaction{
+		core.NewDeployTransaction(types.RandomBytes(8)),
+		core.NewDeployTransaction(types.RandomBytes(8)),
+	}
+	for i, tx := range txx {
+		tx.Nonce = uint64(i + 1)
+	}
+	bundle, err := core.NewBundle(txx)
+	assert.Nil(t, err)
+	for _, tx := range txx {
+		assert.Nil(t, tx.Sign(privateKey))
+	}
+
+	// 交易包成员不能单独加入 // Bundle members cannot be added on their own
+	assert.NotNil(t, p.Add(txx[0]))
+
+	assert.Nil(t, p.Add(randomSignedTx(t, privateKey, 0)))
+	assert.Nil(t, p.AddBundle(bundle))
+	assert.Equal(t, 3, p.PendingCount())
+
+	// 一个成员失效时整个交易包被移除 // The whole bundle is removed once one member is stale
+	removed := p.Prune(func(addr types.Address) uint64 {
+		if addr == sender {
+			return 2
+		}
+		return 0
+	})
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, 0, p.PendingCount())
+}
+
+// randomSignedTx 创建一个指定序号的签名交易
+// randomSignedTx creates a signed transaction with the given nonce
+func randomSignedTx(t *testing.T, privateKey crypto.PrivateKey, nonce uint64) *core.Transaction {
+	tx := core.NewDeployTransaction(types.RandomBytes(8))
+	tx.Nonce = nonce
+	assert.Nil(t, tx.Sign(privateKey))
+	return tx
+}
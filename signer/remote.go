@@ -0,0 +1,310 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout 是远程签名请求的默认超时时间
+// DefaultTimeout is the default timeout of a remote signing request
+var DefaultTimeout = 5 * time.Second
+
+// MinSecretLength 是签名服务与验证者共享密钥的最小长度
+// MinSecretLength is the minimum length of the secret shared by the signer daemon and the validator
+const MinSecretLength = 32
+
+// requestType 表示签名服务请求的类型
+// requestType represents the type of a signer daemon request
+type requestType byte
+
+const (
+	requestPublicKey requestType = 0x1 // 获取公钥 // Get the public key
+	requestSignBlock requestType = 0x2 // 签名区块头 // Sign a block header
+)
+
+// challenge 结构体是签名服务在连接建立时发送的随机数，连接上的每个请求都用它计算认证码，使请求无法在其他连接上重放
+// challenge struct is the random nonce the signer daemon sends when a connection is set up, every request of the connection is authenticated with it so requests cannot be replayed on another connection
+type challenge struct {
+	Nonce []byte // 随机数 // Random nonce
+}
+
+// request 结构体是发送给签名服务的请求
+// request struct is a request sent to the signer daemon
+type request struct {
+	Type   requestType  // 请求类型 // Request type
+	Header *core.Header // 待签名的区块头 // Header to sign
+	MAC    []byte       // 用共享密钥计算的请求认证码，没有密钥时为空 // Request authentication code computed with the shared secret, empty without a secret
+}
+
+// mac 方法用共享密钥计算请求的认证码，覆盖连接的随机数、请求序号、请求类型和区块头的规范编码
+// mac method computes the authentication code of the request with the shared secret, covering the connection nonce, the request sequence number, the request type and the canonical header encoding
+func (r *request) mac(secret, nonce []byte, seq uint64) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(nonce)
+	binary.Write(h, binary.BigEndian, seq)
+	h.Write([]byte{byte(r.Type)})
+	if r.Header != nil {
+		h.Write(r.Header.Bytes())
+	}
+	return h.Sum(nil)
+}
+
+// response 结构体是签名服务的响应，策略拒绝时 Error 不为空
+// response struct is the signer daemon's response, Error is set when the policy refuses
+type response struct {
+	PublicKey crypto.PublicKey  // 验证者公钥 // Validator public key
+	Signature *crypto.Signature // 区块签名 // Block signature
+	Error     string            // 错误信息 // Error message
+}
+
+// splitAddr 函数解析签名服务地址，unix:// 开头为 Unix 套接字，否则为 TCP 地址
+// splitAddr function parses a signer daemon address, unix:// means a Unix socket and anything else a TCP address
+func splitAddr(addr string) (string, string) {
+	if path, ok := strings.CutPrefix(addr, "unix://"); ok {
+		return "unix", path
+	}
+	return "tcp", strings.TrimPrefix(addr, "tcp://")
+}
+
+// ReadSecret 函数从文件读取签名服务与验证者共享的密钥，去掉首尾的空白，密钥太短时返回错误
+// ReadSecret function reads the secret shared by the signer daemon and the validator from the file, trimming surrounding whitespace, an error is returned when the secret is too short
+func ReadSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret := bytes.TrimSpace(data)
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("signer secret %s is shorter than %d bytes", path, MinSecretLength)
+	}
+	return secret, nil
+}
+
+// Listen 函数在 unix:///path 或 host:port 地址上监听签名请求
+// Listen function listens for signing requests on a unix:///path or host:port address
+func Listen(addr string) (net.Listener, error) {
+	network, address := splitAddr(addr)
+	return net.Listen(network, address)
+}
+
+// Server 结构体是签名服务，它持有私钥，只处理用共享密钥认证的请求，并在签名前执行自己的双签保护策略
+// Server struct is the signer daemon, it holds the private key, only handles requests authenticated with the shared secret and enforces its own double sign policy before signing
+type Server struct {
+	signer *Guard     // 带策略的签名者 // Signer with the policy
+	secret []byte     // 与验证者共享的密钥，为空时只能监听 Unix 套接字 // Secret shared with the validator, only Unix sockets may be served when empty
+	logger log.Logger // 日志记录器 // Logger
+}
+
+// NewServer 创建签名服务，所有签名请求都经过给定的双签保护，密钥为空时不认证请求，只能由文件权限保护的 Unix 套接字使用
+// NewServer creates the signer daemon, every signing request goes through the given double sign guard, requests are not authenticated when the secret is empty which is only allowed on Unix sockets protected by file permissions
+func NewServer(g *Guard, secret []byte, logger log.Logger) *Server {
+	if logger == nil {
+		logger = log.NewLogfmtLogger(os.Stderr)
+	}
+	return &Server{signer: g, secret: secret, logger: logger}
+}
+
+// Serve 方法接受连接并处理请求，直到监听器关闭，没有共享密钥时拒绝在 Unix 套接字以外的监听器上服务
+// Serve method accepts connections and handles requests until the listener is closed, without a shared secret it refuses to serve on anything but a Unix socket
+func (s *Server) Serve(l net.Listener) error {
+	if len(s.secret) == 0 && l.Addr().Network() != "unix" {
+		return fmt.Errorf("signer daemon on %s %s needs a shared secret", l.Addr().Network(), l.Addr())
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn 方法发送连接的随机数，然后依次处理连接上的请求，认证失败时关闭连接
+// handleConn method sends the nonce of the connection and then handles its requests in order, the connection is closed when authentication fails
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	dec := gob.NewDecoder(conn)
+	enc := gob.NewEncoder(conn)
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return
+	}
+	if err := enc.Encode(&challenge{Nonce: nonce}); err != nil {
+		return
+	}
+	for seq := uint64(0); ; seq++ {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if len(s.secret) > 0 && !hmac.Equal(req.MAC, req.mac(s.secret, nonce, seq)) {
+			s.logger.Log("msg", "refused unauthenticated request", "remote", conn.RemoteAddr())
+			enc.Encode(&response{Error: "unauthenticated request"})
+			return
+		}
+
+		res := response{PublicKey: s.signer.PublicKey()}
+		switch req.Type {
+		case requestPublicKey:
+		case requestSignBlock:
+			if req.Header == nil {
+				res.Error = "missing block header"
+				break
+			}
+			sig, err := s.signer.SignBlock(req.Header)
+			if err != nil {
+				s.logger.Log("msg", "refused to sign block", "height", req.Header.Height, "err", err)
+				res.Error = err.Error()
+				break
+			}
+			s.logger.Log("msg", "signed block", "height", req.Header.Height)
+			res.Signature = sig
+		default:
+			res.Error = fmt.Sprintf("invalid request type (%x)", req.Type)
+		}
+
+		if err := enc.Encode(&res); err != nil {
+			return
+		}
+	}
+}
+
+// RemoteSigner 结构体通过 Unix 套接字或 TCP 连接签名服务，用共享密钥认证每个请求，连接断开后在下次请求时重连
+// RemoteSigner struct talks to the signer daemon over a Unix socket or TCP, authenticating every request with the shared secret, a broken connection is redialed on the next request
+type RemoteSigner struct {
+	addr      string           // 签名服务地址 // Signer daemon address
+	secret    []byte           // 与签名服务共享的密钥 // Secret shared with the signer daemon
+	timeout   time.Duration    // 请求超时时间 // Request timeout
+	publicKey crypto.PublicKey // 验证者公钥 // Validator public key
+
+	lock  sync.Mutex   // 保证请求按顺序进行的互斥锁 // Mutex keeping requests in order
+	conn  net.Conn     // 当前连接 // Current connection
+	enc   *gob.Encoder // 请求编码器 // Request encoder
+	dec   *gob.Decoder // 响应解码器 // Response decoder
+	nonce []byte       // 当前连接的随机数 // Nonce of the current connection
+	seq   uint64       // 当前连接上下一个请求的序号 // Sequence number of the next request on the current connection
+}
+
+// DialRemoteSigner 用共享密钥连接签名服务并获取验证者公钥，签名服务监听 Unix 套接字且不要求认证时密钥可以为空
+// DialRemoteSigner connects to the signer daemon with the shared secret and fetches the validator public key, the secret may be empty when the daemon listens on a Unix socket without authentication
+func DialRemoteSigner(addr string, secret []byte) (*RemoteSigner, error) {
+	s := &RemoteSigner{addr: addr, secret: secret, timeout: DefaultTimeout}
+	res, err := s.roundTrip(&request{Type: requestPublicKey})
+	if err != nil {
+		return nil, err
+	}
+	if res.PublicKey.IsZero() {
+		return nil, fmt.Errorf("signer at %s returned no public key", addr)
+	}
+	s.publicKey = res.PublicKey
+	return s, nil
+}
+
+// PublicKey 方法返回连接时获取的验证者公钥
+// PublicKey method returns the validator public key fetched when connecting
+func (s *RemoteSigner) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+// SignBlock 方法请求签名服务签名，并在本地验证返回的签名
+// SignBlock method asks the signer daemon for a signature and verifies the returned signature locally
+func (s *RemoteSigner) SignBlock(h *core.Header) (*crypto.Signature, error) {
+	res, err := s.roundTrip(&request{Type: requestSignBlock, Header: h})
+	if err != nil {
+		return nil, err
+	}
+	if res.Signature == nil {
+		return nil, fmt.Errorf("signer returned no signature")
+	}
+	digest := core.BlockHasher{}.Hash(h)
+	if !res.Signature.Verify(s.publicKey, digest[:]) {
+		return nil, fmt.Errorf("signer returned an invalid signature")
+	}
+	return res.Signature, nil
+}
+
+// Close 方法关闭与签名服务的连接
+// Close method closes the connection to the signer daemon
+func (s *RemoteSigner) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// roundTrip 方法发送一个认证过的请求并等待响应，连接出错时关闭连接
+// roundTrip method sends an authenticated request and waits for the response, the connection is closed on errors
+func (s *RemoteSigner) roundTrip(req *request) (*response, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.secret) > 0 {
+		req.MAC = req.mac(s.secret, s.nonce, s.seq)
+	}
+	s.seq++
+
+	res := &response{}
+	s.conn.SetDeadline(time.Now().Add(s.timeout))
+	err := s.enc.Encode(req)
+	if err == nil {
+		err = s.dec.Decode(res)
+	}
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return nil, err
+	}
+
+	if res.Error != "" {
+		return nil, fmt.Errorf("signer refused: %s", res.Error)
+	}
+	return res, nil
+}
+
+// dial 方法在持有锁时连接签名服务，并读取连接的随机数
+// dial method connects to the signer daemon while the lock is held and reads the nonce of the connection
+func (s *RemoteSigner) dial() error {
+	network, address := splitAddr(s.addr)
+	conn, err := net.DialTimeout(network, address, s.timeout)
+	if err != nil {
+		return err
+	}
+	dec := gob.NewDecoder(conn)
+	var c challenge
+	conn.SetDeadline(time.Now().Add(s.timeout))
+	if err := dec.Decode(&c); err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn, s.enc, s.dec, s.nonce, s.seq = conn, gob.NewEncoder(conn), dec, c.Nonce, 0
+	return nil
+}
@@ -0,0 +1,193 @@
+package signer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"sync"
+)
+
+// Signer 接口定义了验证者的区块签名方法，私钥可以在本进程中，也可以在独立的签名服务中
+// Signer interface defines the block signing methods of a validator, the private key may live in this process or in a separate signer daemon
+type Signer interface {
+	// PublicKey 返回验证者公钥 // PublicKey returns the validator's public key
+	PublicKey() crypto.PublicKey
+	// SignBlock 对区块头规范编码的摘要签名 // SignBlock signs the digest of the canonical header encoding
+	SignBlock(h *core.Header) (*crypto.Signature, error)
+}
+
+// proposalSigner 接口由可以记录完整提议区块的签名者实现，以便重启后重新提议已签名的区块
+// proposalSigner interface is implemented by signers that can record the full proposal, so the signed block can be proposed again after a restart
+type proposalSigner interface {
+	signProposal(b *core.Block) error
+}
+
+// SignBlock 函数使用签名者对区块签名，并设置区块的验证者公钥和签名
+// SignBlock function signs the block with the signer and sets the block's validator public key and signature
+func SignBlock(s Signer, b *core.Block) error {
+	if p, ok := s.(proposalSigner); ok {
+		return p.signProposal(b)
+	}
+	sig, err := s.SignBlock(b.Header)
+	if err != nil {
+		return err
+	}
+	b.Validator = s.PublicKey()
+	b.Signature = sig
+	return nil
+}
+
+// LocalSigner 结构体使用本进程中的私钥签名
+// LocalSigner struct signs with a private key held in this process
+type LocalSigner struct {
+	privateKey crypto.PrivateKey // 验证者私钥 // Validator private key
+}
+
+// NewLocalSigner 创建一个使用给定私钥的本地签名者
+// NewLocalSigner creates a local signer using the given private key
+func NewLocalSigner(privateKey crypto.PrivateKey) *LocalSigner {
+	return &LocalSigner{privateKey: privateKey}
+}
+
+// PublicKey 方法返回私钥对应的公钥
+// PublicKey method returns the public key of the private key
+func (s *LocalSigner) PublicKey() crypto.PublicKey {
+	return s.privateKey.PublicKey()
+}
+
+// SignBlock 方法对区块头摘要签名，与 Block.Sign 的签名相同
+// SignBlock method signs the header digest, the signature is the same as the one of Block.Sign
+func (s *LocalSigner) SignBlock(h *core.Header) (*crypto.Signature, error) {
+	digest := core.BlockHasher{}.Hash(h)
+	return s.privateKey.Sign(digest[:])
+}
+
+// DefaultMaxHeightGap 是双签保护默认允许的新高度与上次签名高度的最大差距
+// DefaultMaxHeightGap is the default maximum gap the double sign guard allows between a new height and the last signed height
+const DefaultMaxHeightGap uint32 = 10000
+
+// Guard 结构体包装签名者，拒绝在已签名的高度签署不同的区块头，也拒绝签署更低的高度或远高于上次签名的高度，签名记录可以保存在文件中以便重启后仍然有效
+// Guard struct wraps a signer and refuses to sign a different header at an already signed height, as well as any lower height or one far above the last signed height, the sign record can be kept in a file so it survives restarts
+type Guard struct {
+	signer Signer // 被包装的签名者 // Wrapped signer
+	path   string // 签名记录文件，为空时只保存在内存中 // Sign record file, only kept in memory when empty
+	maxGap uint32 // 新高度与上次签名高度的最大差距，为 0 时不限制 // Maximum gap between a new height and the last signed height, unlimited when 0
+
+	lock  sync.Mutex // 保护签名记录的互斥锁 // Mutex guarding the sign record
+	state *signState // 最近的签名记录，从未签名时为空 // Last sign record, nil when nothing was signed yet
+}
+
+// NewGuard 创建一个包装给定签名者的双签保护，签名记录只保存在内存中
+// NewGuard creates a double sign guard wrapping the given signer, the sign record is only kept in memory
+func NewGuard(s Signer) *Guard {
+	return &Guard{signer: s, maxGap: DefaultMaxHeightGap}
+}
+
+// OpenGuard 创建一个双签保护，并从文件读取之前的签名记录，文件不存在时从空记录开始
+// OpenGuard creates a double sign guard and reads the previous sign record from the file, starting from an empty record when the file does not exist
+func OpenGuard(s Signer, path string) (*Guard, error) {
+	state, err := loadSignState(path)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.publicKey != nil && !bytes.Equal(state.publicKey, s.PublicKey().ToSlice()) {
+		return nil, fmt.Errorf("sign record %s belongs to another validator key", path)
+	}
+	return &Guard{signer: s, path: path, maxGap: DefaultMaxHeightGap, state: state}, nil
+}
+
+// SetMaxHeightGap 方法设置新高度与上次签名高度的最大差距，验证者长时间离线后链已经超出差距时需要调大，为 0 时不限制
+// SetMaxHeightGap method sets the maximum gap between a new height and the last signed height, it has to be raised when the chain moved further than that while the validator was offline, 0 means unlimited
+func (g *Guard) SetMaxHeightGap(gap uint32) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.maxGap = gap
+}
+
+// PublicKey 方法返回被包装签名者的公钥
+// PublicKey method returns the public key of the wrapped signer
+func (g *Guard) PublicKey() crypto.PublicKey {
+	return g.signer.PublicKey()
+}
+
+// SignBlock 方法在策略允许时签名，签名记录写入文件后才返回签名，再次请求同一区块头时返回相同的签名
+// SignBlock method signs when the policy allows it, the signature is only returned once the sign record is written to the file, asking again for the same header returns the same signature
+func (g *Guard) SignBlock(h *core.Header) (*crypto.Signature, error) {
+	return g.sign(h, nil)
+}
+
+// signProposal 方法签名完整的提议区块，并把签名后的区块一起写入签名记录
+// signProposal method signs the full proposal and writes the signed block into the sign record as well
+func (g *Guard) signProposal(b *core.Block) error {
+	_, err := g.sign(b.Header, b)
+	return err
+}
+
+// Proposal 方法返回最近签名的完整提议区块，最近一次只签名了区块头或记录无法解码时返回空
+// Proposal method returns the full proposal signed last, nil when only a header was signed last or the record cannot be decoded
+func (g *Guard) Proposal() *core.Block {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.state == nil || len(g.state.block) == 0 {
+		return nil
+	}
+	b := new(core.Block)
+	if err := b.Decode(core.NewProtobufBlockDecoder(bytes.NewReader(g.state.block))); err != nil {
+		return nil
+	}
+	return b
+}
+
+// sign 方法按策略签名区块头，给出区块时设置它的验证者公钥和签名，并把它记录为提议区块
+// sign method signs the header following the policy, when the block is given its validator public key and signature are set and it is recorded as the proposal
+func (g *Guard) sign(h *core.Header, b *core.Block) (*crypto.Signature, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	// 目前每个高度只有一轮出块 // For now there is a single proposal round per height
+	next := &signState{height: h.Height, round: 0, hash: core.BlockHasher{}.Hash(h)}
+	if last := g.state; last != nil {
+		// 同一区块头可以重复签名，例如请求超时后重试 // The same header may be signed again, e.g. when retrying after a timeout
+		if next.height == last.height && next.round == last.round && next.hash == last.hash && last.signature != nil {
+			if b != nil {
+				b.Validator, b.Signature = g.signer.PublicKey(), last.signature
+			}
+			return last.signature, nil
+		}
+		if next.height < last.height || (next.height == last.height && next.round <= last.round) {
+			return nil, fmt.Errorf("refusing to sign block (%s) at height %d round %d, already signed block (%s) at height %d round %d",
+				next.hash, next.height, next.round, last.hash, last.height, last.round)
+		}
+		// 远高于上次签名的高度会让之后所有正常的高度都被拒绝 // A height far above the last signed one would make every regular height afterwards refused
+		if g.maxGap > 0 && next.height > last.height && next.height-last.height > g.maxGap {
+			return nil, fmt.Errorf("refusing to sign block (%s) at height %d, more than %d heights above the last signed height %d",
+				next.hash, next.height, g.maxGap, last.height)
+		}
+	}
+
+	sig, err := g.signer.SignBlock(h)
+	if err != nil {
+		return nil, err
+	}
+	next.publicKey, next.signature = g.signer.PublicKey().ToSlice(), sig
+	if b != nil {
+		b.Validator, b.Signature = g.signer.PublicKey(), sig
+		buf := &bytes.Buffer{}
+		if err := b.Encode(core.NewProtobufBlockEncoder(buf)); err != nil {
+			return nil, err
+		}
+		next.block = buf.Bytes()
+	}
+
+	// 崩溃发生在写入之前时签名还未交出，因此是安全的 // A crash before the write is safe because the signature has not been handed out yet
+	if g.path != "" {
+		if err := next.save(g.path); err != nil {
+			return nil, err
+		}
+	}
+	g.state = next
+	return sig, nil
+}
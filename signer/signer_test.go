@@ -0,0 +1,219 @@
+package signer
+
+import (
+	"encoding/gob"
+	"github.com/go-kit/log"
+	"github.com/lonySp/go-blockchain/core"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLocalSigner 测试本地签名者的区块签名可以通过区块验证
+// TestLocalSigner tests that blocks signed by the local signer pass block verification
+func TestLocalSigner(t *testing.T) {
+	privateKey := crypto.GeneratePrivateKey()
+	s := NewLocalSigner(privateKey)
+	assert.Equal(t, privateKey.PublicKey(), s.PublicKey())
+
+	b := randomBlock(t, 1)
+	assert.Nil(t, SignBlock(s, b))
+	assert.Equal(t, privateKey.PublicKey(), b.Validator)
+	assert.Nil(t, b.Verify())
+}
+
+// TestGuard 测试双签保护拒绝在同一高度签署不同的区块头和签署更低的高度
+// TestGuard tests that the double sign guard refuses a different header at the same height and lower heights
+func TestGuard(t *testing.T) {
+	g := NewGuard(NewLocalSigner(crypto.GeneratePrivateKey()))
+
+	b := randomBlock(t, 2)
+	sig, err := g.SignBlock(b.Header)
+	assert.Nil(t, err)
+
+	// 同一区块头重复签名返回相同的签名 // Signing the same header again returns the same signature
+	again, err := g.SignBlock(b.Header)
+	assert.Nil(t, err)
+	assert.Equal(t, sig, again)
+
+	_, err = g.SignBlock(randomBlock(t, 2).Header)
+	assert.NotNil(t, err)
+	_, err = g.SignBlock(randomBlock(t, 1).Header)
+	assert.NotNil(t, err)
+
+	_, err = g.SignBlock(randomBlock(t, 3).Header)
+	assert.Nil(t, err)
+
+	// 远高于上次签名的高度被拒绝，调大差距后可以签名 // A height far above the last signed one is refused until the gap is raised
+	_, err = g.SignBlock(randomBlock(t, 4+DefaultMaxHeightGap).Header)
+	assert.NotNil(t, err)
+	_, err = g.SignBlock(randomBlock(t, 3+DefaultMaxHeightGap).Header)
+	assert.Nil(t, err)
+	g.SetMaxHeightGap(0)
+	_, err = g.SignBlock(randomBlock(t, 10*DefaultMaxHeightGap).Header)
+	assert.Nil(t, err)
+}
+
+// TestRemoteSigner 测试远程签名者通过 Unix 套接字签名，并由签名服务执行双签保护
+// TestRemoteSigner tests that the remote signer signs over a Unix socket and that the daemon enforces the double sign guard
+func TestRemoteSigner(t *testing.T) {
+	privateKey := crypto.GeneratePrivateKey()
+	addr := "unix://" + filepath.Join(t.TempDir(), "signer.sock")
+	l, err := Listen(addr)
+	assert.Nil(t, err)
+	defer l.Close()
+	go NewServer(NewGuard(NewLocalSigner(privateKey)), nil, log.NewNopLogger()).Serve(l)
+
+	s, err := DialRemoteSigner(addr, nil)
+	assert.Nil(t, err)
+	defer s.Close()
+	assert.Equal(t, privateKey.PublicKey(), s.PublicKey())
+
+	b := randomBlock(t, 1)
+	assert.Nil(t, SignBlock(s, b))
+	assert.Nil(t, b.Verify())
+
+	// 签名服务拒绝在同一高度签署另一个区块，连接仍然可用 // The daemon refuses another block at the same height and the connection stays usable
+	assert.NotNil(t, SignBlock(s, randomBlock(t, 1)))
+	assert.Nil(t, SignBlock(s, randomBlock(t, 2)))
+
+	// 连接断开后下一个请求会重新连接 // The next request redials after the connection is dropped
+	assert.Nil(t, s.Close())
+	assert.Nil(t, SignBlock(s, randomBlock(t, 3)))
+}
+
+// TestRemoteSignerAuth 测试 TCP 上的签名服务只处理用共享密钥认证的请求，请求不能在其他位置重放
+// TestRemoteSignerAuth tests that the signer daemon on TCP only handles requests authenticated with the shared secret and that requests cannot be replayed elsewhere
+func TestRemoteSignerAuth(t *testing.T) {
+	privateKey := crypto.GeneratePrivateKey()
+	guard := NewGuard(NewLocalSigner(privateKey))
+	l, err := Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+
+	// 没有密钥时不在 TCP 上服务 // No service on TCP without a secret
+	assert.NotNil(t, NewServer(guard, nil, log.NewNopLogger()).Serve(l))
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	go NewServer(guard, secret, log.NewNopLogger()).Serve(l)
+	addr := l.Addr().String()
+
+	_, err = DialRemoteSigner(addr, nil)
+	assert.NotNil(t, err)
+	_, err = DialRemoteSigner(addr, []byte("fedcba9876543210fedcba9876543210"))
+	assert.NotNil(t, err)
+
+	s, err := DialRemoteSigner(addr, secret)
+	assert.Nil(t, err)
+	defer s.Close()
+	b := randomBlock(t, 1)
+	assert.Nil(t, SignBlock(s, b))
+	assert.Nil(t, b.Verify())
+
+	// 为其他序号计算的认证码被拒绝 // An authentication code computed for another sequence number is refused
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+	dec, enc := gob.NewDecoder(conn), gob.NewEncoder(conn)
+	var c challenge
+	assert.Nil(t, dec.Decode(&c))
+	req := &request{Type: requestSignBlock, Header: randomBlock(t, 2).Header}
+	req.MAC = req.mac(secret, c.Nonce, 1)
+	assert.Nil(t, enc.Encode(req))
+	var res response
+	assert.Nil(t, dec.Decode(&res))
+	assert.Nil(t, res.Signature)
+	assert.NotEmpty(t, res.Error)
+}
+
+// TestReadSecret 测试读取共享密钥时去掉首尾空白并拒绝太短的密钥
+// TestReadSecret tests that reading the shared secret trims surrounding whitespace and refuses secrets that are too short
+func TestReadSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	assert.Nil(t, os.WriteFile(path, []byte("0123456789abcdef0123456789abcdef\n"), 0600))
+	secret, err := ReadSecret(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), secret)
+
+	assert.Nil(t, os.WriteFile(path, []byte("short\n"), 0600))
+	_, err = ReadSecret(path)
+	assert.NotNil(t, err)
+}
+
+// randomBlock 创建一个指定高度的随机区块
+// randomBlock creates a random block at the given height
+func randomBlock(t *testing.T, height uint32) *core.Block {
+	b, err := core.NewBlockFromPrevHeader(&core.Header{Version: core.HeaderVersion, Height: height - 1, ChainID: 1, DataHash: types.RandomHash()}, nil)
+	assert.Nil(t, err)
+	return b
+}
+
+// TestGuardPersistent 测试签名记录在重新打开后仍然有效，并且属于其他私钥的记录被拒绝
+// TestGuardPersistent tests that the sign record is still in force after reopening and that a record of another key is refused
+func TestGuardPersistent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sign_state.json")
+	s := NewLocalSigner(crypto.GeneratePrivateKey())
+
+	g, err := OpenGuard(s, path)
+	assert.Nil(t, err)
+	b := randomBlock(t, 5)
+	sig, err := g.SignBlock(b.Header)
+	assert.Nil(t, err)
+
+	// 模拟重启 // Simulate a restart
+	g, err = OpenGuard(s, path)
+	assert.Nil(t, err)
+	_, err = g.SignBlock(randomBlock(t, 5).Header)
+	assert.NotNil(t, err)
+	_, err = g.SignBlock(randomBlock(t, 4).Header)
+	assert.NotNil(t, err)
+	again, err := g.SignBlock(b.Header)
+	assert.Nil(t, err)
+	assert.Equal(t, sig, again)
+	_, err = g.SignBlock(randomBlock(t, 6).Header)
+	assert.Nil(t, err)
+
+	_, err = OpenGuard(NewLocalSigner(crypto.GeneratePrivateKey()), path)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = OpenGuard(s, path)
+	assert.NotNil(t, err)
+}
+
+// TestGuardProposal 测试双签保护记录签名的完整提议区块，重启后仍然可以取回
+// TestGuardProposal tests that the double sign guard records the signed full proposal and it can be read back after a restart
+func TestGuardProposal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sign_state.json")
+	s := NewLocalSigner(crypto.GeneratePrivateKey())
+
+	g, err := OpenGuard(s, path)
+	assert.Nil(t, err)
+	assert.Nil(t, g.Proposal())
+	b := randomBlock(t, 5)
+	assert.Nil(t, SignBlock(g, b))
+	assert.Nil(t, b.Verify())
+
+	// 模拟重启 // Simulate a restart
+	g, err = OpenGuard(s, path)
+	assert.Nil(t, err)
+	proposal := g.Proposal()
+	assert.NotNil(t, proposal)
+	assert.Nil(t, proposal.Verify())
+	assert.Equal(t, b.Hash(core.BlockHasher{}), proposal.Hash(core.BlockHasher{}))
+	assert.Equal(t, b.Signature, proposal.Signature)
+
+	// 再次签名同一区块得到相同的签名 // Signing the same block again gives the same signature
+	again := &core.Block{Header: b.Header, Transactions: b.Transactions}
+	assert.Nil(t, SignBlock(g, again))
+	assert.Equal(t, b.Signature, again.Signature)
+
+	// 只签名区块头后不再有提议区块 // There is no proposal any more once only a header was signed
+	_, err = g.SignBlock(randomBlock(t, 6).Header)
+	assert.Nil(t, err)
+	assert.Nil(t, g.Proposal())
+}
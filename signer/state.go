@@ -0,0 +1,127 @@
+package signer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lonySp/go-blockchain/crypto"
+	"github.com/lonySp/go-blockchain/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// signState 结构体记录验证者最近签名的高度、轮次和区块哈希
+// signState struct records the height, round and block hash the validator signed last
+type signState struct {
+	height    uint32            // 签名的高度 // Signed height
+	round     uint32            // 签名的轮次 // Signed round
+	hash      types.Hash        // 签名的区块哈希 // Signed block hash
+	publicKey []byte            // 签名者公钥的编码 // Encoding of the signer's public key
+	signature *crypto.Signature // 签名 // Signature
+	block     []byte            // 签名的完整提议区块的编码，只签名区块头时为空 // Encoding of the signed full proposal, empty when only the header was signed
+}
+
+// signStateJSON 结构体是签名记录文件的 JSON 格式
+// signStateJSON struct is the JSON format of the sign record file
+type signStateJSON struct {
+	PublicKey string `json:"publicKey"`       // 十六进制公钥 // Hex encoded public key
+	Height    uint32 `json:"height"`          // 签名的高度 // Signed height
+	Round     uint32 `json:"round"`           // 签名的轮次 // Signed round
+	Hash      string `json:"hash"`            // 十六进制区块哈希 // Hex encoded block hash
+	Signature string `json:"signature"`       // 十六进制签名 // Hex encoded signature
+	Block     string `json:"block,omitempty"` // 十六进制的提议区块编码 // Hex encoded proposal block
+}
+
+// loadSignState 函数从文件读取签名记录，文件不存在时返回空记录
+// loadSignState function reads the sign record from the file, returning a nil record when the file does not exist
+func loadSignState(path string) (*signState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var in signStateJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("invalid sign record %s: %w", path, err)
+	}
+	publicKey, err := hex.DecodeString(in.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign record %s: public key: %w", path, err)
+	}
+	hash, err := hex.DecodeString(in.Hash)
+	if err != nil || len(hash) != len(types.Hash{}) {
+		return nil, fmt.Errorf("invalid sign record %s: invalid hash %q", path, in.Hash)
+	}
+	sigBytes, err := hex.DecodeString(in.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign record %s: signature: %w", path, err)
+	}
+	sig, err := crypto.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign record %s: %w", path, err)
+	}
+	block, err := hex.DecodeString(in.Block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign record %s: block: %w", path, err)
+	}
+	return &signState{
+		height:    in.Height,
+		round:     in.Round,
+		hash:      types.HashFromBytes(hash),
+		publicKey: publicKey,
+		signature: sig,
+		block:     block,
+	}, nil
+}
+
+// save 方法将签名记录原子地写入文件：先写入同目录的临时文件并同步到磁盘，再重命名覆盖原文件
+// save method writes the sign record to the file atomically: it is written to a temporary file in the same directory and synced to disk, then renamed over the original file
+func (s *signState) save(path string) error {
+	data, err := json.MarshalIndent(signStateJSON{
+		PublicKey: hex.EncodeToString(s.publicKey),
+		Height:    s.height,
+		Round:     s.round,
+		Hash:      hex.EncodeToString(s.hash[:]),
+		Signature: hex.EncodeToString(s.signature.ToBytes()),
+		Block:     hex.EncodeToString(s.block),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	// 同步目录使重命名本身在崩溃后仍然有效 // Sync the directory so the rename itself survives a crash
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
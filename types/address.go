@@ -1,10 +1,13 @@
 package types
 
 import (
-	"encoding/hex"
 	"fmt"
 )
 
+// AddressPrefix 是地址的网络前缀，地址以 bech32m 编码为 gbc1 开头的字符串
+// AddressPrefix is the network prefix of addresses, addresses are bech32m encoded as strings starting with gbc1
+const AddressPrefix = "gbc"
+
 // Address 结构体表示一个20字节的地址
 // Address struct represents a 20-byte address
 type Address [20]uint8
@@ -19,10 +22,48 @@ func (a Address) ToSlice() []byte {
 	return b
 }
 
-// String 方法将地址转换为字符串
-// String method converts the address to a string
+// String 方法将地址编码为带网络前缀和校验和的 bech32m 字符串
+// String method encodes the address as a bech32m string with the network prefix and a checksum
 func (a Address) String() string {
-	return hex.EncodeToString(a.ToSlice())
+	data, _ := convertBits(a[:], 8, 5, true)
+	return bech32mEncode(AddressPrefix, data)
+}
+
+// ParseAddress 函数解析 bech32m 地址，校验网络前缀、校验和和长度
+// ParseAddress function parses a bech32m address, checking the network prefix, the checksum and the length
+func ParseAddress(s string) (Address, error) {
+	hrp, data, err := bech32mDecode(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	if hrp != AddressPrefix {
+		return Address{}, fmt.Errorf("invalid address %q: prefix %q, expected %q", s, hrp, AddressPrefix)
+	}
+	b, err := convertBits(data, 5, 8, false)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	if len(b) != len(Address{}) {
+		return Address{}, fmt.Errorf("invalid address %q: length %d, expected %d", s, len(b), len(Address{}))
+	}
+	return NewAddressFromBytes(b), nil
+}
+
+// MarshalText 方法将地址编码为 bech32m 文本，用于 JSON 和配置文件
+// MarshalText method encodes the address as bech32m text, used by JSON and configuration files
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText 方法解析 bech32m 文本并校验校验和
+// UnmarshalText method parses bech32m text and checks its checksum
+func (a *Address) UnmarshalText(text []byte) error {
+	addr, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
 }
 
 // NewAddressFromBytes 函数从字节切片创建一个地址
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBech32mVectors 测试 BIP-350 中有效的 bech32m 字符串
+// TestBech32mVectors tests valid bech32m strings from BIP-350
+func TestBech32mVectors(t *testing.T) {
+	for _, s := range []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	} {
+		_, _, err := bech32mDecode(s)
+		assert.Nil(t, err, s)
+	}
+
+	for _, s := range []string{
+		"A1LqFN3A", // 大小写混合 // Mixed case
+		"a1lqfn3b", // 校验和错误 // Wrong checksum
+		"1lqfn3a",  // 缺少前缀 // Missing prefix
+		"a1lqfn",   // 校验和过短 // Checksum too short
+	} {
+		_, _, err := bech32mDecode(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+// TestParseAddress 测试地址的编码往返，以及拒绝错误前缀和单字符错误
+// TestParseAddress tests the address encoding round trip and rejecting wrong prefixes and single character typos
+func TestParseAddress(t *testing.T) {
+	addr := NewAddressFromBytes(RandomBytes(20))
+	s := addr.String()
+	assert.True(t, strings.HasPrefix(s, AddressPrefix+"1"))
+
+	parsed, err := ParseAddress(s)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, parsed)
+	parsed, err = ParseAddress(strings.ToUpper(s))
+	assert.Nil(t, err)
+	assert.Equal(t, addr, parsed)
+
+	// 每个位置的任意单字符替换都会被检测到 // Any single character substitution at any position is detected
+	for i := len(AddressPrefix) + 1; i < len(s); i++ {
+		for _, c := range bech32Charset {
+			if byte(c) == s[i] {
+				continue
+			}
+			typo := s[:i] + string(c) + s[i+1:]
+			_, err := ParseAddress(typo)
+			assert.NotNil(t, err, typo)
+		}
+	}
+
+	data, _ := convertBits(addr[:], 8, 5, true)
+	_, err = ParseAddress(bech32mEncode("test", data))
+	assert.NotNil(t, err)
+	_, err = ParseAddress(bech32mEncode(AddressPrefix, data[:len(data)-1]))
+	assert.NotNil(t, err)
+	_, err = ParseAddress(addr.String()[:10])
+	assert.NotNil(t, err)
+}
+
+// TestAddressJSON 测试地址通过 JSON 的往返，包括作为映射的键
+// TestAddressJSON tests the address round trip through JSON, including as a map key
+func TestAddressJSON(t *testing.T) {
+	in := map[Address][]Address{NewAddressFromBytes(RandomBytes(20)): {NewAddressFromBytes(RandomBytes(20)), {}}}
+	data, err := json.Marshal(in)
+	assert.Nil(t, err)
+
+	out := map[Address][]Address{}
+	assert.Nil(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	var addr Address
+	assert.NotNil(t, json.Unmarshal([]byte(`"0100000000000000000000000000000000000000"`), &addr))
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bech32Charset 是 bech32 的 32 个字符，按 5 位值排列
+// bech32Charset are the 32 characters of bech32, ordered by 5 bit value
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32mConst 是 BIP-350 bech32m 校验和的常量
+// bech32mConst is the constant of the BIP-350 bech32m checksum
+const bech32mConst = 0x2bc830a3
+
+// bech32MaxLength 是 bech32 字符串的最大长度
+// bech32MaxLength is the maximum length of a bech32 string
+const bech32MaxLength = 90
+
+// bech32Polymod 函数计算 BCH 校验多项式
+// bech32Polymod function computes the BCH checksum polynomial
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand 函数展开前缀用于校验和计算
+// bech32HRPExpand function expands the prefix for the checksum computation
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32mEncode 函数将前缀和 5 位数据编码为带 6 个字符校验和的 bech32m 字符串
+// bech32mEncode function encodes the prefix and 5 bit data as a bech32m string with a 6 character checksum
+func bech32mEncode(hrp string, data []byte) string {
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ bech32mConst
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range data {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// bech32mDecode 函数解码 bech32m 字符串并校验校验和，拒绝大小写混合
+// bech32mDecode function decodes a bech32m string and checks its checksum, rejecting mixed case
+func bech32mDecode(s string) (string, []byte, error) {
+	if len(s) > bech32MaxLength {
+		return "", nil, fmt.Errorf("bech32 string too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("bech32 string has mixed case")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 separator position")
+	}
+	hrp := s[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid bech32 prefix character %q", hrp[i])
+		}
+	}
+
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q", s[i])
+		}
+		data = append(data, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != bech32mConst {
+		return "", nil, fmt.Errorf("invalid bech32m checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits 函数在不同位宽之间重新分组，pad 为 false 时拒绝非零填充
+// convertBits function regroups bits between widths, rejecting non-zero padding when pad is false
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		out  []byte
+		max  = uint32(1)<<to - 1
+	)
+	for _, v := range data {
+		if uint32(v)>>from != 0 {
+			return nil, fmt.Errorf("invalid data value %d", v)
+		}
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&max))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&max))
+		}
+	} else if bits >= from || acc<<(to-bits)&max != 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return out, nil
+}